@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/coinbase/odin/aws"
 )
@@ -27,11 +28,19 @@ func (alarm *AlarmInput) SetAlarmDescription() {
 	}
 
 	if alarm.Statistic != nil && alarm.MetricName != nil {
-		desc = append(desc, fmt.Sprintf(" if %v %v", *alarm.Statistic, *alarm.MetricName))
+		metric := *alarm.MetricName
+		if alarm.Namespace != nil && *alarm.Namespace != "AWS/EC2" {
+			metric = fmt.Sprintf("%v/%v", *alarm.Namespace, metric)
+		}
+		desc = append(desc, fmt.Sprintf(" if %v %v", *alarm.Statistic, metric))
 	}
 
 	if alarm.ComparisonOperator != nil && alarm.Threshold != nil {
-		desc = append(desc, fmt.Sprintf(" is %v %v%v", *alarm.ComparisonOperator, *alarm.Threshold, "%"))
+		unit := ""
+		if alarm.MetricName != nil && *alarm.MetricName == "CPUUtilization" {
+			unit = "%"
+		}
+		desc = append(desc, fmt.Sprintf(" is %v %v%v", *alarm.ComparisonOperator, *alarm.Threshold, unit))
 	}
 
 	if alarm.Period != nil && alarm.EvaluationPeriods != nil {
@@ -42,3 +51,33 @@ func (alarm *AlarmInput) SetAlarmDescription() {
 
 	alarm.AlarmDescription = &descstr
 }
+
+// AddStepAdjustmentsDescription appends the step policies adjustments to the description
+func (alarm *AlarmInput) AddStepAdjustmentsDescription(steps []*autoscaling.StepAdjustment) {
+	desc := []string{}
+
+	if alarm.AlarmDescription != nil {
+		desc = append(desc, *alarm.AlarmDescription)
+	}
+
+	for _, step := range steps {
+		if step == nil || step.ScalingAdjustment == nil {
+			continue
+		}
+
+		lower, upper := "-inf", "+inf"
+		if step.MetricIntervalLowerBound != nil {
+			lower = fmt.Sprintf("%+g", *step.MetricIntervalLowerBound)
+		}
+
+		if step.MetricIntervalUpperBound != nil {
+			upper = fmt.Sprintf("%+g", *step.MetricIntervalUpperBound)
+		}
+
+		desc = append(desc, fmt.Sprintf(" adjust by %+d from threshold%v to threshold%v", *step.ScalingAdjustment, lower, upper))
+	}
+
+	descstr := strings.Join(desc, "\n")
+
+	alarm.AlarmDescription = &descstr
+}
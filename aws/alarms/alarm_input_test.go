@@ -3,8 +3,11 @@ package alarms
 import (
 	"testing"
 
+	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/coinbase/step/utils/to"
 	fuzz "github.com/google/gofuzz"
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_SetAlarmDescription_Fuzz(t *testing.T) {
@@ -17,3 +20,26 @@ func Test_SetAlarmDescription_Fuzz(t *testing.T) {
 		ai.SetAlarmDescription()
 	}
 }
+
+func Test_AddStepAdjustmentsDescription(t *testing.T) {
+	ai := AlarmInput{&cloudwatch.PutMetricAlarmInput{
+		AlarmName:          to.Strp("name"),
+		Namespace:          to.Strp("AWS/SQS"),
+		MetricName:         to.Strp("ApproximateNumberOfMessagesVisible"),
+		Statistic:          to.Strp("Sum"),
+		ComparisonOperator: to.Strp("GreaterThanThreshold"),
+		Threshold:          to.Float64p(100),
+	}}
+
+	ai.SetAlarmDescription()
+	ai.AddStepAdjustmentsDescription([]*autoscaling.StepAdjustment{
+		&autoscaling.StepAdjustment{MetricIntervalLowerBound: to.Float64p(0), MetricIntervalUpperBound: to.Float64p(50), ScalingAdjustment: to.Int64p(1)},
+		&autoscaling.StepAdjustment{MetricIntervalLowerBound: to.Float64p(50), ScalingAdjustment: to.Int64p(3)},
+	})
+
+	assert.Equal(t, `Scale-name
+ if Sum AWS/SQS/ApproximateNumberOfMessagesVisible
+ is GreaterThanThreshold 100
+ adjust by +1 from threshold+0 to threshold+50
+ adjust by +3 from threshold+50 to threshold+inf`, *ai.AlarmDescription)
+}
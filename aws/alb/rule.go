@@ -0,0 +1,138 @@
+package alb
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
+)
+
+// Rule is a listener rule forwarding to weighted target groups
+type Rule struct {
+	RuleArn *string
+	Weights map[string]int64 // Target group ARN to weight
+}
+
+//////
+// Find
+//////
+
+// FindRule returns the listener rule with its forward weights
+func FindRule(albc aws.ALBAPI, ruleArn *string) (*Rule, error) {
+	awsRule, err := findRuleByArn(albc, ruleArn)
+	if err != nil {
+		return nil, err
+	}
+
+	action := forwardAction(awsRule.Actions)
+	if action == nil {
+		return nil, fmt.Errorf("Rule %v has no forward action", *ruleArn)
+	}
+
+	weights := map[string]int64{}
+	if action.ForwardConfig != nil && len(action.ForwardConfig.TargetGroups) > 0 {
+		for _, tg := range action.ForwardConfig.TargetGroups {
+			if tg == nil || tg.TargetGroupArn == nil {
+				continue
+			}
+			// A target group without a weight defaults to 1
+			weight := int64(1)
+			if tg.Weight != nil {
+				weight = *tg.Weight
+			}
+			weights[*tg.TargetGroupArn] = weight
+		}
+	} else if action.TargetGroupArn != nil {
+		weights[*action.TargetGroupArn] = 1
+	}
+
+	return &Rule{
+		RuleArn: awsRule.RuleArn,
+		Weights: weights,
+	}, nil
+}
+
+func findRuleByArn(albc aws.ALBAPI, ruleArn *string) (*elbv2.Rule, error) {
+	output, err := albc.DescribeRules(&elbv2.DescribeRulesInput{
+		RuleArns: []*string{ruleArn},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(output.Rules) != 1 || output.Rules[0].RuleArn == nil || *output.Rules[0].RuleArn != *ruleArn {
+		return nil, fmt.Errorf("Rule %v Not Found", *ruleArn)
+	}
+
+	return output.Rules[0], nil
+}
+
+func forwardAction(actions []*elbv2.Action) *elbv2.Action {
+	for _, action := range actions {
+		if action != nil && action.Type != nil && *action.Type == elbv2.ActionTypeEnumForward {
+			return action
+		}
+	}
+	return nil
+}
+
+//////
+// Update
+//////
+
+// SetWeights replaces the forward weights of the listener rule
+// the other actions of the rule are kept as they are
+func SetWeights(albc aws.ALBAPI, ruleArn *string, weights map[string]int64) error {
+	awsRule, err := findRuleByArn(albc, ruleArn)
+	if err != nil {
+		return err
+	}
+
+	arns := []string{}
+	for arn := range weights {
+		arns = append(arns, arn)
+	}
+	sort.Strings(arns)
+
+	tgs := []*elbv2.TargetGroupTuple{}
+	for _, arn := range arns {
+		tgs = append(tgs, &elbv2.TargetGroupTuple{
+			TargetGroupArn: to.Strp(arn),
+			Weight:         to.Int64p(weights[arn]),
+		})
+	}
+
+	forward := forwardAction(awsRule.Actions)
+	if forward == nil {
+		return fmt.Errorf("Rule %v has no forward action", *ruleArn)
+	}
+
+	actions := []*elbv2.Action{}
+	for _, action := range awsRule.Actions {
+		if action == forward {
+			action = &elbv2.Action{
+				Type:          action.Type,
+				Order:         action.Order,
+				ForwardConfig: &elbv2.ForwardActionConfig{TargetGroups: tgs, TargetGroupStickinessConfig: stickiness(action)},
+			}
+		}
+		actions = append(actions, action)
+	}
+
+	_, err = albc.ModifyRule(&elbv2.ModifyRuleInput{
+		RuleArn: ruleArn,
+		Actions: actions,
+	})
+
+	return err
+}
+
+func stickiness(action *elbv2.Action) *elbv2.TargetGroupStickinessConfig {
+	if action.ForwardConfig == nil {
+		return nil
+	}
+	return action.ForwardConfig.TargetGroupStickinessConfig
+}
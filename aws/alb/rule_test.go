@@ -0,0 +1,66 @@
+package alb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FindRule_NotFound(t *testing.T) {
+	albc := &mocks.ALBClient{}
+	_, err := FindRule(albc, to.Strp("rule"))
+	assert.Error(t, err)
+}
+
+func Test_FindRule_Weights(t *testing.T) {
+	albc := &mocks.ALBClient{}
+	albc.AddRule("rule", map[string]int64{"blue": 100, "green": 0})
+
+	rule, err := FindRule(albc, to.Strp("rule"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"blue": 100, "green": 0}, rule.Weights)
+}
+
+func Test_FindRule_SingleTargetGroup(t *testing.T) {
+	albc := &mocks.ALBClient{}
+	albc.AddRule("rule", map[string]int64{})
+	albc.Rules["rule"].Actions[0].ForwardConfig = nil
+	albc.Rules["rule"].Actions[0].TargetGroupArn = to.Strp("blue")
+
+	rule, err := FindRule(albc, to.Strp("rule"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"blue": 1}, rule.Weights)
+}
+
+func Test_FindRule_NoForward(t *testing.T) {
+	albc := &mocks.ALBClient{}
+	albc.AddRule("rule", map[string]int64{})
+	albc.Rules["rule"].Actions[0].Type = to.Strp(elbv2.ActionTypeEnumFixedResponse)
+
+	_, err := FindRule(albc, to.Strp("rule"))
+	assert.Error(t, err)
+}
+
+func Test_SetWeights(t *testing.T) {
+	albc := &mocks.ALBClient{}
+	albc.AddRule("rule", map[string]int64{"blue": 100, "green": 0})
+	albc.Rules["rule"].Actions = append([]*elbv2.Action{
+		&elbv2.Action{Type: to.Strp(elbv2.ActionTypeEnumAuthenticateOidc), Order: to.Int64p(1)},
+	}, albc.Rules["rule"].Actions...)
+
+	err := SetWeights(albc, to.Strp("rule"), map[string]int64{"blue": 75, "green": 25})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(albc.ModifyRuleInputs))
+
+	actions := albc.ModifyRuleInputs[0].Actions
+	assert.Equal(t, 2, len(actions))
+	assert.Equal(t, elbv2.ActionTypeEnumAuthenticateOidc, *actions[0].Type)
+	assert.Nil(t, actions[1].TargetGroupArn)
+
+	rule, err := FindRule(albc, to.Strp("rule"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"blue": 75, "green": 25}, rule.Weights)
+}
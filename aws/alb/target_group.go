@@ -3,9 +3,12 @@ package alb
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/aws/cw"
 	"github.com/coinbase/step/utils/to"
 )
 
@@ -17,6 +20,7 @@ type TargetGroup struct {
 	AllowedServiceTag *string
 	TargetGroupArn    *string
 	TargetGroupName   *string
+	LoadBalancerArns  []*string
 	SlowStartDuration int
 }
 
@@ -51,6 +55,33 @@ func (s *TargetGroup) AllowedService() *string {
 	return s.AllowedServiceTag
 }
 
+// ResourceLabel returns the label identifying the target group for the ALBRequestCountPerTarget metric
+// It is nil unless the target group is attached to exactly one load balancer
+func (s *TargetGroup) ResourceLabel() *string {
+	if len(s.LoadBalancerArns) != 1 || s.LoadBalancerArns[0] == nil || s.TargetGroupArn == nil {
+		return nil
+	}
+
+	// arn:aws:elasticloadbalancing:region:account:loadbalancer/app/name/id
+	lb := arnResource(*s.LoadBalancerArns[0])
+	if !strings.HasPrefix(lb, "loadbalancer/") {
+		return nil
+	}
+
+	// arn:aws:elasticloadbalancing:region:account:targetgroup/name/id
+	tg := arnResource(*s.TargetGroupArn)
+	if !strings.HasPrefix(tg, "targetgroup/") {
+		return nil
+	}
+
+	return to.Strp(fmt.Sprintf("%v/%v", strings.TrimPrefix(lb, "loadbalancer/"), tg))
+}
+
+func arnResource(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	return parts[len(parts)-1]
+}
+
 //////
 // Healthy
 //////
@@ -121,6 +152,7 @@ func find(alb aws.ALBAPI, targetGroupName *string) (*TargetGroup, error) {
 		AllowedServiceTag: aws.FetchELBV2Tag(awsTags, to.Strp("AllowedService")),
 		TargetGroupArn:    awsTarget.TargetGroupArn,
 		TargetGroupName:   targetGroupName,
+		LoadBalancerArns:  awsTarget.LoadBalancerArns,
 		SlowStartDuration: slowStartDuration,
 	}, nil
 }
@@ -185,3 +217,29 @@ func findSlowStartDuration(alb aws.ALBAPI, targetGroupARN *string) int {
 	}
 	return 0
 }
+
+// ErrorCounts returns the number of 5xx target responses and requests between start and end
+// resourceLabel is the target groups ResourceLabel
+func ErrorCounts(cwc aws.CWAPI, resourceLabel *string, start time.Time, end time.Time) (float64, float64, error) {
+	i := strings.Index(*resourceLabel, "/targetgroup/")
+	if i < 0 {
+		return 0, 0, fmt.Errorf("Invalid target group resource label %v", *resourceLabel)
+	}
+
+	dims := map[string]string{
+		"LoadBalancer": (*resourceLabel)[:i],
+		"TargetGroup":  (*resourceLabel)[i+1:],
+	}
+
+	errors, err := cw.Sum(cwc, "AWS/ApplicationELB", "HTTPCode_Target_5XX_Count", dims, start, end)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	requests, err := cw.Sum(cwc, "AWS/ApplicationELB", "RequestCount", dims, start, end)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return errors, requests, nil
+}
@@ -88,3 +88,16 @@ func Test_createDescribeTargetHealthInput(t *testing.T) {
 	assert.Equal(t, tgsIDs[0], "a")
 	assert.Equal(t, tgsIDs[1], "b")
 }
+
+func Test_ResourceLabel(t *testing.T) {
+	tg := TargetGroup{
+		TargetGroupArn: to.Strp("arn:aws:elasticloadbalancing:us-east-1:000000000000:targetgroup/web/73e2d6bc24d8a067"),
+	}
+	assert.Nil(t, tg.ResourceLabel()) // No load balancer
+
+	tg.LoadBalancerArns = []*string{to.Strp("arn:aws:elasticloadbalancing:us-east-1:000000000000:loadbalancer/app/web-alb/50dc6c495c0c9188")}
+	assert.Equal(t, "app/web-alb/50dc6c495c0c9188/targetgroup/web/73e2d6bc24d8a067", *tg.ResourceLabel())
+
+	tg.LoadBalancerArns = append(tg.LoadBalancerArns, to.Strp("arn:aws:elasticloadbalancing:us-east-1:000000000000:loadbalancer/app/other/1"))
+	assert.Nil(t, tg.ResourceLabel()) // Ambiguous
+}
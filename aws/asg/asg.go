@@ -2,11 +2,13 @@ package asg
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/coinbase/odin/aws"
 	"github.com/coinbase/odin/aws/lc"
+	"github.com/coinbase/odin/aws/lt"
 	"github.com/coinbase/step/utils/is"
 	"github.com/coinbase/step/utils/to"
 )
@@ -19,12 +21,21 @@ type ASG struct {
 	ReleaseIDTag   *string
 	ReleaseIdTag   *string
 
+	// Set when the ASG is retained for rollback
+	RetainUntilTag             *string
+	RetainedByTag              *string
+	RetainedMinSizeTag         *string
+	RetainedMaxSizeTag         *string
+	RetainedDesiredCapacityTag *string
+
 	MinSize         *int64
 	MaxSize         *int64
 	DesiredCapacity *int64
 
 	AutoScalingGroupName    *string
-	LaunchConfigurationName *string
+	LaunchConfigurationName *string // Only set on ASGs created before launch templates
+	LaunchTemplateName      *string
+	OverrideInstanceTypes   []*string // Set when launched with a mixed instances policy
 
 	LoadBalancerNames []*string
 	TargetGroupARNs   []*string
@@ -78,8 +89,16 @@ func newASG(group *autoscaling.Group) *ASG {
 		ReleaseIDTag:   aws.FetchASGTag(group.Tags, to.Strp("ReleaseID")),
 		ReleaseIdTag:   aws.FetchASGTag(group.Tags, to.Strp("ReleaseId")),
 
+		RetainUntilTag:             aws.FetchASGTag(group.Tags, to.Strp("RetainUntil")),
+		RetainedByTag:              aws.FetchASGTag(group.Tags, to.Strp("RetainedBy")),
+		RetainedMinSizeTag:         aws.FetchASGTag(group.Tags, to.Strp("RetainedMinSize")),
+		RetainedMaxSizeTag:         aws.FetchASGTag(group.Tags, to.Strp("RetainedMaxSize")),
+		RetainedDesiredCapacityTag: aws.FetchASGTag(group.Tags, to.Strp("RetainedDesiredCapacity")),
+
 		AutoScalingGroupName:    group.AutoScalingGroupName,
 		LaunchConfigurationName: group.LaunchConfigurationName,
+		LaunchTemplateName:      launchTemplateName(group),
+		OverrideInstanceTypes:   overrideInstanceTypes(group),
 
 		LoadBalancerNames: group.LoadBalancerNames,
 		TargetGroupARNs:   group.TargetGroupARNs,
@@ -92,6 +111,57 @@ func newASG(group *autoscaling.Group) *ASG {
 	}
 }
 
+func launchTemplateName(group *autoscaling.Group) *string {
+	if group.LaunchTemplate != nil {
+		return group.LaunchTemplate.LaunchTemplateName
+	}
+
+	mip := group.MixedInstancesPolicy
+	if mip != nil && mip.LaunchTemplate != nil && mip.LaunchTemplate.LaunchTemplateSpecification != nil {
+		return mip.LaunchTemplate.LaunchTemplateSpecification.LaunchTemplateName
+	}
+
+	return nil
+}
+
+func overrideInstanceTypes(group *autoscaling.Group) []*string {
+	mip := group.MixedInstancesPolicy
+	if mip == nil || mip.LaunchTemplate == nil {
+		return nil
+	}
+
+	types := []*string{}
+	for _, o := range mip.LaunchTemplate.Overrides {
+		if o != nil && o.InstanceType != nil {
+			types = append(types, o.InstanceType)
+		}
+	}
+
+	return types
+}
+
+// LaunchDetails returns the AMI and instance types the ASG launches
+func (s *ASG) LaunchDetails(asgc aws.ASGAPI, ec2c aws.EC2API) (*string, []*string, error) {
+	if s.LaunchTemplateName == nil && s.LaunchConfigurationName != nil {
+		config, err := lc.Find(asgc, s.LaunchConfigurationName)
+		if err != nil {
+			return nil, nil, err
+		}
+		return config.ImageId, []*string{config.InstanceType}, nil
+	}
+
+	data, err := lt.Find(ec2c, s.LaunchTemplateName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(s.OverrideInstanceTypes) > 0 {
+		return data.ImageId, s.OverrideInstanceTypes, nil
+	}
+
+	return data.ImageId, []*string{data.InstanceType}, nil
+}
+
 //////
 // Healthy
 //////
@@ -103,13 +173,57 @@ func GetInstances(asgc aws.ASGAPI, asgName *string) (aws.Instances, *ASG, error)
 		return nil, nil, err
 	}
 
+	return group.Instances(), group, nil
+}
+
+// Instances returns the instances on the ASG with their ASG health
+func (s *ASG) Instances() aws.Instances {
 	instances := aws.Instances{}
 
-	for _, i := range group.instances {
+	for _, i := range s.instances {
 		instances.AddASGInstance(i)
 	}
 
-	return instances, group, nil
+	return instances
+}
+
+// Weights returns the weighted capacity of each instance on the ASG
+func (s *ASG) Weights() aws.Weights {
+	weights := aws.Weights{}
+	for _, i := range s.instances {
+		weights.AddASGInstance(i)
+	}
+	return weights
+}
+
+// WeightedCapacity returns the capacity of the running instances given a weight per instance type
+// Instance types without a weight count as one
+func (s *ASG) WeightedCapacity(typeWeights map[string]int64) int64 {
+	capacity := int64(0)
+	for _, i := range s.instances {
+		if i == nil || i.LifecycleState == nil || strings.HasPrefix(*i.LifecycleState, "Term") {
+			continue
+		}
+
+		weight := int64(1)
+		if i.InstanceType != nil {
+			if w, ok := typeWeights[*i.InstanceType]; ok {
+				weight = w
+			}
+		}
+		capacity += weight
+	}
+	return capacity
+}
+
+// IsWeighted returns true if the instances on the ASG have weighted capacity
+func (s *ASG) IsWeighted() bool {
+	for _, i := range s.instances {
+		if i != nil && i.WeightedCapacity != nil {
+			return true
+		}
+	}
+	return false
 }
 
 func findByName(asgc aws.ASGAPI, asgName *string) (*ASG, error) {
@@ -142,7 +256,7 @@ func findByName(asgc aws.ASGAPI, asgName *string) (*ASG, error) {
 // Find
 //////////
 
-// ForProjectConfigNotReleaseIDServiceMap finds all previous ASGs and returns them as a service map
+// ForProjectConfigNotReleaseIDServiceMap finds all previous ASGs, except retained ones, and returns them as a service map
 // Will error if there is an ASG without a service name || two ASGs for a service
 func ForProjectConfigNotReleaseIDServiceMap(asgc aws.ASGAPI, projectName *string, configName *string, releaseID *string) (map[string]*ASG, error) {
 	asgs, err := ForProjectConfigNOTReleaseID(asgc, projectName, configName, releaseID)
@@ -152,6 +266,11 @@ func ForProjectConfigNotReleaseIDServiceMap(asgc aws.ASGAPI, projectName *string
 
 	prevASGs := map[string]*ASG{}
 	for _, asg := range asgs {
+		// Retained ASGs are detached rollback candidates, not the running release
+		if asg.IsRetained() {
+			continue
+		}
+
 		sn := asg.ServiceName()
 		if sn == nil {
 			return nil, fmt.Errorf("Autoscaling Group found for Project with No Service Name %v", to.Strs(asg.ServiceID()))
@@ -170,7 +289,7 @@ func ForProjectConfigNotReleaseIDServiceMap(asgc aws.ASGAPI, projectName *string
 
 // ForProjectConfigNOTReleaseID returns all ASGs not with the release ID
 func ForProjectConfigNOTReleaseID(asgc aws.ASGAPI, projectName *string, configName *string, releaseID *string) ([]*ASG, error) {
-	all, err := forProjectConfig(asgc, projectName, configName)
+	all, err := ForProjectConfig(asgc, projectName, configName)
 	if err != nil {
 		return nil, err
 	}
@@ -187,7 +306,7 @@ func ForProjectConfigNOTReleaseID(asgc aws.ASGAPI, projectName *string, configNa
 
 // ForProjectConfigReleaseID returns all ASGs with a release ID
 func ForProjectConfigReleaseID(asgc aws.ASGAPI, projectName *string, configName *string, releaseID *string) ([]*ASG, error) {
-	all, err := forProjectConfig(asgc, projectName, configName)
+	all, err := ForProjectConfig(asgc, projectName, configName)
 	if err != nil {
 		return nil, err
 	}
@@ -202,7 +321,8 @@ func ForProjectConfigReleaseID(asgc aws.ASGAPI, projectName *string, configName
 	return asgs, nil
 }
 
-func forProjectConfig(asgc aws.ASGAPI, projectName *string, configName *string) ([]*ASG, error) {
+// ForProjectConfig returns all ASGs for the project and config
+func ForProjectConfig(asgc aws.ASGAPI, projectName *string, configName *string) ([]*ASG, error) {
 	all, err := findInAws(asgc, &autoscaling.DescribeAutoScalingGroupsInput{})
 	if err != nil {
 		return nil, err
@@ -337,8 +457,8 @@ func (s *ASG) attachedClassicLBs(asgc aws.ASGAPI) ([]string, error) {
 	return lbs, nil
 }
 
-// Teardown deletes the ASG with launch config and alarms
-func (s *ASG) Teardown(asgc aws.ASGAPI, cwc aws.CWAPI) error {
+// Teardown deletes the ASG with launch template (or legacy launch config) and alarms
+func (s *ASG) Teardown(asgc aws.ASGAPI, ec2c aws.EC2API, cwc aws.CWAPI) error {
 	// Delete Alarms
 	alarms, err := s.alarmNames(asgc)
 	if err != nil {
@@ -354,32 +474,65 @@ func (s *ASG) Teardown(asgc aws.ASGAPI, cwc aws.CWAPI) error {
 		return err
 	}
 
-	// Delete Launch Config as well
-	if err := lc.Teardown(asgc, s.LaunchConfigurationName); err != nil {
-		return err
+	// Delete Launch Template as well
+	if s.LaunchTemplateName != nil {
+		if err := lt.Teardown(ec2c, s.LaunchTemplateName); err != nil {
+			return err
+		}
+	}
+
+	// ASGs created before launch templates still have a Launch Config
+	if s.LaunchConfigurationName != nil {
+		if err := lc.Teardown(asgc, s.LaunchConfigurationName); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// alarmNames returns the alarms of all policies, including those AWS creates for target tracking
 func (s *ASG) alarmNames(asgc aws.ASGAPI) ([]*string, error) {
-	output, err := asgc.DescribePolicies(&autoscaling.DescribePoliciesInput{AutoScalingGroupName: s.AutoScalingGroupName})
-	if err != nil {
-		return nil, err
-	}
+	input := &autoscaling.DescribePoliciesInput{AutoScalingGroupName: s.AutoScalingGroupName}
 	alarms := []*string{}
-	for _, sp := range output.ScalingPolicies {
-		for _, alarm := range sp.Alarms {
-			alarms = append(alarms, alarm.AlarmName)
+
+	for {
+		output, err := asgc.DescribePolicies(input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sp := range output.ScalingPolicies {
+			for _, alarm := range sp.Alarms {
+				alarms = append(alarms, alarm.AlarmName)
+			}
+		}
+
+		if output.NextToken == nil {
+			break
 		}
+		input.NextToken = output.NextToken
 	}
 
 	return alarms, nil
 }
 
 func (s *ASG) teardownAlarms(cwc aws.CWAPI, alarms []*string) error {
-	_, err := cwc.DeleteAlarms(&cloudwatch.DeleteAlarmsInput{AlarmNames: alarms})
-	return err
+	// DeleteAlarms takes at most 100 alarms
+	for len(alarms) > 0 {
+		batch := alarms
+		if len(batch) > 100 {
+			batch = alarms[:100]
+		}
+
+		if _, err := cwc.DeleteAlarms(&cloudwatch.DeleteAlarmsInput{AlarmNames: batch}); err != nil {
+			return err
+		}
+
+		alarms = alarms[len(batch):]
+	}
+
+	return nil
 }
 
 func (s *ASG) deleteGroup(asgc aws.ASGAPI) error {
@@ -3,6 +3,7 @@ package asg
 import (
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/aws/lt"
 	"github.com/coinbase/step/utils/to"
 )
 
@@ -48,8 +49,8 @@ func (s *Input) SetDefaults() {
 		s.HealthCheckGracePeriod = to.Int64p(300)
 	}
 
-	if s.LaunchConfigurationName == nil {
-		s.LaunchConfigurationName = s.AutoScalingGroupName // Makes the name the same
+	if s.LaunchTemplate == nil && s.MixedInstancesPolicy == nil {
+		s.LaunchTemplate = lt.Specification(s.AutoScalingGroupName) // Makes the name the same
 	}
 
 	s.HealthCheckType = to.Strp("EC2")
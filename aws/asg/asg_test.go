@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/coinbase/odin/aws/mocks"
 	"github.com/coinbase/step/utils/to"
 	"github.com/stretchr/testify/assert"
@@ -88,8 +89,9 @@ func Test_ForProjectConfigReleaseID(t *testing.T) {
 }
 
 func Test_Teardown(t *testing.T) {
-	// func (s *ASG) Teardown(asgc aws.ASGAPI, cwc aws.CWAPI) error {
+	// func (s *ASG) Teardown(asgc aws.ASGAPI, ec2c aws.EC2API, cwc aws.CWAPI) error {
 	asgc := &mocks.ASGClient{}
+	ec2c := &mocks.EC2Client{}
 	cwc := &mocks.CWClient{}
 
 	asgc.AddPreviousRuntimeResources("project", "config", "service1", "not_release")
@@ -97,10 +99,52 @@ func Test_Teardown(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(asgs))
 
-	err = asgs[0].Teardown(asgc, cwc)
+	assert.Equal(t, "project-config-service1-not_release", *asgs[0].LaunchTemplateName)
+	assert.Nil(t, asgs[0].LaunchConfigurationName)
+
+	err = asgs[0].Teardown(asgc, ec2c, cwc)
 	assert.NoError(t, err)
 }
 
+func Test_Teardown_LaunchConfiguration(t *testing.T) {
+	// ASGs created before launch templates still have to be cleaned up
+	asgc := &mocks.ASGClient{}
+	ec2c := &mocks.EC2Client{}
+	cwc := &mocks.CWClient{}
+
+	group := mocks.MakeMockASG("project-config-service1-old", "project", "config", "service1", "old")
+	group.LaunchTemplate = nil
+	group.LaunchConfigurationName = group.AutoScalingGroupName
+	asgc.AddASG(group)
+
+	asgs, err := ForProjectConfigNOTReleaseID(asgc, to.Strp("project"), to.Strp("config"), to.Strp("release"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(asgs))
+	assert.Nil(t, asgs[0].LaunchTemplateName)
+	assert.Equal(t, "project-config-service1-old", *asgs[0].LaunchConfigurationName)
+
+	err = asgs[0].Teardown(asgc, ec2c, cwc)
+	assert.NoError(t, err)
+}
+
+func Test_Teardown_Alarms_Batched(t *testing.T) {
+	cwc := &mocks.CWClient{}
+	s := &ASG{}
+
+	assert.NoError(t, s.teardownAlarms(cwc, []*string{}))
+	assert.Equal(t, 0, len(cwc.DeleteAlarmsInputs))
+
+	alarms := []*string{}
+	for i := 0; i < 150; i++ {
+		alarms = append(alarms, to.Strp("alarm"))
+	}
+
+	assert.NoError(t, s.teardownAlarms(cwc, alarms))
+	assert.Equal(t, 2, len(cwc.DeleteAlarmsInputs))
+	assert.Equal(t, 100, len(cwc.DeleteAlarmsInputs[0].AlarmNames))
+	assert.Equal(t, 50, len(cwc.DeleteAlarmsInputs[1].AlarmNames))
+}
+
 func Test_AttachedLBs(t *testing.T) {
 	asgc := &mocks.ASGClient{}
 
@@ -151,3 +195,44 @@ func Test_AttachedLBs(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(attached))
 }
+
+func Test_LaunchDetails(t *testing.T) {
+	asgc := &mocks.ASGClient{}
+	ec2c := &mocks.EC2Client{}
+
+	group := mocks.MakeMockASG("asg", "project", "config", "service", "release")
+	asgc.AddASG(group)
+
+	_, _, err := newASG(group).LaunchDetails(asgc, ec2c)
+	assert.Error(t, err) // No launch template
+
+	ec2c.CreateLaunchTemplate(&ec2.CreateLaunchTemplateInput{
+		LaunchTemplateName: to.Strp("asg"),
+		LaunchTemplateData: &ec2.RequestLaunchTemplateData{
+			ImageId:      to.Strp("ami-123456"),
+			InstanceType: to.Strp("t2.small"),
+		},
+	})
+
+	image, types, err := newASG(group).LaunchDetails(asgc, ec2c)
+	assert.NoError(t, err)
+	assert.Equal(t, "ami-123456", *image)
+	assert.Equal(t, []string{"t2.small"}, to.StrSlice(types))
+
+	// Mixed instances list the override types
+	group.LaunchTemplate = nil
+	group.MixedInstancesPolicy = &autoscaling.MixedInstancesPolicy{
+		LaunchTemplate: &autoscaling.LaunchTemplate{
+			LaunchTemplateSpecification: &autoscaling.LaunchTemplateSpecification{LaunchTemplateName: to.Strp("asg")},
+			Overrides: []*autoscaling.LaunchTemplateOverrides{
+				&autoscaling.LaunchTemplateOverrides{InstanceType: to.Strp("c5.large")},
+				&autoscaling.LaunchTemplateOverrides{InstanceType: to.Strp("m5.large")},
+			},
+		},
+	}
+
+	image, types, err = newASG(group).LaunchDetails(asgc, ec2c)
+	assert.NoError(t, err)
+	assert.Equal(t, "ami-123456", *image)
+	assert.Equal(t, []string{"c5.large", "m5.large"}, to.StrSlice(types))
+}
@@ -0,0 +1,286 @@
+package asg
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
+)
+
+// RETAINED_TAGS record a detached ASG kept for rollback and the capacity to restore it to
+var RETAINED_TAGS = []string{
+	"RetainUntil",
+	"RetainedBy",
+	"RetainedMinSize",
+	"RetainedMaxSize",
+	"RetainedDesiredCapacity",
+}
+
+// RETAIN_EXPIRY_ACTION is the scheduled action scaling a retained ASG to 0 once it expires,
+// so its instances stop even if no later deploy runs to delete it
+var RETAIN_EXPIRY_ACTION = "odin-retain-expiry"
+
+// IsRetained returns true if the ASG is kept as a rollback candidate
+func (s *ASG) IsRetained() bool {
+	return s.RetainUntilTag != nil
+}
+
+// RetainUntil returns when the ASG stops being a rollback candidate
+func (s *ASG) RetainUntil() *time.Time {
+	if s.RetainUntilTag == nil {
+		return nil
+	}
+
+	until, err := time.Parse(time.RFC3339, *s.RetainUntilTag)
+	if err != nil {
+		return nil
+	}
+
+	return &until
+}
+
+// RetainedBy returns the release ID that retained the ASG
+func (s *ASG) RetainedBy() *string {
+	return s.RetainedByTag
+}
+
+// IsRollbackCandidate returns true if the ASG is retained and can be restored at now
+func (s *ASG) IsRollbackCandidate(now time.Time) bool {
+	until := s.RetainUntil()
+	return until != nil && now.Before(*until)
+}
+
+//////////
+// Find
+//////////
+
+// RetainedForProjectConfigServiceMap returns the rollback candidates for each service
+// if a service has more than one, the one retained the longest is returned
+func RetainedForProjectConfigServiceMap(asgc aws.ASGAPI, projectName *string, configName *string, now time.Time) (map[string]*ASG, error) {
+	all, err := ForProjectConfig(asgc, projectName, configName)
+	if err != nil {
+		return nil, err
+	}
+
+	retained := map[string]*ASG{}
+	for _, asg := range all {
+		sn := asg.ServiceName()
+		if sn == nil || !asg.IsRollbackCandidate(now) {
+			continue
+		}
+
+		if prev, ok := retained[*sn]; ok && prev.RetainUntil().After(*asg.RetainUntil()) {
+			continue
+		}
+
+		retained[*sn] = asg
+	}
+
+	return retained, nil
+}
+
+//////////
+// Retain
+//////////
+
+// Retain scales the detached ASG down to capacity and tags it as a rollback candidate until until
+// Its scheduled actions are removed so it is not scaled back up, and any capacity is scaled to 0 at until
+func (s *ASG) Retain(asgc aws.ASGAPI, releaseID *string, capacity int64, until time.Time) error {
+	if err := s.deleteScheduledActions(asgc); err != nil {
+		return err
+	}
+
+	// Tag before scaling so the sizes recorded are the ones it served with, even if retried
+	if s.RetainedDesiredCapacityTag == nil {
+		tags := map[string]*string{
+			"RetainedMinSize":         to.Strp(fmt.Sprintf("%d", *s.MinSize)),
+			"RetainedMaxSize":         to.Strp(fmt.Sprintf("%d", *s.MaxSize)),
+			"RetainedDesiredCapacity": to.Strp(fmt.Sprintf("%d", *s.DesiredCapacity)),
+			"RetainedBy":              releaseID,
+		}
+
+		if err := s.createOrUpdateTags(asgc, tags, false); err != nil {
+			return err
+		}
+	}
+
+	if capacity > *s.DesiredCapacity {
+		capacity = *s.DesiredCapacity
+	}
+
+	_, err := asgc.UpdateAutoScalingGroup(&autoscaling.UpdateAutoScalingGroupInput{
+		AutoScalingGroupName: s.ServiceID(),
+		MinSize:              to.Int64p(capacity),
+		MaxSize:              to.Int64p(capacity),
+		DesiredCapacity:      to.Int64p(capacity),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if capacity > 0 {
+		if _, err := asgc.PutScheduledUpdateGroupAction(&autoscaling.PutScheduledUpdateGroupActionInput{
+			AutoScalingGroupName: s.ServiceID(),
+			ScheduledActionName:  to.Strp(RETAIN_EXPIRY_ACTION),
+			StartTime:            to.Timep(until.UTC()),
+			MinSize:              to.Int64p(0),
+			MaxSize:              to.Int64p(0),
+			DesiredCapacity:      to.Int64p(0),
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Last, since a retained ASG is torn down by other releases
+	return s.createOrUpdateTags(asgc, map[string]*string{
+		"RetainUntil": to.Strp(until.UTC().Format(time.RFC3339)),
+	}, false)
+}
+
+// Restore makes the retained ASG part of release releaseID,
+// scales it to the capacity it was retained with and attaches it to the ELBs and target groups
+func (s *ASG) Restore(asgc aws.ASGAPI, releaseID *string, releaseUUID *string, elbs []*string, targetGroups []*string) error {
+	minSize, maxSize, desiredCapacity, err := s.retainedCapacity()
+	if err != nil {
+		return err
+	}
+
+	// First, so a failed release tears it down
+	if err := s.createOrUpdateTags(asgc, map[string]*string{
+		"ReleaseID":   releaseID,
+		"ReleaseUUID": releaseUUID,
+	}, true); err != nil {
+		return err
+	}
+
+	// Remove the expiry action so the restored ASG is not scaled to 0
+	if err := s.deleteScheduledActions(asgc); err != nil {
+		return err
+	}
+
+	_, err = asgc.UpdateAutoScalingGroup(&autoscaling.UpdateAutoScalingGroupInput{
+		AutoScalingGroupName: s.ServiceID(),
+		MinSize:              to.Int64p(minSize),
+		MaxSize:              to.Int64p(maxSize),
+		DesiredCapacity:      to.Int64p(desiredCapacity),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.deleteRetainedTags(asgc); err != nil {
+		return err
+	}
+
+	if len(elbs) > 0 {
+		if _, err := asgc.AttachLoadBalancers(&autoscaling.AttachLoadBalancersInput{
+			AutoScalingGroupName: s.ServiceID(),
+			LoadBalancerNames:    elbs,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if len(targetGroups) > 0 {
+		if _, err := asgc.AttachLoadBalancerTargetGroups(&autoscaling.AttachLoadBalancerTargetGroupsInput{
+			AutoScalingGroupName: s.ServiceID(),
+			TargetGroupARNs:      targetGroups,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *ASG) retainedCapacity() (int64, int64, int64, error) {
+	sizes := []int64{}
+	for _, tag := range []*string{s.RetainedMinSizeTag, s.RetainedMaxSizeTag, s.RetainedDesiredCapacityTag} {
+		if tag == nil {
+			return 0, 0, 0, fmt.Errorf("Autoscaling group %v has no retained capacity", to.Strs(s.ServiceID()))
+		}
+
+		size, err := strconv.ParseInt(*tag, 10, 64)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("Autoscaling group %v retained capacity %v", to.Strs(s.ServiceID()), err.Error())
+		}
+		sizes = append(sizes, size)
+	}
+
+	return sizes[0], sizes[1], sizes[2], nil
+}
+
+func (s *ASG) createOrUpdateTags(asgc aws.ASGAPI, tags map[string]*string, propagate bool) error {
+	awsTags := []*autoscaling.Tag{}
+	for key, value := range tags {
+		awsTags = append(awsTags, &autoscaling.Tag{
+			Key:               to.Strp(key),
+			Value:             value,
+			PropagateAtLaunch: to.Boolp(propagate),
+			ResourceId:        s.ServiceID(),
+			ResourceType:      to.Strp("auto-scaling-group"),
+		})
+	}
+
+	_, err := asgc.CreateOrUpdateTags(&autoscaling.CreateOrUpdateTagsInput{Tags: awsTags})
+	return err
+}
+
+func (s *ASG) deleteRetainedTags(asgc aws.ASGAPI) error {
+	awsTags := []*autoscaling.Tag{}
+	for _, key := range RETAINED_TAGS {
+		awsTags = append(awsTags, &autoscaling.Tag{
+			Key:          to.Strp(key),
+			ResourceId:   s.ServiceID(),
+			ResourceType: to.Strp("auto-scaling-group"),
+		})
+	}
+
+	_, err := asgc.DeleteTags(&autoscaling.DeleteTagsInput{Tags: awsTags})
+	return err
+}
+
+func (s *ASG) deleteScheduledActions(asgc aws.ASGAPI) error {
+	input := &autoscaling.DescribeScheduledActionsInput{AutoScalingGroupName: s.ServiceID()}
+	names := []*string{}
+
+	for {
+		output, err := asgc.DescribeScheduledActions(input)
+		if err != nil {
+			return err
+		}
+
+		for _, action := range output.ScheduledUpdateGroupActions {
+			names = append(names, action.ScheduledActionName)
+		}
+
+		if output.NextToken == nil {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+
+	// BatchDeleteScheduledAction takes at most 50 actions
+	for len(names) > 0 {
+		batch := names
+		if len(batch) > 50 {
+			batch = names[:50]
+		}
+
+		if _, err := asgc.BatchDeleteScheduledAction(&autoscaling.BatchDeleteScheduledActionInput{
+			AutoScalingGroupName: s.ServiceID(),
+			ScheduledActionNames: batch,
+		}); err != nil {
+			return err
+		}
+
+		names = names[len(batch):]
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package asg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func retainedGroup(name string, releaseID string, until time.Time) *autoscaling.Group {
+	group := mocks.MakeMockASG(name, "project", "config", "service", releaseID)
+	group.Tags = append(group.Tags,
+		&autoscaling.TagDescription{Key: to.Strp("RetainUntil"), Value: to.Strp(until.Format(time.RFC3339))},
+		&autoscaling.TagDescription{Key: to.Strp("RetainedBy"), Value: to.Strp("other")},
+		&autoscaling.TagDescription{Key: to.Strp("RetainedMinSize"), Value: to.Strp("2")},
+		&autoscaling.TagDescription{Key: to.Strp("RetainedMaxSize"), Value: to.Strp("5")},
+		&autoscaling.TagDescription{Key: to.Strp("RetainedDesiredCapacity"), Value: to.Strp("3")},
+	)
+	return group
+}
+
+func Test_RetainedForProjectConfigServiceMap(t *testing.T) {
+	now := time.Now()
+	asgc := &mocks.ASGClient{}
+	asgc.AddPreviousRuntimeResources("project", "config", "service", "live")
+	asgc.AddASG(retainedGroup("expired", "expired", now.Add(-time.Minute)))
+	asgc.AddASG(retainedGroup("retained", "retained", now.Add(time.Hour)))
+
+	retained, err := RetainedForProjectConfigServiceMap(asgc, to.Strp("project"), to.Strp("config"), now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(retained))
+	assert.Equal(t, "retained", *retained["service"].ServiceID())
+
+	// Retained ASGs are not previous ASGs
+	prev, err := ForProjectConfigNotReleaseIDServiceMap(asgc, to.Strp("project"), to.Strp("config"), to.Strp("new"))
+	assert.NoError(t, err)
+	assert.Equal(t, "project-config-service-live", *prev["service"].ServiceID())
+}
+
+func Test_Retain(t *testing.T) {
+	asgc := &mocks.ASGClient{}
+	asgc.DescribeScheduledActionsOutput = &autoscaling.DescribeScheduledActionsOutput{
+		ScheduledUpdateGroupActions: []*autoscaling.ScheduledUpdateGroupAction{
+			&autoscaling.ScheduledUpdateGroupAction{ScheduledActionName: to.Strp("peak")},
+		},
+	}
+
+	group := newASG(mocks.MakeMockASG("name", "project", "config", "service", "release"))
+	until := time.Now().Add(time.Hour)
+	assert.NoError(t, group.Retain(asgc, to.Strp("new"), 0, until))
+
+	assert.Equal(t, 1, len(asgc.BatchDeleteScheduledActionInputs))
+	assert.Equal(t, 0, len(asgc.PutScheduledUpdateGroupActionInputs))
+	assert.EqualValues(t, 0, *asgc.UpdateAutoScalingGroupLastInput.MaxSize)
+	assert.EqualValues(t, 0, *asgc.UpdateAutoScalingGroupLastInput.DesiredCapacity)
+
+	tags := map[string]string{}
+	for _, input := range asgc.CreateOrUpdateTagsInputs {
+		for _, tag := range input.Tags {
+			tags[*tag.Key] = *tag.Value
+		}
+	}
+	assert.Equal(t, "1", tags["RetainedDesiredCapacity"])
+	assert.Equal(t, "3", tags["RetainedMaxSize"])
+	assert.Equal(t, "new", tags["RetainedBy"])
+	assert.Equal(t, until.UTC().Format(time.RFC3339), tags["RetainUntil"])
+
+	// RetainUntil is tagged last so a failed retain is never a rollback candidate
+	last := asgc.CreateOrUpdateTagsInputs[len(asgc.CreateOrUpdateTagsInputs)-1]
+	assert.Equal(t, "RetainUntil", *last.Tags[0].Key)
+}
+
+func Test_Retain_KeepsCapacity(t *testing.T) {
+	asgc := &mocks.ASGClient{}
+	group := newASG(mocks.MakeMockASG("name", "project", "config", "service", "release"))
+	group.DesiredCapacity = to.Int64p(4)
+
+	until := time.Now().Add(time.Hour)
+	assert.NoError(t, group.Retain(asgc, to.Strp("new"), 2, until))
+	assert.EqualValues(t, 2, *asgc.UpdateAutoScalingGroupLastInput.DesiredCapacity)
+
+	// The instances are stopped once it expires, even without another deploy
+	assert.Equal(t, 1, len(asgc.PutScheduledUpdateGroupActionInputs))
+	expiry := asgc.PutScheduledUpdateGroupActionInputs[0]
+	assert.Equal(t, RETAIN_EXPIRY_ACTION, *expiry.ScheduledActionName)
+	assert.Equal(t, until.UTC(), *expiry.StartTime)
+	assert.EqualValues(t, 0, *expiry.MaxSize)
+	assert.EqualValues(t, 0, *expiry.DesiredCapacity)
+}
+
+func Test_Restore(t *testing.T) {
+	asgc := &mocks.ASGClient{}
+	asgc.DescribeScheduledActionsOutput = &autoscaling.DescribeScheduledActionsOutput{
+		ScheduledUpdateGroupActions: []*autoscaling.ScheduledUpdateGroupAction{
+			&autoscaling.ScheduledUpdateGroupAction{ScheduledActionName: to.Strp(RETAIN_EXPIRY_ACTION)},
+		},
+	}
+	group := newASG(retainedGroup("name", "release", time.Now().Add(time.Hour)))
+
+	err := group.Restore(asgc, to.Strp("new"), to.Strp("uuid"), []*string{to.Strp("elb")}, []*string{to.Strp("tg")})
+	assert.NoError(t, err)
+
+	// The release is tagged first so a failed restore is torn down with the release
+	first := map[string]string{}
+	for _, tag := range asgc.CreateOrUpdateTagsInputs[0].Tags {
+		first[*tag.Key] = *tag.Value
+	}
+	assert.Equal(t, map[string]string{"ReleaseID": "new", "ReleaseUUID": "uuid"}, first)
+	assert.Equal(t, []string{RETAIN_EXPIRY_ACTION}, to.StrSlice(asgc.BatchDeleteScheduledActionInputs[0].ScheduledActionNames))
+	assert.EqualValues(t, 2, *asgc.UpdateAutoScalingGroupLastInput.MinSize)
+	assert.EqualValues(t, 5, *asgc.UpdateAutoScalingGroupLastInput.MaxSize)
+	assert.EqualValues(t, 3, *asgc.UpdateAutoScalingGroupLastInput.DesiredCapacity)
+	assert.Equal(t, len(RETAINED_TAGS), len(asgc.DeleteTagsInputs[0].Tags))
+	assert.Equal(t, []string{"elb"}, to.StrSlice(asgc.AttachLoadBalancersInputs[0].LoadBalancerNames))
+	assert.Equal(t, []string{"tg"}, to.StrSlice(asgc.AttachLoadBalancerTargetGroupsInputs[0].TargetGroupARNs))
+}
+
+func Test_Restore_NoCapacity(t *testing.T) {
+	asgc := &mocks.ASGClient{}
+	group := newASG(mocks.MakeMockASG("name", "project", "config", "service", "release"))
+
+	assert.Error(t, group.Restore(asgc, to.Strp("new"), to.Strp("uuid"), nil, nil))
+	assert.Equal(t, 0, len(asgc.CreateOrUpdateTagsInputs))
+}
@@ -16,12 +16,18 @@ import (
 	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/aws/aws-sdk-go/service/servicequotas/servicequotasiface"
 	"github.com/aws/aws-sdk-go/service/sfn"
 	"github.com/aws/aws-sdk-go/service/sfn/sfniface"
 	"github.com/aws/aws-sdk-go/service/sns"
 	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	ar "github.com/coinbase/step/aws"
 )
 
@@ -178,12 +184,21 @@ type IAMAPI iamiface.IAMAPI
 // SNSAPI aws API
 type SNSAPI snsiface.SNSAPI
 
+// SQSAPI aws API
+type SQSAPI sqsiface.SQSAPI
+
 // SFNAPI aws API
 type SFNAPI sfniface.SFNAPI
 
 // DynamoDBAPI aws API
 type DynamoDBAPI dynamodbiface.DynamoDBAPI
 
+// ServiceQuotasAPI aws API
+type ServiceQuotasAPI servicequotasiface.ServiceQuotasAPI
+
+// KMSAPI aws API
+type KMSAPI kmsiface.KMSAPI
+
 // Clients for AWS
 type Clients interface {
 	S3Client(region *string, accountID *string, role *string) S3API
@@ -194,8 +209,11 @@ type Clients interface {
 	CWClient(region *string, accountID *string, role *string) CWAPI
 	IAMClient(region *string, accountID *string, role *string) IAMAPI
 	SNSClient(region *string, accountID *string, role *string) SNSAPI
+	SQSClient(region *string, accountID *string, role *string) SQSAPI
 	SFNClient(region *string, accountID *string, role *string) SFNAPI
 	DynamoDBClient(region *string, accountID *string, role *string) DynamoDBAPI
+	ServiceQuotasClient(region *string, accountID *string, role *string) ServiceQuotasAPI
+	KMSClient(region *string, accountID *string, role *string) KMSAPI
 }
 
 // ClientsStr implementation
@@ -243,6 +261,11 @@ func (awsc *ClientsStr) SNSClient(region *string, accountID *string, role *strin
 	return sns.New(awsc.Session(), awsc.Config(region, accountID, role))
 }
 
+// SQSClient returns client for region account and role
+func (awsc *ClientsStr) SQSClient(region *string, accountID *string, role *string) SQSAPI {
+	return sqs.New(awsc.Session(), awsc.Config(region, accountID, role))
+}
+
 // SFNClient returns client for region account and role
 func (awsc *ClientsStr) SFNClient(region *string, accountID *string, role *string) SFNAPI {
 	return sfn.New(awsc.Session(), awsc.Config(region, accountID, role))
@@ -252,3 +275,13 @@ func (awsc *ClientsStr) SFNClient(region *string, accountID *string, role *strin
 func (awsc *ClientsStr) DynamoDBClient(region *string, account_id *string, role *string) DynamoDBAPI {
 	return dynamodb.New(awsc.Session(), awsc.Config(region, account_id, role))
 }
+
+// ServiceQuotasClient returns client for region account and role
+func (awsc *ClientsStr) ServiceQuotasClient(region *string, accountID *string, role *string) ServiceQuotasAPI {
+	return servicequotas.New(awsc.Session(), awsc.Config(region, accountID, role))
+}
+
+// KMSClient returns client for region account and role
+func (awsc *ClientsStr) KMSClient(region *string, accountID *string, role *string) KMSAPI {
+	return kms.New(awsc.Session(), awsc.Config(region, accountID, role))
+}
@@ -0,0 +1,65 @@
+package cw
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
+)
+
+// Sum returns the sum of a metric between start and end
+func Sum(cwc aws.CWAPI, namespace string, metricName string, dimensions map[string]string, start time.Time, end time.Time) (float64, error) {
+	sum := float64(0)
+	err := statistics(cwc, namespace, metricName, dimensions, start, end, "Sum", func(dp *cloudwatch.Datapoint) {
+		if dp.Sum != nil {
+			sum += *dp.Sum
+		}
+	})
+
+	return sum, err
+}
+
+// Max returns the maximum of a metric between start and end
+func Max(cwc aws.CWAPI, namespace string, metricName string, dimensions map[string]string, start time.Time, end time.Time) (float64, error) {
+	max := float64(0)
+	err := statistics(cwc, namespace, metricName, dimensions, start, end, "Maximum", func(dp *cloudwatch.Datapoint) {
+		if dp.Maximum != nil && *dp.Maximum > max {
+			max = *dp.Maximum
+		}
+	})
+
+	return max, err
+}
+
+func statistics(cwc aws.CWAPI, namespace string, metricName string, dimensions map[string]string, start time.Time, end time.Time, statistic string, fn func(*cloudwatch.Datapoint)) error {
+	dims := []*cloudwatch.Dimension{}
+	for name, value := range dimensions {
+		dims = append(dims, &cloudwatch.Dimension{Name: to.Strp(name), Value: to.Strp(value)})
+	}
+
+	period := int64(end.Sub(start).Seconds())
+	if period < 60 {
+		period = 60
+	}
+
+	out, err := cwc.GetMetricStatistics(&cloudwatch.GetMetricStatisticsInput{
+		Namespace:  to.Strp(namespace),
+		MetricName: to.Strp(metricName),
+		Dimensions: dims,
+		StartTime:  to.Timep(start),
+		EndTime:    to.Timep(end),
+		Period:     to.Int64p(period - period%60),
+		Statistics: []*string{to.Strp(statistic)},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, dp := range out.Datapoints {
+		fn(dp)
+	}
+
+	return nil
+}
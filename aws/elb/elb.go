@@ -2,10 +2,12 @@ package elb
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	aws_elb "github.com/aws/aws-sdk-go/service/elb"
 	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/aws/cw"
 	"github.com/coinbase/step/utils/to"
 )
 
@@ -172,3 +174,20 @@ func findAwsTagsByName(elbc aws.ELBAPI, name *string) ([]*aws_elb.Tag, error) {
 	return tagsOutput.TagDescriptions[0].Tags, nil
 
 }
+
+// ErrorCounts returns the number of 5xx backend responses and requests between start and end
+func ErrorCounts(cwc aws.CWAPI, name *string, start time.Time, end time.Time) (float64, float64, error) {
+	dims := map[string]string{"LoadBalancerName": *name}
+
+	errors, err := cw.Sum(cwc, "AWS/ELB", "HTTPCode_Backend_5XX", dims, start, end)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	requests, err := cw.Sum(cwc, "AWS/ELB", "RequestCount", dims, start, end)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return errors, requests, nil
+}
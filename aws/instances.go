@@ -1,6 +1,8 @@
 package aws
 
 import (
+	"strconv"
+
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/elb"
 	"github.com/aws/aws-sdk-go/service/elbv2"
@@ -114,6 +116,36 @@ func (all Instances) TerminatingIDs() []string {
 	return ids
 }
 
+// Weights Map of instance id to the capacity units it provides
+type Weights map[string]int64
+
+// AddASGInstance add the weighted capacity of an ASG instance
+func (weights Weights) AddASGInstance(i *autoscaling.Instance) {
+	if i == nil || i.InstanceId == nil || i.WeightedCapacity == nil {
+		return
+	}
+
+	weight, err := strconv.ParseInt(*i.WeightedCapacity, 10, 64)
+	if err != nil || weight < 1 {
+		return
+	}
+
+	weights[*i.InstanceId] = weight
+}
+
+// Capacity sums the capacity of the instance ids, an instance without a weight counts as one
+func (weights Weights) Capacity(ids []string) int64 {
+	capacity := int64(0)
+	for _, id := range ids {
+		weight, ok := weights[id]
+		if !ok {
+			weight = 1
+		}
+		capacity += weight
+	}
+	return capacity
+}
+
 // MergeInstances merge new set of instances returns new set
 func (all Instances) MergeInstances(update Instances) Instances {
 	ret := Instances{}
@@ -3,6 +3,8 @@ package aws
 import (
 	"testing"
 
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/coinbase/step/utils/to"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -27,3 +29,16 @@ func Test_MergeInstances(t *testing.T) {
 	i2 = Instances{"i": terminating}
 	assert.Equal(t, terminating, i2.MergeInstances(i1)["i"])
 }
+
+func Test_Weights_Capacity(t *testing.T) {
+	var nilWeights Weights
+	assert.Equal(t, int64(2), nilWeights.Capacity([]string{"a", "b"}))
+
+	weights := Weights{}
+	weights.AddASGInstance(&autoscaling.Instance{InstanceId: to.Strp("a"), WeightedCapacity: to.Strp("4")})
+	weights.AddASGInstance(&autoscaling.Instance{InstanceId: to.Strp("b")})
+	weights.AddASGInstance(&autoscaling.Instance{InstanceId: to.Strp("c"), WeightedCapacity: to.Strp("bad")})
+
+	assert.Equal(t, int64(6), weights.Capacity([]string{"a", "b", "c"}))
+	assert.Equal(t, int64(0), weights.Capacity([]string{}))
+}
@@ -1,9 +1,12 @@
 package lc
 
 import (
+	"fmt"
+
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 
 	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
 )
 
 // Teardown deleted launch configuration
@@ -18,3 +21,20 @@ func Teardown(asgc aws.ASGAPI, name *string) error {
 
 	return nil
 }
+
+// Find returns the launch configuration
+func Find(asgc aws.ASGAPI, name *string) (*autoscaling.LaunchConfiguration, error) {
+	out, err := asgc.DescribeLaunchConfigurations(&autoscaling.DescribeLaunchConfigurationsInput{
+		LaunchConfigurationNames: []*string{name},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(out.LaunchConfigurations) != 1 {
+		return nil, fmt.Errorf("Launch configuration %v not found", to.Strs(name))
+	}
+
+	return out.LaunchConfigurations[0], nil
+}
@@ -0,0 +1,41 @@
+package lt
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
+)
+
+// Teardown deletes the launch template and all its versions
+func Teardown(ec2c aws.EC2API, name *string) error {
+	_, err := ec2c.DeleteLaunchTemplate(&ec2.DeleteLaunchTemplateInput{
+		LaunchTemplateName: name,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Find returns the launch data of the latest version of the launch template
+func Find(ec2c aws.EC2API, name *string) (*ec2.ResponseLaunchTemplateData, error) {
+	out, err := ec2c.DescribeLaunchTemplateVersions(&ec2.DescribeLaunchTemplateVersionsInput{
+		LaunchTemplateName: name,
+		Versions:           []*string{to.Strp("$Latest")},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(out.LaunchTemplateVersions) != 1 || out.LaunchTemplateVersions[0].LaunchTemplateData == nil {
+		return nil, fmt.Errorf("Launch template %v not found", to.Strs(name))
+	}
+
+	return out.LaunchTemplateVersions[0].LaunchTemplateData, nil
+}
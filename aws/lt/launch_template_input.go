@@ -0,0 +1,196 @@
+package lt
+
+import (
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
+)
+
+// DefaultVersion is the version of the template an ASG launches with
+// Odin creates a new template for every release, so this is always the version it created
+const DefaultVersion = "$Default"
+
+var ebsOptimizedInstances = map[string]bool{
+	"c4.large":    true,
+	"c4.xlarge":   true,
+	"c4.2xlarge":  true,
+	"c4.4xlarge":  true,
+	"c4.8xlarge":  true,
+	"c5.large":    true,
+	"c5.xlarge":   true,
+	"c5.2xlarge":  true,
+	"c5.4xlarge":  true,
+	"c5.9xlarge":  true,
+	"c5.18xlarge": true,
+	"i3.large":    true,
+	"i3.xlarge":   true,
+	"i3.2xlarge":  true,
+	"i3.4xlarge":  true,
+	"i3.8xlarge":  true,
+	"i3.16xlarge": true,
+	"m4.large":    true,
+	"m4.xlarge":   true,
+	"m4.2xlarge":  true,
+	"m4.4xlarge":  true,
+	"m4.10xlarge": true,
+	"m4.16xlarge": true,
+	"m5.large":    true,
+	"m5.xlarge":   true,
+	"m5.2xlarge":  true,
+	"m5.4xlarge":  true,
+	"m5.12xlarge": true,
+	"m5.24xlarge": true,
+	"r4.large":    true,
+	"r4.xlarge":   true,
+	"r4.2xlarge":  true,
+	"r4.4xlarge":  true,
+	"r4.8xlarge":  true,
+	"r4.16xlarge": true,
+}
+
+// LaunchTemplateInput input struct
+type LaunchTemplateInput struct {
+	*ec2.CreateLaunchTemplateInput
+}
+
+// Create tries to create the launch template
+func (s *LaunchTemplateInput) Create(ec2c aws.EC2API) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	_, err := ec2c.CreateLaunchTemplate(s.CreateLaunchTemplateInput)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Data returns the launch template data, creating it if necessary
+func (s *LaunchTemplateInput) Data() *ec2.RequestLaunchTemplateData {
+	if s.LaunchTemplateData == nil {
+		s.LaunchTemplateData = &ec2.RequestLaunchTemplateData{}
+	}
+
+	return s.LaunchTemplateData
+}
+
+// AddBlockDevice adds an EBS block device to the LT
+func (s *LaunchTemplateInput) AddBlockDevice(ebsVolumeSize *int64, ebsVolumeType *string, ebsDeviceType *string) {
+	if ebsVolumeSize == nil {
+		return
+	}
+
+	if ebsVolumeType == nil {
+		ebsVolumeType = to.Strp("gp2")
+	}
+
+	if ebsDeviceType == nil {
+		ebsDeviceType = to.Strp("/dev/xvda")
+	}
+
+	block := &ec2.LaunchTemplateBlockDeviceMappingRequest{
+		DeviceName: ebsDeviceType,
+		Ebs: &ec2.LaunchTemplateEbsBlockDeviceRequest{
+			VolumeSize:          ebsVolumeSize,
+			VolumeType:          ebsVolumeType,
+			DeleteOnTermination: to.Boolp(true),
+		},
+	}
+
+	data := s.Data()
+	if data.BlockDeviceMappings == nil {
+		data.BlockDeviceMappings = []*ec2.LaunchTemplateBlockDeviceMappingRequest{}
+	}
+
+	data.BlockDeviceMappings = append(data.BlockDeviceMappings, block)
+}
+
+// SetNetwork assigns the security groups and optionally a public IP address
+// A public IP can only be requested on a network interface, so then the groups go there
+func (s *LaunchTemplateInput) SetNetwork(securityGroupIDs []*string, associatePublicIPAddress *bool) {
+	data := s.Data()
+
+	if associatePublicIPAddress == nil {
+		data.SecurityGroupIds = securityGroupIDs
+		data.NetworkInterfaces = nil
+		return
+	}
+
+	data.SecurityGroupIds = nil
+	data.NetworkInterfaces = []*ec2.LaunchTemplateInstanceNetworkInterfaceSpecificationRequest{
+		&ec2.LaunchTemplateInstanceNetworkInterfaceSpecificationRequest{
+			DeviceIndex:              to.Int64p(0),
+			AssociatePublicIpAddress: associatePublicIPAddress,
+			DeleteOnTermination:      to.Boolp(true),
+			Groups:                   securityGroupIDs,
+		},
+	}
+}
+
+// SetIamInstanceProfile assigns the instance profile with its ARN
+func (s *LaunchTemplateInput) SetIamInstanceProfile(profileARN *string) {
+	if profileARN == nil {
+		s.Data().IamInstanceProfile = nil
+		return
+	}
+
+	s.Data().IamInstanceProfile = &ec2.LaunchTemplateIamInstanceProfileSpecificationRequest{Arn: profileARN}
+}
+
+// SetSpotPrice requests spot instances at a max price
+func (s *LaunchTemplateInput) SetSpotPrice(spotPrice *string) {
+	if spotPrice == nil {
+		s.Data().InstanceMarketOptions = nil
+		return
+	}
+
+	s.Data().InstanceMarketOptions = &ec2.LaunchTemplateInstanceMarketOptionsRequest{
+		MarketType:  to.Strp("spot"),
+		SpotOptions: &ec2.LaunchTemplateSpotMarketOptionsRequest{MaxPrice: spotPrice},
+	}
+}
+
+// SetPlacementTenancy assigns the tenancy of the instances
+func (s *LaunchTemplateInput) SetPlacementTenancy(tenancy *string) {
+	if tenancy == nil {
+		s.Data().Placement = nil
+		return
+	}
+
+	s.Data().Placement = &ec2.LaunchTemplatePlacementRequest{Tenancy: tenancy}
+}
+
+// SetDefaults assigns values
+func (s *LaunchTemplateInput) SetDefaults() {
+	data := s.Data()
+
+	if data.InstanceType == nil {
+		data.InstanceType = to.Strp("t2.nano")
+	}
+
+	if data.Monitoring == nil {
+		data.Monitoring = &ec2.LaunchTemplatesMonitoringRequest{Enabled: to.Boolp(false)}
+	}
+
+	if data.EbsOptimized == nil {
+		opt := ebsOptimizedInstances[*data.InstanceType]
+		data.EbsOptimized = to.Boolp(opt)
+	}
+}
+
+// Specification returns the reference an ASG uses to launch with this template
+func (s *LaunchTemplateInput) Specification() *autoscaling.LaunchTemplateSpecification {
+	return Specification(s.LaunchTemplateName)
+}
+
+// Specification returns the reference an ASG uses to launch with the named template
+func Specification(name *string) *autoscaling.LaunchTemplateSpecification {
+	return &autoscaling.LaunchTemplateSpecification{
+		LaunchTemplateName: name,
+		Version:            to.Strp(DefaultVersion),
+	}
+}
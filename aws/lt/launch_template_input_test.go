@@ -0,0 +1,34 @@
+package lt
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AddBlockDevice(t *testing.T) {
+	input := &LaunchTemplateInput{&ec2.CreateLaunchTemplateInput{}}
+
+	input.AddBlockDevice(to.Int64p(10), nil, nil)
+	input.AddBlockDevice(to.Int64p(10), to.Strp("asd"), nil)
+	input.AddBlockDevice(to.Int64p(10), nil, to.Strp("asd"))
+
+	assert.Equal(t, 3, len(input.LaunchTemplateData.BlockDeviceMappings))
+}
+
+func Test_SetNetwork(t *testing.T) {
+	input := &LaunchTemplateInput{&ec2.CreateLaunchTemplateInput{}}
+	sgs := []*string{to.Strp("sg-1")}
+
+	input.SetNetwork(sgs, nil)
+	assert.Equal(t, sgs, input.LaunchTemplateData.SecurityGroupIds)
+	assert.Nil(t, input.LaunchTemplateData.NetworkInterfaces)
+
+	// Public IPs must be requested on the network interface with the groups
+	input.SetNetwork(sgs, to.Boolp(true))
+	assert.Nil(t, input.LaunchTemplateData.SecurityGroupIds)
+	assert.Equal(t, sgs, input.LaunchTemplateData.NetworkInterfaces[0].Groups)
+	assert.True(t, *input.LaunchTemplateData.NetworkInterfaces[0].AssociatePublicIpAddress)
+}
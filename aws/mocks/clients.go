@@ -7,7 +7,7 @@ import (
 
 // MockClients struct
 type MockClients struct {
-	S3       *mocks.MockS3Client
+	S3       *S3Client
 	ASG      *ASGClient
 	ELB      *ELBClient
 	EC2      *EC2Client
@@ -15,14 +15,17 @@ type MockClients struct {
 	CW       *CWClient
 	IAM      *IAMClient
 	SNS      *SNSClient
+	SQS      *SQSClient
 	SFN      *mocks.MockSFNClient
 	DynamoDB *mocks.MockDynamoDBClient
+	SQ       *ServiceQuotasClient
+	KMS      *KMSClient
 }
 
 // MockAWS mock clients
 func MockAWS() *MockClients {
 	return &MockClients{
-		S3:       &mocks.MockS3Client{},
+		S3:       &S3Client{},
 		ASG:      &ASGClient{},
 		ELB:      &ELBClient{},
 		EC2:      &EC2Client{},
@@ -30,8 +33,11 @@ func MockAWS() *MockClients {
 		CW:       &CWClient{},
 		IAM:      &IAMClient{},
 		SNS:      &SNSClient{},
+		SQS:      &SQSClient{},
 		SFN:      &mocks.MockSFNClient{},
 		DynamoDB: &mocks.MockDynamoDBClient{},
+		SQ:       &ServiceQuotasClient{},
+		KMS:      &KMSClient{},
 	}
 }
 
@@ -75,6 +81,11 @@ func (a *MockClients) SNSClient(*string, *string, *string) aws.SNSAPI {
 	return a.SNS
 }
 
+// SQSClient returns
+func (a *MockClients) SQSClient(*string, *string, *string) aws.SQSAPI {
+	return a.SQS
+}
+
 // SFNClient returns
 func (a *MockClients) SFNClient(*string, *string, *string) aws.SFNAPI {
 	return a.SFN
@@ -84,3 +95,13 @@ func (a *MockClients) SFNClient(*string, *string, *string) aws.SFNAPI {
 func (a *MockClients) DynamoDBClient(*string, *string, *string) aws.DynamoDBAPI {
 	return a.DynamoDB
 }
+
+// ServiceQuotasClient returns
+func (a *MockClients) ServiceQuotasClient(*string, *string, *string) aws.ServiceQuotasAPI {
+	return a.SQ
+}
+
+// KMSClient returns
+func (a *MockClients) KMSClient(*string, *string, *string) aws.KMSAPI {
+	return a.KMS
+}
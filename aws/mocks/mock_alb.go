@@ -16,6 +16,9 @@ type ALBClient struct {
 	DescribeTagsResp                  map[string]*DescribeV2TagsResponse
 	DescribeTargetHealthResp          map[string]*DescribeTargetHealthResponse
 	DescribeTargetGroupAttributesResp map[string]*DescribeTargetGroupAttributesResponse
+
+	Rules            map[string]*elbv2.Rule
+	ModifyRuleInputs []*elbv2.ModifyRuleInput
 }
 
 // DescribeTargetGroupsResponse return
@@ -94,6 +97,30 @@ func (m *ALBClient) init() {
 	if m.DescribeTargetGroupAttributesResp == nil {
 		m.DescribeTargetGroupAttributesResp = map[string]*DescribeTargetGroupAttributesResponse{}
 	}
+
+	if m.Rules == nil {
+		m.Rules = map[string]*elbv2.Rule{}
+	}
+}
+
+// AddRule adds a listener rule forwarding to the weighted target groups
+func (m *ALBClient) AddRule(arn string, weights map[string]int64) {
+	m.init()
+
+	tgs := []*elbv2.TargetGroupTuple{}
+	for tg, weight := range weights {
+		tgs = append(tgs, &elbv2.TargetGroupTuple{TargetGroupArn: to.Strp(tg), Weight: to.Int64p(weight)})
+	}
+
+	m.Rules[arn] = &elbv2.Rule{
+		RuleArn: to.Strp(arn),
+		Actions: []*elbv2.Action{
+			&elbv2.Action{
+				Type:          to.Strp(elbv2.ActionTypeEnumForward),
+				ForwardConfig: &elbv2.ForwardActionConfig{TargetGroups: tgs},
+			},
+		},
+	}
 }
 
 // AddTargetGroup return
@@ -197,3 +224,30 @@ func (m *ALBClient) DescribeTargetGroupAttributes(in *elbv2.DescribeTargetGroupA
 	}
 	return resp.Resp, resp.Error
 }
+
+// DescribeRules return
+func (m *ALBClient) DescribeRules(in *elbv2.DescribeRulesInput) (*elbv2.DescribeRulesOutput, error) {
+	m.init()
+	rules := []*elbv2.Rule{}
+	for _, arn := range in.RuleArns {
+		rule := m.Rules[*arn]
+		if rule == nil {
+			return nil, awserr.New(elbv2.ErrCodeRuleNotFoundException, "RuleNotFound", nil)
+		}
+		rules = append(rules, rule)
+	}
+	return &elbv2.DescribeRulesOutput{Rules: rules}, nil
+}
+
+// ModifyRule return
+func (m *ALBClient) ModifyRule(in *elbv2.ModifyRuleInput) (*elbv2.ModifyRuleOutput, error) {
+	m.init()
+	m.ModifyRuleInputs = append(m.ModifyRuleInputs, in)
+
+	rule := m.Rules[*in.RuleArn]
+	if rule == nil {
+		return nil, awserr.New(elbv2.ErrCodeRuleNotFoundException, "RuleNotFound", nil)
+	}
+	rule.Actions = in.Actions
+	return &elbv2.ModifyRuleOutput{Rules: []*elbv2.Rule{rule}}, nil
+}
@@ -35,9 +35,19 @@ type ASGClient struct {
 
 	DescribeLoadBalancerTargetGroupsOutput *autoscaling.DescribeLoadBalancerTargetGroupsOutput
 	DescribeLoadBalancersOutput            *autoscaling.DescribeLoadBalancersOutput
-
-	UpdateAutoScalingGroupLastInput *autoscaling.UpdateAutoScalingGroupInput
-	DetachLoadBalancersError        error
+	DescribeAccountLimitsOutput            *autoscaling.DescribeAccountLimitsOutput
+
+	UpdateAutoScalingGroupLastInput     *autoscaling.UpdateAutoScalingGroupInput
+	PutScheduledUpdateGroupActionInputs []*autoscaling.PutScheduledUpdateGroupActionInput
+	DetachLoadBalancersError            error
+
+	DescribeScheduledActionsOutput       *autoscaling.DescribeScheduledActionsOutput
+	BatchDeleteScheduledActionInputs     []*autoscaling.BatchDeleteScheduledActionInput
+	CreateOrUpdateTagsInputs             []*autoscaling.CreateOrUpdateTagsInput
+	DeleteTagsInputs                     []*autoscaling.DeleteTagsInput
+	AttachLoadBalancersInputs            []*autoscaling.AttachLoadBalancersInput
+	AttachLoadBalancerTargetGroupsInputs []*autoscaling.AttachLoadBalancerTargetGroupsInput
+	DeleteAutoScalingGroupInputs         []*autoscaling.DeleteAutoScalingGroupInput
 }
 
 func (m *ASGClient) init() {
@@ -58,9 +68,13 @@ func (m *ASGClient) init() {
 func MakeMockASG(name string, projetName string, configName string, serviceName string, releaseID string) *autoscaling.Group {
 	return &autoscaling.Group{
 		AutoScalingGroupName: to.Strp(name),
-		Instances:            MakeMockASGInstances(1, 0, 0),
-		LoadBalancerNames:    []*string{to.Strp("elb")},
-		TargetGroupARNs:      []*string{to.Strp("tg")},
+		LaunchTemplate: &autoscaling.LaunchTemplateSpecification{
+			LaunchTemplateName: to.Strp(name),
+			Version:            to.Strp("$Default"),
+		},
+		Instances:         MakeMockASGInstances(1, 0, 0),
+		LoadBalancerNames: []*string{to.Strp("elb")},
+		TargetGroupARNs:   []*string{to.Strp("tg")},
 
 		MinSize:         to.Int64p(1),
 		MaxSize:         to.Int64p(3),
@@ -182,6 +196,7 @@ func (m *ASGClient) DescribeAutoScalingGroupsPages(input *autoscaling.DescribeAu
 
 // DeleteAutoScalingGroup returns
 func (m *ASGClient) DeleteAutoScalingGroup(input *autoscaling.DeleteAutoScalingGroupInput) (*autoscaling.DeleteAutoScalingGroupOutput, error) {
+	m.DeleteAutoScalingGroupInputs = append(m.DeleteAutoScalingGroupInputs, input)
 	return nil, nil
 }
 
@@ -231,6 +246,12 @@ func (m *ASGClient) PutScalingPolicy(input *autoscaling.PutScalingPolicyInput) (
 	return &autoscaling.PutScalingPolicyOutput{PolicyARN: to.Strp("arn")}, nil
 }
 
+// PutScheduledUpdateGroupAction returns
+func (m *ASGClient) PutScheduledUpdateGroupAction(input *autoscaling.PutScheduledUpdateGroupActionInput) (*autoscaling.PutScheduledUpdateGroupActionOutput, error) {
+	m.PutScheduledUpdateGroupActionInputs = append(m.PutScheduledUpdateGroupActionInputs, input)
+	return nil, nil
+}
+
 func (m *ASGClient) DetachLoadBalancers(input *autoscaling.DetachLoadBalancersInput) (*autoscaling.DetachLoadBalancersOutput, error) {
 	return nil, m.DetachLoadBalancersError
 }
@@ -257,3 +278,55 @@ func (m *ASGClient) UpdateAutoScalingGroup(input *autoscaling.UpdateAutoScalingG
 	m.UpdateAutoScalingGroupLastInput = input
 	return nil, nil
 }
+
+// DescribeAccountLimits returns the output or 10 of 200 ASGs used
+func (m *ASGClient) DescribeAccountLimits(in *autoscaling.DescribeAccountLimitsInput) (*autoscaling.DescribeAccountLimitsOutput, error) {
+	if m.DescribeAccountLimitsOutput != nil {
+		return m.DescribeAccountLimitsOutput, nil
+	}
+
+	return &autoscaling.DescribeAccountLimitsOutput{
+		MaxNumberOfAutoScalingGroups:    to.Int64p(200),
+		NumberOfAutoScalingGroups:       to.Int64p(10),
+		MaxNumberOfLaunchConfigurations: to.Int64p(200),
+		NumberOfLaunchConfigurations:    to.Int64p(10),
+	}, nil
+}
+
+// DescribeScheduledActions returns the output or no actions
+func (m *ASGClient) DescribeScheduledActions(input *autoscaling.DescribeScheduledActionsInput) (*autoscaling.DescribeScheduledActionsOutput, error) {
+	if m.DescribeScheduledActionsOutput != nil {
+		return m.DescribeScheduledActionsOutput, nil
+	}
+	return &autoscaling.DescribeScheduledActionsOutput{}, nil
+}
+
+// BatchDeleteScheduledAction returns
+func (m *ASGClient) BatchDeleteScheduledAction(input *autoscaling.BatchDeleteScheduledActionInput) (*autoscaling.BatchDeleteScheduledActionOutput, error) {
+	m.BatchDeleteScheduledActionInputs = append(m.BatchDeleteScheduledActionInputs, input)
+	return &autoscaling.BatchDeleteScheduledActionOutput{}, nil
+}
+
+// CreateOrUpdateTags returns
+func (m *ASGClient) CreateOrUpdateTags(input *autoscaling.CreateOrUpdateTagsInput) (*autoscaling.CreateOrUpdateTagsOutput, error) {
+	m.CreateOrUpdateTagsInputs = append(m.CreateOrUpdateTagsInputs, input)
+	return &autoscaling.CreateOrUpdateTagsOutput{}, nil
+}
+
+// DeleteTags returns
+func (m *ASGClient) DeleteTags(input *autoscaling.DeleteTagsInput) (*autoscaling.DeleteTagsOutput, error) {
+	m.DeleteTagsInputs = append(m.DeleteTagsInputs, input)
+	return &autoscaling.DeleteTagsOutput{}, nil
+}
+
+// AttachLoadBalancers returns
+func (m *ASGClient) AttachLoadBalancers(input *autoscaling.AttachLoadBalancersInput) (*autoscaling.AttachLoadBalancersOutput, error) {
+	m.AttachLoadBalancersInputs = append(m.AttachLoadBalancersInputs, input)
+	return &autoscaling.AttachLoadBalancersOutput{}, nil
+}
+
+// AttachLoadBalancerTargetGroups returns
+func (m *ASGClient) AttachLoadBalancerTargetGroups(input *autoscaling.AttachLoadBalancerTargetGroupsInput) (*autoscaling.AttachLoadBalancerTargetGroupsOutput, error) {
+	m.AttachLoadBalancerTargetGroupsInputs = append(m.AttachLoadBalancerTargetGroupsInputs, input)
+	return &autoscaling.AttachLoadBalancerTargetGroupsOutput{}, nil
+}
@@ -8,10 +8,24 @@ import (
 // CWClient struct
 type CWClient struct {
 	aws.CWAPI
+	DeleteAlarmsInputs []*cloudwatch.DeleteAlarmsInput
+
+	// Metric name to the sum and maximum returned
+	MetricSums                map[string]float64
+	GetMetricStatisticsInputs []*cloudwatch.GetMetricStatisticsInput
+}
+
+// AddMetricSum sets the sum returned for a metric
+func (m *CWClient) AddMetricSum(metricName string, sum float64) {
+	if m.MetricSums == nil {
+		m.MetricSums = map[string]float64{}
+	}
+	m.MetricSums[metricName] = sum
 }
 
 // DeleteAlarms returns
 func (m *CWClient) DeleteAlarms(input *cloudwatch.DeleteAlarmsInput) (*cloudwatch.DeleteAlarmsOutput, error) {
+	m.DeleteAlarmsInputs = append(m.DeleteAlarmsInputs, input)
 	return nil, nil
 }
 
@@ -19,3 +33,17 @@ func (m *CWClient) DeleteAlarms(input *cloudwatch.DeleteAlarmsInput) (*cloudwatc
 func (m *CWClient) PutMetricAlarm(input *cloudwatch.PutMetricAlarmInput) (*cloudwatch.PutMetricAlarmOutput, error) {
 	return nil, nil
 }
+
+// GetMetricStatistics returns
+func (m *CWClient) GetMetricStatistics(input *cloudwatch.GetMetricStatisticsInput) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	m.GetMetricStatisticsInputs = append(m.GetMetricStatisticsInputs, input)
+
+	sum, ok := m.MetricSums[*input.MetricName]
+	if !ok {
+		return &cloudwatch.GetMetricStatisticsOutput{}, nil
+	}
+
+	return &cloudwatch.GetMetricStatisticsOutput{
+		Datapoints: []*cloudwatch.Datapoint{&cloudwatch.Datapoint{Sum: &sum, Maximum: &sum}},
+	}, nil
+}
@@ -33,6 +33,12 @@ type EC2Client struct {
 	DescribeSubnetsResp        *DescribeSubnetsResponse
 	DescribeImagesResp         *DescribeImagesResponse
 	PlacementGroups            []*ec2.PlacementGroup
+	LaunchTemplates            map[string]*ec2.CreateLaunchTemplateInput
+
+	// Availability zone to the instance types not offered there
+	NotOfferedInstanceTypes map[string][]string
+	// Instance type to vCPUs, default 2
+	InstanceTypeVCPUs map[string]int64
 }
 
 func (m *EC2Client) init() {
@@ -42,6 +48,9 @@ func (m *EC2Client) init() {
 	if m.PlacementGroups == nil {
 		m.PlacementGroups = []*ec2.PlacementGroup{}
 	}
+	if m.LaunchTemplates == nil {
+		m.LaunchTemplates = map[string]*ec2.CreateLaunchTemplateInput{}
+	}
 }
 
 // AddSecurityGroup returns
@@ -80,7 +89,8 @@ func (m *EC2Client) AddSubnet(nameTag string, id string) {
 		Resp: &ec2.DescribeSubnetsOutput{
 			Subnets: []*ec2.Subnet{
 				&ec2.Subnet{
-					SubnetId: to.Strp(id),
+					SubnetId:         to.Strp(id),
+					AvailabilityZone: to.Strp("us-east-1a"),
 					Tags: []*ec2.Tag{
 						&ec2.Tag{Key: to.Strp("Name"), Value: to.Strp(nameTag)},
 						&ec2.Tag{Key: to.Strp("DeployWith"), Value: to.Strp("odin")},
@@ -151,3 +161,100 @@ func (m *EC2Client) CreatePlacementGroup(in *ec2.CreatePlacementGroupInput) (*ec
 
 	return nil, nil
 }
+
+// CreateLaunchTemplate returns
+func (m *EC2Client) CreateLaunchTemplate(in *ec2.CreateLaunchTemplateInput) (*ec2.CreateLaunchTemplateOutput, error) {
+	m.init()
+	m.LaunchTemplates[*in.LaunchTemplateName] = in
+	return &ec2.CreateLaunchTemplateOutput{
+		LaunchTemplate: &ec2.LaunchTemplate{LaunchTemplateName: in.LaunchTemplateName},
+	}, nil
+}
+
+// DeleteLaunchTemplate returns
+func (m *EC2Client) DeleteLaunchTemplate(in *ec2.DeleteLaunchTemplateInput) (*ec2.DeleteLaunchTemplateOutput, error) {
+	m.init()
+	delete(m.LaunchTemplates, *in.LaunchTemplateName)
+	return &ec2.DeleteLaunchTemplateOutput{}, nil
+}
+
+// DescribeLaunchTemplateVersions returns
+func (m *EC2Client) DescribeLaunchTemplateVersions(in *ec2.DescribeLaunchTemplateVersionsInput) (*ec2.DescribeLaunchTemplateVersionsOutput, error) {
+	m.init()
+	template := m.LaunchTemplates[*in.LaunchTemplateName]
+	if template == nil {
+		return nil, fmt.Errorf("LaunchTemplate not found")
+	}
+
+	data := &ec2.ResponseLaunchTemplateData{}
+	if template.LaunchTemplateData != nil {
+		data.ImageId = template.LaunchTemplateData.ImageId
+		data.InstanceType = template.LaunchTemplateData.InstanceType
+	}
+
+	return &ec2.DescribeLaunchTemplateVersionsOutput{
+		LaunchTemplateVersions: []*ec2.LaunchTemplateVersion{
+			&ec2.LaunchTemplateVersion{
+				LaunchTemplateName: in.LaunchTemplateName,
+				LaunchTemplateData: data,
+			},
+		},
+	}, nil
+}
+
+// DescribeInstanceTypes returns
+func (m *EC2Client) DescribeInstanceTypes(in *ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error) {
+	types := []*ec2.InstanceTypeInfo{}
+	for _, it := range in.InstanceTypes {
+		vcpus, ok := m.InstanceTypeVCPUs[*it]
+		if !ok {
+			vcpus = 2
+		}
+
+		types = append(types, &ec2.InstanceTypeInfo{
+			InstanceType: it,
+			VCpuInfo:     &ec2.VCpuInfo{DefaultVCpus: to.Int64p(vcpus)},
+		})
+	}
+
+	return &ec2.DescribeInstanceTypesOutput{InstanceTypes: types}, nil
+}
+
+// DescribeInstanceTypeOfferings returns all filtered types in all filtered locations unless not offered
+func (m *EC2Client) DescribeInstanceTypeOfferings(in *ec2.DescribeInstanceTypeOfferingsInput) (*ec2.DescribeInstanceTypeOfferingsOutput, error) {
+	types, locations := []*string{}, []*string{}
+	for _, f := range in.Filters {
+		switch *f.Name {
+		case "instance-type":
+			types = f.Values
+		case "location":
+			locations = f.Values
+		}
+	}
+
+	offerings := []*ec2.InstanceTypeOffering{}
+	for _, location := range locations {
+		for _, it := range types {
+			if containsStr(m.NotOfferedInstanceTypes[*location], *it) {
+				continue
+			}
+
+			offerings = append(offerings, &ec2.InstanceTypeOffering{
+				InstanceType: it,
+				Location:     location,
+				LocationType: in.LocationType,
+			})
+		}
+	}
+
+	return &ec2.DescribeInstanceTypeOfferingsOutput{InstanceTypeOfferings: offerings}, nil
+}
+
+func containsStr(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
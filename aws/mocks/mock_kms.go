@@ -0,0 +1,103 @@
+package mocks
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
+)
+
+// KMSClient signs with an ECC_NIST_P256 key generated on first use
+type KMSClient struct {
+	aws.KMSAPI
+	SignInputs []*kms.SignInput
+	Aliases    map[string]string // alias to key ARN
+	key        *ecdsa.PrivateKey
+}
+
+// AddAlias adds an alias of the key ARN
+func (m *KMSClient) AddAlias(alias string, keyARN string) {
+	if m.Aliases == nil {
+		m.Aliases = map[string]string{}
+	}
+	m.Aliases[alias] = keyARN
+}
+
+// DescribeKey returns
+func (m *KMSClient) DescribeKey(in *kms.DescribeKeyInput) (*kms.DescribeKeyOutput, error) {
+	arn, ok := m.Aliases[to.Strs(in.KeyId)]
+	if !ok {
+		return nil, fmt.Errorf("NotFoundException: Alias %v is not found", to.Strs(in.KeyId))
+	}
+
+	return &kms.DescribeKeyOutput{KeyMetadata: &kms.KeyMetadata{Arn: to.Strp(arn)}}, nil
+}
+
+func (m *KMSClient) init() {
+	if m.key != nil {
+		return
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	m.key = key
+}
+
+// PublicKeyDER returns the base64 DER encoded public key
+func (m *KMSClient) PublicKeyDER() string {
+	m.init()
+	der, _ := x509.MarshalPKIXPublicKey(&m.key.PublicKey)
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+// GetPublicKey returns
+func (m *KMSClient) GetPublicKey(in *kms.GetPublicKeyInput) (*kms.GetPublicKeyOutput, error) {
+	m.init()
+	der, err := x509.MarshalPKIXPublicKey(&m.key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kms.GetPublicKeyOutput{
+		KeyId:             in.KeyId,
+		KeySpec:           to.Strp("ECC_NIST_P256"),
+		KeyUsage:          to.Strp("SIGN_VERIFY"),
+		PublicKey:         der,
+		SigningAlgorithms: []*string{to.Strp("ECDSA_SHA_256")},
+	}, nil
+}
+
+// Sign returns
+func (m *KMSClient) Sign(in *kms.SignInput) (*kms.SignOutput, error) {
+	m.init()
+	m.SignInputs = append(m.SignInputs, in)
+
+	if to.Strs(in.SigningAlgorithm) != "ECDSA_SHA_256" {
+		return nil, fmt.Errorf("unsupported signing algorithm %v", to.Strs(in.SigningAlgorithm))
+	}
+
+	digest := sha256.Sum256(in.Message)
+	r, s, err := ecdsa.Sign(rand.Reader, m.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	// KMS returns the DER encoded signature
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	if err != nil {
+		return nil, err
+	}
+
+	return &kms.SignOutput{KeyId: in.KeyId, Signature: sig, SigningAlgorithm: in.SigningAlgorithm}, nil
+}
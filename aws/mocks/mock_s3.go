@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/coinbase/step/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+)
+
+// S3Client returns
+type S3Client struct {
+	mocks.MockS3Client
+	KMSKeyIDs map[string]*string
+}
+
+func (m *S3Client) init() {
+	if m.KMSKeyIDs == nil {
+		m.KMSKeyIDs = map[string]*string{}
+	}
+}
+
+// AddKMSKeyID sets the KMS key an object is encrypted with
+func (m *S3Client) AddKMSKeyID(key string, kmsKeyID string) {
+	m.init()
+	m.KMSKeyIDs[key] = &kmsKeyID
+}
+
+// PutObject returns
+func (m *S3Client) PutObject(in *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	m.init()
+	m.KMSKeyIDs[*in.Key] = in.SSEKMSKeyId
+	return m.MockS3Client.PutObject(in)
+}
+
+// HeadObject returns
+func (m *S3Client) HeadObject(in *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	m.init()
+	if _, ok := m.GetObjectResp[*in.Key]; !ok {
+		return nil, mocks.AWSS3NotFoundError()
+	}
+
+	// Objects added without PutObject are treated as KMS encrypted
+	return &s3.HeadObjectOutput{
+		ServerSideEncryption: to.Strp("aws:kms"),
+		SSEKMSKeyId:          m.KMSKeyIDs[*in.Key],
+	}, nil
+}
@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
+)
+
+// ServiceQuotasClient struct
+type ServiceQuotasClient struct {
+	aws.ServiceQuotasAPI
+	Quotas map[string]float64
+}
+
+// AddQuota sets the value of a quota code
+func (m *ServiceQuotasClient) AddQuota(code string, value float64) {
+	if m.Quotas == nil {
+		m.Quotas = map[string]float64{}
+	}
+	m.Quotas[code] = value
+}
+
+// GetServiceQuota returns the quota value or 1000 by default
+func (m *ServiceQuotasClient) GetServiceQuota(in *servicequotas.GetServiceQuotaInput) (*servicequotas.GetServiceQuotaOutput, error) {
+	value, ok := m.Quotas[*in.QuotaCode]
+	if !ok {
+		value = 1000
+	}
+
+	return &servicequotas.GetServiceQuotaOutput{
+		Quota: &servicequotas.ServiceQuota{QuotaCode: in.QuotaCode, Value: to.Float64p(value)},
+	}, nil
+}
@@ -3,14 +3,26 @@ package mocks
 import (
 	"github.com/aws/aws-sdk-go/service/sns"
 	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
 )
 
 // SNSClient returns
 type SNSClient struct {
 	aws.SNSAPI
+	PublishInputs []*sns.PublishInput
+	PublishError  error
 }
 
 // GetTopicAttributes returns
 func (m *SNSClient) GetTopicAttributes(in *sns.GetTopicAttributesInput) (*sns.GetTopicAttributesOutput, error) {
 	return nil, nil
 }
+
+// Publish returns
+func (m *SNSClient) Publish(in *sns.PublishInput) (*sns.PublishOutput, error) {
+	m.PublishInputs = append(m.PublishInputs, in)
+	if m.PublishError != nil {
+		return nil, m.PublishError
+	}
+	return &sns.PublishOutput{MessageId: to.Strp("message")}, nil
+}
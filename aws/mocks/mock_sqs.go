@@ -0,0 +1,25 @@
+package mocks
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/coinbase/odin/aws"
+)
+
+// SQSClient returns
+type SQSClient struct {
+	aws.SQSAPI
+	MissingQueues []string
+}
+
+// GetQueueUrl returns the queue URL unless the queue is missing
+func (m *SQSClient) GetQueueUrl(in *sqs.GetQueueUrlInput) (*sqs.GetQueueUrlOutput, error) {
+	if containsStr(m.MissingQueues, *in.QueueName) {
+		return nil, awserr.New(sqs.ErrCodeQueueDoesNotExist, "The specified queue does not exist", nil)
+	}
+
+	url := fmt.Sprintf("https://sqs.us-east-1.amazonaws.com/%v/%v", *in.QueueOwnerAWSAccountId, *in.QueueName)
+	return &sqs.GetQueueUrlOutput{QueueUrl: &url}, nil
+}
@@ -0,0 +1,161 @@
+package quota
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/aws/cw"
+	"github.com/coinbase/step/utils/to"
+)
+
+// VCPUQuota is the On-Demand vCPU quota for a group of instance families
+type VCPUQuota struct {
+	Class     string // The AWS/Usage Class dimension
+	QuotaCode string
+}
+
+// VCPU_QUOTAS are the On-Demand vCPU quotas by instance family prefix
+// checked in order, any other family is Standard
+var VCPU_QUOTAS = []struct {
+	Prefix string
+	Quota  VCPUQuota
+}{
+	{"inf", VCPUQuota{"Inf/OnDemand", "L-1945791B"}},
+	{"dl", VCPUQuota{"DL/OnDemand", "L-6E869C2A"}},
+	{"trn", VCPUQuota{"Trn/OnDemand", "L-2C3B7624"}},
+	{"hpc", VCPUQuota{"HPC/OnDemand", "L-F7808C92"}},
+	{"vt", VCPUQuota{"G/OnDemand", "L-DB2E81BA"}},
+	{"f", VCPUQuota{"F/OnDemand", "L-74FC7D96"}},
+	{"g", VCPUQuota{"G/OnDemand", "L-DB2E81BA"}},
+	{"p", VCPUQuota{"P/OnDemand", "L-417A185B"}},
+	{"x", VCPUQuota{"X/OnDemand", "L-7295265B"}},
+}
+
+// StandardVCPUQuota is for the A, C, D, H, I, M, R, T and Z families
+var StandardVCPUQuota = VCPUQuota{"Standard/OnDemand", "L-1216C47A"}
+
+// VCPUQuotaFor returns the On-Demand vCPU quota an instance type counts towards
+func VCPUQuotaFor(instanceType string) VCPUQuota {
+	for _, q := range VCPU_QUOTAS {
+		if strings.HasPrefix(instanceType, q.Prefix) {
+			return q.Quota
+		}
+	}
+
+	return StandardVCPUQuota
+}
+
+// RemainingVCPUs returns the quota minus the current usage
+func (q VCPUQuota) RemainingVCPUs(sqc aws.ServiceQuotasAPI, cwc aws.CWAPI, now time.Time) (int64, error) {
+	out, err := sqc.GetServiceQuota(&servicequotas.GetServiceQuotaInput{
+		ServiceCode: to.Strp("ec2"),
+		QuotaCode:   to.Strp(q.QuotaCode),
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	if out.Quota == nil || out.Quota.Value == nil {
+		return 0, fmt.Errorf("Quota %v has no value", q.QuotaCode)
+	}
+
+	usage, err := cw.Max(cwc, "AWS/Usage", "ResourceCount", map[string]string{
+		"Service":  "EC2",
+		"Type":     "Resource",
+		"Resource": "vCPU",
+		"Class":    q.Class,
+	}, now.Add(-5*time.Minute), now)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(*out.Quota.Value - usage), nil
+}
+
+// RemainingASGs returns how many more ASGs the account can create
+func RemainingASGs(asgc aws.ASGAPI) (int64, error) {
+	out, err := asgc.DescribeAccountLimits(&autoscaling.DescribeAccountLimitsInput{})
+	if err != nil {
+		return 0, err
+	}
+
+	if out.MaxNumberOfAutoScalingGroups == nil || out.NumberOfAutoScalingGroups == nil {
+		return 0, fmt.Errorf("Auto Scaling account limits not found")
+	}
+
+	return *out.MaxNumberOfAutoScalingGroups - *out.NumberOfAutoScalingGroups, nil
+}
+
+// InstanceTypeVCPUs returns the default vCPUs of each instance type
+func InstanceTypeVCPUs(ec2c aws.EC2API, instanceTypes []*string) (map[string]int64, error) {
+	input := &ec2.DescribeInstanceTypesInput{InstanceTypes: instanceTypes}
+	vcpus := map[string]int64{}
+
+	for {
+		out, err := ec2c.DescribeInstanceTypes(input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, it := range out.InstanceTypes {
+			if it.InstanceType == nil || it.VCpuInfo == nil || it.VCpuInfo.DefaultVCpus == nil {
+				continue
+			}
+			vcpus[*it.InstanceType] = *it.VCpuInfo.DefaultVCpus
+		}
+
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+
+	for _, it := range instanceTypes {
+		if _, ok := vcpus[*it]; !ok {
+			return nil, fmt.Errorf("Instance type %v not found", *it)
+		}
+	}
+
+	return vcpus, nil
+}
+
+// OfferedInstanceTypes returns the instance types offered in each availability zone
+func OfferedInstanceTypes(ec2c aws.EC2API, instanceTypes []*string, azs []*string) (map[string][]string, error) {
+	input := &ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: to.Strp("availability-zone"),
+		Filters: []*ec2.Filter{
+			&ec2.Filter{Name: to.Strp("instance-type"), Values: instanceTypes},
+			&ec2.Filter{Name: to.Strp("location"), Values: azs},
+		},
+	}
+
+	offered := map[string][]string{}
+
+	for {
+		out, err := ec2c.DescribeInstanceTypeOfferings(input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, o := range out.InstanceTypeOfferings {
+			if o.Location == nil || o.InstanceType == nil {
+				continue
+			}
+			offered[*o.Location] = append(offered[*o.Location], *o.InstanceType)
+		}
+
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+
+	return offered, nil
+}
@@ -0,0 +1,47 @@
+package quota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_VCPUQuotaFor(t *testing.T) {
+	assert.Equal(t, "Standard/OnDemand", VCPUQuotaFor("c5.large").Class)
+	assert.Equal(t, "Standard/OnDemand", VCPUQuotaFor("d2.xlarge").Class)
+	assert.Equal(t, "Standard/OnDemand", VCPUQuotaFor("t3.micro").Class)
+	assert.Equal(t, "DL/OnDemand", VCPUQuotaFor("dl1.24xlarge").Class)
+	assert.Equal(t, "Inf/OnDemand", VCPUQuotaFor("inf1.xlarge").Class)
+	assert.Equal(t, "G/OnDemand", VCPUQuotaFor("g4dn.xlarge").Class)
+	assert.Equal(t, "G/OnDemand", VCPUQuotaFor("vt1.3xlarge").Class)
+	assert.Equal(t, "P/OnDemand", VCPUQuotaFor("p3.2xlarge").Class)
+}
+
+func Test_RemainingVCPUs(t *testing.T) {
+	sqc := &mocks.ServiceQuotasClient{}
+	cwc := &mocks.CWClient{}
+
+	sqc.AddQuota("L-1216C47A", 256)
+	cwc.AddMetricSum("ResourceCount", 200)
+
+	remaining, err := StandardVCPUQuota.RemainingVCPUs(sqc, cwc, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(56), remaining)
+
+	input := cwc.GetMetricStatisticsInputs[0]
+	assert.Equal(t, "AWS/Usage", *input.Namespace)
+	assert.Equal(t, "Maximum", *input.Statistics[0])
+}
+
+func Test_OfferedInstanceTypes(t *testing.T) {
+	ec2c := &mocks.EC2Client{}
+	ec2c.NotOfferedInstanceTypes = map[string][]string{"us-east-1b": []string{"c5.large"}}
+
+	offered, err := OfferedInstanceTypes(ec2c, []*string{to.Strp("c5.large")}, []*string{to.Strp("us-east-1a"), to.Strp("us-east-1b")})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c5.large"}, offered["us-east-1a"])
+	assert.Equal(t, 0, len(offered["us-east-1b"]))
+}
@@ -10,8 +10,9 @@ import (
 
 // Subnet struct
 type Subnet struct {
-	SubnetID      *string
-	DeployWithTag *string
+	SubnetID         *string
+	DeployWithTag    *string
+	AvailabilityZone *string
 }
 
 // Find returns a list of subnets for either ids or tags NO MIXING , e.g. subnet-00000000 OR privatea
@@ -92,8 +93,9 @@ func find(ec2Client aws.EC2API, in *ec2.DescribeSubnetsInput) ([]*Subnet, error)
 	subnets := []*Subnet{}
 	for _, subnet := range output.Subnets {
 		subnets = append(subnets, &Subnet{
-			subnet.SubnetId,
-			aws.FetchEc2Tag(subnet.Tags, to.Strp("DeployWith")),
+			SubnetID:         subnet.SubnetId,
+			DeployWithTag:    aws.FetchEc2Tag(subnet.Tags, to.Strp("DeployWith")),
+			AvailabilityZone: subnet.AvailabilityZone,
 		})
 	}
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math"
+	"os"
 	"sort"
 	"strings"
 	"time"
@@ -54,6 +55,11 @@ func prepareRelease(release *models.Release, region *string, accountID *string)
 
 	release.ReleaseID = to.TimeUUID("release-")
 	release.CreatedAt = to.Timep(time.Now())
+
+	// The release can choose its key, otherwise the environment can
+	if is.EmptyStr(release.UserDataKMSKey) && os.Getenv("ODIN_USER_DATA_KMS_KEY") != "" {
+		release.UserDataKMSKey = to.Strp(os.Getenv("ODIN_USER_DATA_KMS_KEY"))
+	}
 }
 
 func parseRelease(releaseFile string) (*models.Release, error) {
@@ -81,6 +87,22 @@ func parseUserData(releaseFile string) (*string, error) {
 	return to.Strp(string(rawUserData)), nil
 }
 
+// parseServiceUserData returns the services own userdata, or nil if there is no file
+func parseServiceUserData(releaseFile string, serviceName string) (*string, error) {
+	userdataFile := fmt.Sprintf("%v.%v.userdata", releaseFile, serviceName)
+	rawUserData, err := ioutil.ReadFile(userdataFile)
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return to.Strp(string(rawUserData)), nil
+}
+
 func releaseFromFile(releaseFile *string, region *string, accountID *string) (*models.Release, error) {
 	release, err := parseRelease(*releaseFile)
 	if err != nil {
@@ -95,6 +117,24 @@ func releaseFromFile(releaseFile *string, region *string, accountID *string) (*m
 	release.SetUserData(userdata)
 	release.UserDataSHA256 = to.Strp(to.SHA256Str(userdata))
 
+	for name, service := range release.Services {
+		if service == nil {
+			continue
+		}
+
+		serviceUserData, err := parseServiceUserData(*releaseFile, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if serviceUserData == nil {
+			continue
+		}
+
+		service.SetUserData(serviceUserData)
+		service.UserDataSHA256 = to.Strp(to.SHA256Str(serviceUserData))
+	}
+
 	prepareRelease(release, region, accountID)
 
 	if err := validateClientAttributes(release); err != nil {
@@ -200,7 +240,29 @@ func serviceStr(name string, service *models.Service) string {
 				dots = append(dots, fmt.Sprintf("%v.%v", GRAY, NC))
 			}
 		}
-		return fmt.Sprintf("%s: %v", name, strings.Join(dots, ""))
+		str := fmt.Sprintf("%s: %v", name, strings.Join(dots, ""))
+
+		if service.HealthReport.ErrorRate != nil {
+			str += fmt.Sprintf(" 5xx %.2f%%", *service.HealthReport.ErrorRate*100)
+		}
+
+		if service.HealthReport.RolloutStep != nil && service.HealthReport.RolloutSteps != nil {
+			str += fmt.Sprintf(" step %v/%v", *service.HealthReport.RolloutStep+1, *service.HealthReport.RolloutSteps)
+		}
+
+		if service.HealthReport.AwaitingApproval {
+			str += " awaiting odin continue"
+		}
+
+		if service.HealthReport.WaitRemaining != nil {
+			str += fmt.Sprintf(" waiting %v", time.Duration(*service.HealthReport.WaitRemaining)*time.Second)
+		}
+
+		if service.HealthReport.TrafficPercent != nil {
+			str += fmt.Sprintf(" traffic %v%%", *service.HealthReport.TrafficPercent)
+		}
+
+		return str
 	}
 
 	return ""
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/deployer/models"
+	"github.com/coinbase/step/execution"
+	"github.com/coinbase/step/utils/to"
+)
+
+// Continue approves a release paused for approval
+func Continue(step_fn *string, releaseFile *string) error {
+	region, accountID := to.RegionAccount()
+	release, err := releaseFromFile(releaseFile, region, accountID)
+	if err != nil {
+		return err
+	}
+
+	deployerARN := to.StepArn(region, accountID, step_fn)
+
+	return continueRelease(&aws.ClientsStr{}, release, deployerARN)
+}
+
+func continueRelease(awsc aws.Clients, release *models.Release, deployerARN *string) error {
+	exec, err := execution.FindExecution(awsc.SFNClient(nil, nil, nil), deployerARN, release.ExecutionPrefix())
+	if err != nil {
+		return err
+	}
+
+	if exec == nil {
+		return fmt.Errorf("Cannot find current execution of release with prefix %q", release.ExecutionPrefix())
+	}
+
+	if err := release.Continue(awsc.S3Client(nil, nil, nil)); err != nil {
+		return err
+	}
+
+	exec.WaitForExecution(awsc.SFNClient(nil, nil, nil), 1, waiter)
+	fmt.Println("")
+	return nil
+}
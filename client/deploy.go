@@ -2,45 +2,50 @@ package client
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/aws/aws-sdk-go/service/sfn/sfniface"
 	"github.com/coinbase/odin/aws"
 	"github.com/coinbase/odin/deployer/models"
 	"github.com/coinbase/step/aws/s3"
 	"github.com/coinbase/step/execution"
+	"github.com/coinbase/step/utils/is"
 	"github.com/coinbase/step/utils/to"
 )
 
-// Deploy attempts to deploy release
-func Deploy(step_fn *string, releaseFile *string) error {
+// Deploy attempts to deploy release, signing it if signKey is given
+func Deploy(step_fn *string, releaseFile *string, signKey *string) error {
 	region, accountID := to.RegionAccount()
 	release, err := releaseFromFile(releaseFile, region, accountID)
 	if err != nil {
 		return err
 	}
 
+	awsc := &aws.ClientsStr{}
+
+	if len(release.Regions) > 0 || len(release.Accounts) > 0 {
+		return deployTargets(awsc, release, step_fn, accountID, signKey, time.Second)
+	}
+
+	if err := signRelease(awsc, release, signKey); err != nil {
+		return err
+	}
+
 	deployerARN := to.StepArn(region, accountID, step_fn)
 
-	return deploy(&aws.ClientsStr{}, release, deployerARN)
+	return deploy(awsc, release, deployerARN)
 }
 
-func kMSKey() *string {
-	// TODO: allow customization of the KMS key from the command line utility
+// kMSKey returns the KMS key the userdata is encrypted with
+func kMSKey(release *models.Release) *string {
+	if !is.EmptyStr(release.UserDataKMSKey) {
+		return release.UserDataKMSKey
+	}
 	return to.Strp("alias/aws/s3")
 }
 
 func deploy(awsc aws.Clients, release *models.Release, deployerARN *string) error {
-	// Uploading the Release to S3 to match SHAs
-	if err := s3.PutStruct(awsc.S3Client(nil, nil, nil), release.Bucket, release.ReleasePath(), release); err != nil {
-		return err
-	}
-
-	// Uploading the encrypted Userdata to S3
-	if err := s3.PutSecure(awsc.S3Client(nil, nil, nil), release.Bucket, release.UserDataPath(), release.UserData(), kMSKey()); err != nil {
-		return err
-	}
-
-	exec, err := findOrCreateExec(awsc.SFNClient(nil, nil, nil), deployerARN, release)
+	exec, err := startDeploy(awsc, nil, release, deployerARN)
 	if err != nil {
 		return err
 	}
@@ -51,14 +56,47 @@ func deploy(awsc aws.Clients, release *models.Release, deployerARN *string) erro
 	return nil
 }
 
+// startDeploy uploads the release to the region then finds or starts its execution
+func startDeploy(awsc aws.Clients, region *string, release *models.Release, deployerARN *string) (*execution.Execution, error) {
+	s3c := awsc.S3Client(region, nil, nil)
+
+	// Uploading the Release to S3 to match SHAs
+	if err := s3.PutStruct(s3c, release.Bucket, release.ReleasePath(), release); err != nil {
+		return nil, err
+	}
+
+	// Uploading the encrypted Userdata to S3
+	if err := s3.PutSecure(s3c, release.Bucket, release.UserDataPath(), release.UserData(), kMSKey(release)); err != nil {
+		return nil, err
+	}
+
+	// Uploading the encrypted Userdata of services that override it
+	for name, service := range release.Services {
+		if service == nil || !service.OverridesUserData() {
+			continue
+		}
+
+		if err := s3.PutSecure(s3c, release.Bucket, release.ServiceUserDataPath(name), service.RawUserData(), kMSKey(release)); err != nil {
+			return nil, err
+		}
+	}
+
+	return findOrCreateExec(awsc.SFNClient(region, nil, nil), deployerARN, release)
+}
+
 func findOrCreateExec(sfnc sfniface.SFNAPI, deployer *string, release *models.Release) (*execution.Execution, error) {
-	exec, err := execution.FindExecution(sfnc, deployer, release.ExecutionPrefix())
+	executions, err := runningExecutions(sfnc, deployer, release.ExecutionPrefix())
 	if err != nil {
 		return nil, err
 	}
 
-	if exec != nil {
-		return exec, nil
+	for _, exec := range executions {
+		// The deployer can be running the project config for other accounts
+		if exec.accountID != nil && release.AwsAccountID != nil && *exec.accountID != *release.AwsAccountID {
+			continue
+		}
+
+		return &execution.Execution{ExecutionArn: exec.ExecutionArn, StartDate: exec.StartDate}, nil
 	}
 
 	return execution.StartExecution(sfnc, deployer, release.ExecutionName(), release)
@@ -1,8 +1,11 @@
 package client
 
 import (
+	"os"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/service/sfn"
 	"github.com/coinbase/odin/aws/mocks"
 	"github.com/coinbase/step/utils/to"
 	"github.com/stretchr/testify/assert"
@@ -17,3 +20,79 @@ func Test_Deploy(t *testing.T) {
 	err := deploy(awsc, r, to.Strp("deployerARN"))
 	assert.NoError(t, err)
 }
+
+func Test_Deploy_ServiceUserData(t *testing.T) {
+	awsc := mocks.MockAWS()
+	r := minimalRelease(t)
+	r.Release.SetDefaults(to.Strp("region"), to.Strp("accountid"), "")
+	r.SetUserData(to.Strp("#cloud_config"))
+	r.Services["web"].SetUserData(to.Strp("#web_config"))
+	r.Services["web"].UserDataSHA256 = to.Strp(to.SHA256Str(to.Strp("#web_config")))
+
+	err := deploy(awsc, r, to.Strp("deployerARN"))
+	assert.NoError(t, err)
+
+	uploaded := awsc.S3.GetObjectResp[*r.ServiceUserDataPath("web")]
+	assert.NotNil(t, uploaded)
+	assert.Equal(t, "#web_config", uploaded.Body)
+}
+
+func Test_Deploy_KMSKey(t *testing.T) {
+	awsc := mocks.MockAWS()
+	r := minimalRelease(t)
+	r.Release.SetDefaults(to.Strp("region"), to.Strp("accountid"), "")
+	r.SetUserData(to.Strp("#cloud_config"))
+
+	assert.NoError(t, deploy(awsc, r, to.Strp("deployerARN")))
+	assert.Equal(t, "alias/aws/s3", *awsc.S3.KMSKeyIDs[*r.UserDataPath()])
+
+	r.UserDataKMSKey = to.Strp("alias/odin")
+	assert.NoError(t, deploy(awsc, r, to.Strp("deployerARN")))
+	assert.Equal(t, "alias/odin", *awsc.S3.KMSKeyIDs[*r.UserDataPath()])
+}
+
+func Test_PrepareRelease_KMSKeyEnv(t *testing.T) {
+	os.Setenv("ODIN_USER_DATA_KMS_KEY", "alias/env")
+	defer os.Unsetenv("ODIN_USER_DATA_KMS_KEY")
+
+	r := minimalRelease(t)
+	prepareRelease(r, to.Strp("region"), to.Strp("accountid"))
+	assert.Equal(t, "alias/env", *r.UserDataKMSKey)
+
+	// The release takes precedence
+	r.UserDataKMSKey = to.Strp("alias/release")
+	prepareRelease(r, to.Strp("region"), to.Strp("accountid"))
+	assert.Equal(t, "alias/release", *r.UserDataKMSKey)
+}
+
+func Test_FindOrCreateExec_Account(t *testing.T) {
+	awsc := mocks.MockAWS()
+	r := minimalRelease(t)
+	r.Release.SetDefaults(to.Strp("region"), to.Strp("accountid"), "")
+
+	awsc.SFN.ListExecutionsResp = &sfn.ListExecutionsOutput{
+		Executions: []*sfn.ExecutionListItem{
+			&sfn.ExecutionListItem{
+				Name:         r.ExecutionName(),
+				ExecutionArn: to.Strp("running-arn"),
+				StartDate:    to.Timep(time.Now()),
+			},
+		},
+	}
+	awsc.SFN.DescribeExecutionResp = &sfn.DescribeExecutionOutput{
+		Status: to.Strp("RUNNING"),
+		Input:  to.Strp(`{"release_id": "other", "aws_account_id": "accountid"}`),
+	}
+	awsc.SFN.StartExecutionResp = &sfn.StartExecutionOutput{ExecutionArn: to.Strp("started-arn")}
+
+	// The running execution is for the same account
+	exec, err := findOrCreateExec(awsc.SFN, to.Strp("deployerARN"), r)
+	assert.NoError(t, err)
+	assert.Equal(t, "running-arn", *exec.ExecutionArn)
+
+	// The running execution is for another account
+	r.AwsAccountID = to.Strp("staging")
+	exec, err = findOrCreateExec(awsc.SFN, to.Strp("deployerARN"), r)
+	assert.NoError(t, err)
+	assert.Equal(t, "started-arn", *exec.ExecutionArn)
+}
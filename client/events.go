@@ -0,0 +1,72 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/deployer/models"
+	"github.com/coinbase/step/utils/to"
+)
+
+// Events prints the audit log of a release, by default the most recent one
+func Events(step_fn *string, releaseFile *string, releaseID *string) error {
+	region, accountID := to.RegionAccount()
+	release, err := releaseFromFile(releaseFile, region, accountID)
+	if err != nil {
+		return err
+	}
+
+	deployerARN := to.StepArn(region, accountID, step_fn)
+
+	return events(&aws.ClientsStr{}, release, releaseID, deployerARN)
+}
+
+func events(awsc aws.Clients, release *models.Release, releaseID *string, deployerARN *string) error {
+	if releaseID == nil {
+		id, err := lastReleaseID(awsc.SFNClient(nil, nil, nil), deployerARN, release.ExecutionPrefix(), nil)
+		if err != nil {
+			return err
+		}
+
+		if id == nil {
+			return fmt.Errorf("Cannot find an execution with prefix %q", release.ExecutionPrefix())
+		}
+		releaseID = id
+	}
+
+	release.ReleaseID = releaseID
+
+	events, err := release.ReadEvents(awsc.S3Client(nil, nil, nil))
+	if err != nil {
+		return fmt.Errorf("Cannot find events s3://%v/%v: %v", *release.Bucket, *release.EventsPath(), err.Error())
+	}
+
+	fmt.Printf("Events for release %v\n", *releaseID)
+	for _, event := range events {
+		fmt.Println(eventStr(event))
+	}
+
+	return nil
+}
+
+func eventStr(event *models.AuditEvent) string {
+	line := fmt.Sprintf("%v %-18v %v", event.Time.Format(time.RFC3339), event.State, event.Type)
+
+	if event.Service != nil {
+		line = fmt.Sprintf("%v %v", line, *event.Service)
+	}
+
+	if event.Message != "" {
+		line = fmt.Sprintf("%v %v", line, event.Message)
+	}
+
+	if event.Details != nil {
+		if details, err := json.Marshal(event.Details); err == nil {
+			line = fmt.Sprintf("%v %v", line, string(details))
+		}
+	}
+
+	return line
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Events(t *testing.T) {
+	awsc := mocks.MockAWS()
+	r := minimalRelease(t)
+	r.Release.SetDefaults(to.Strp("region"), to.Strp("accountid"), "coinbase-odin-")
+	releaseID := r.ReleaseID
+
+	r.RecordEvent("Validated", nil, "", nil)
+	r.RecordEvent("ASGCreated", to.Strp("web"), "web-asg", map[string]int{"min_size": 1})
+	assert.NoError(t, r.WriteEvents(awsc.S3, "Deploy"))
+
+	// An explicit release
+	assert.NoError(t, events(awsc, r, releaseID, to.Strp("deployerARN")))
+	assert.Error(t, events(awsc, r, to.Strp("unknown"), to.Strp("deployerARN")))
+
+	// No executions to default to
+	assert.Error(t, events(awsc, r, nil, to.Strp("deployerARN")))
+
+	// Default to the most recent execution
+	awsc.SFN.ListExecutionsResp = &sfn.ListExecutionsOutput{
+		Executions: []*sfn.ExecutionListItem{
+			&sfn.ExecutionListItem{
+				Name:         r.ExecutionName(),
+				ExecutionArn: to.Strp("arn"),
+				StartDate:    to.Timep(time.Now()),
+			},
+		},
+	}
+
+	r.ReleaseID = releaseID
+	input, _ := to.PrettyJSON(r)
+	awsc.SFN.DescribeExecutionResp = &sfn.DescribeExecutionOutput{
+		Input:  &input,
+		Status: to.Strp("FAILED"),
+	}
+
+	r.ReleaseID = to.Strp("other")
+	assert.NoError(t, events(awsc, r, nil, to.Strp("deployerARN")))
+	assert.Equal(t, *releaseID, *r.ReleaseID)
+}
@@ -3,14 +3,23 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/service/sfn"
 	"github.com/coinbase/odin/aws"
 	"github.com/coinbase/step/bifrost"
 	"github.com/coinbase/step/execution"
+	"github.com/coinbase/step/utils/is"
 	"github.com/coinbase/step/utils/to"
 )
 
+var FAILURE_STATES = []string{
+	"FailureDirty",
+	"FailureClean",
+}
+
 // Release is the Data Structure passed between Client to Deployer
 type FailedRelease struct {
 	// Useful information from AWS
@@ -19,58 +28,246 @@ type FailedRelease struct {
 
 	ProjectName *string `json:"project_name,omitempty"`
 	ConfigName  *string `json:"config_name,omitempty"`
+	ReleaseID   *string `json:"release_id,omitempty"`
 
 	// Where the previous Catch Error should be located
 	Error *bifrost.ReleaseError `json:"error,omitempty"`
 }
 
-// List the recent failures and their causes
-func Failures(step_fn *string) error {
-	region, accountID := to.RegionAccount()
+// FailuresFilter selects which failures are listed
+type FailuresFilter struct {
+	Since       time.Time
+	ProjectName *string
+	ConfigName  *string
+	State       *string
+}
 
-	deployerARN := to.StepArn(region, accountID, step_fn)
+// Failure is a failed execution and its cause
+type Failure struct {
+	Name         *string    `json:"name,omitempty"`
+	ExecutionArn *string    `json:"execution_arn,omitempty"`
+	StartDate    *time.Time `json:"start_date,omitempty"`
 
-	awsc := &aws.ClientsStr{}
+	AwsAccountID *string `json:"aws_account_id,omitempty"`
+	AwsRegion    *string `json:"aws_region,omitempty"`
+	ProjectName  *string `json:"project_name,omitempty"`
+	ConfigName   *string `json:"config_name,omitempty"`
+	ReleaseID    *string `json:"release_id,omitempty"`
+
+	State     *string `json:"state,omitempty"`
+	ErrorType string  `json:"error_type"`
+	Cause     string  `json:"cause"`
+}
+
+// Validate validates the filter
+func (f *FailuresFilter) Validate() error {
+	if f.State != nil && !containsStr(FAILURE_STATES, *f.State) {
+		return fmt.Errorf("State is %v but must be in %v", *f.State, FAILURE_STATES)
+	}
+
+	if f.ConfigName != nil && f.ProjectName == nil {
+		return fmt.Errorf("Config requires a project")
+	}
 
-	return failures(awsc.SFNClient(nil, nil, nil), deployerARN)
+	return nil
 }
 
-func failures(sfnc aws.SFNAPI, arn *string) error {
-	execs, err := execution.ExecutionsAfter(sfnc, arn, to.Strp("FAILED"), time.Now().Add((-3*24)*time.Hour))
+// prefix is the execution name prefix of the filtered project and config
+func (f *FailuresFilter) prefix() string {
+	switch {
+	case f.ProjectName != nil && f.ConfigName != nil:
+		return (&bifrost.Release{ProjectName: f.ProjectName, ConfigName: f.ConfigName}).ExecutionPrefix()
+	case f.ProjectName != nil:
+		// Execution names replace the "/" in "org/repo" project names like ExecutionPrefix
+		return fmt.Sprintf("deploy-%v-", strings.Replace(*f.ProjectName, "/", "-", -1))
+	}
+	return ""
+}
 
+func (f *FailuresFilter) matches(failure *Failure) bool {
+	if f.ProjectName != nil && to.Strs(failure.ProjectName) != *f.ProjectName {
+		return false
+	}
+
+	if f.ConfigName != nil && to.Strs(failure.ConfigName) != *f.ConfigName {
+		return false
+	}
+
+	if f.State != nil && to.Strs(failure.State) != *f.State {
+		return false
+	}
+
+	return true
+}
+
+// Failures lists the recent failures grouped by their error type
+func Failures(step_fn *string, filter *FailuresFilter, jsonOutput bool) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
+	region, accountID := to.RegionAccount()
+
+	deployerARN := to.StepArn(region, accountID, step_fn)
+
+	awsc := &aws.ClientsStr{}
+
+	groups, err := failures(awsc.SFNClient(nil, nil, nil), deployerARN, filter)
 	if err != nil {
 		return err
 	}
 
-	for _, e := range execs {
-		sd, err := e.GetStateDetails(sfnc)
+	if jsonOutput {
+		j, err := to.PrettyJSON(groups)
 		if err != nil {
 			return err
 		}
+		fmt.Println(j)
+		return nil
+	}
+
+	fmt.Print(failuresStr(groups))
+	return nil
+}
 
-		release := FailedRelease{}
-		if err := json.Unmarshal([]byte(*sd.LastOutput), &release); err != nil {
-			j, _ := to.PrettyJSON(*sd.LastOutput)
-			fmt.Println(j)
-			continue
+// failures returns the failed executions after filter.Since grouped by error type
+func failures(sfnc aws.SFNAPI, arn *string, filter *FailuresFilter) (map[string][]*Failure, error) {
+	input := &sfn.ListExecutionsInput{
+		MaxResults:      to.Int64p(100),
+		StateMachineArn: arn,
+		StatusFilter:    to.Strp("FAILED"),
+	}
+
+	groups := map[string][]*Failure{}
+	prefix := filter.prefix()
+
+	for {
+		out, err := sfnc.ListExecutions(input)
+		if err != nil {
+			return nil, err
 		}
 
-		cause := ""
-		err_json := map[string]string{}
+		// Executions are listed most recent first
+		for _, e := range out.Executions {
+			if e.StartDate != nil && e.StartDate.Before(filter.Since) {
+				return groups, nil
+			}
 
-		if release.Error != nil {
-			err = json.Unmarshal([]byte(*release.Error.Cause), &err_json)
+			if e.Name == nil || !strings.HasPrefix(*e.Name, prefix) {
+				continue
+			}
 
+			failure, err := executionFailure(sfnc, e)
 			if err != nil {
-				fmt.Println(err)
-				cause = *release.Error.Cause
-			} else {
-				cause = err_json["errorMessage"]
+				return nil, err
+			}
+
+			if !filter.matches(failure) {
+				continue
 			}
+
+			groups[failure.ErrorType] = append(groups[failure.ErrorType], failure)
 		}
 
-		fmt.Println(fmt.Printf("%v -- %v -- %q", *sd.LastStateName, *e.Name, cause))
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
 	}
 
-	return nil
+	return groups, nil
+}
+
+func executionFailure(sfnc aws.SFNAPI, e *sfn.ExecutionListItem) (*Failure, error) {
+	exec := &execution.Execution{ExecutionArn: e.ExecutionArn, Name: e.Name}
+	sd, err := exec.GetStateDetails(sfnc)
+	if err != nil {
+		return nil, err
+	}
+
+	failure := &Failure{
+		Name:         e.Name,
+		ExecutionArn: e.ExecutionArn,
+		StartDate:    e.StartDate,
+		State:        sd.LastStateName,
+		ErrorType:    "Unknown",
+	}
+
+	if sd.LastOutput == nil {
+		return failure, nil
+	}
+
+	release := FailedRelease{}
+	if err := json.Unmarshal([]byte(*sd.LastOutput), &release); err != nil {
+		failure.Cause = *sd.LastOutput
+		return failure, nil
+	}
+
+	failure.AwsAccountID = release.AwsAccountID
+	failure.AwsRegion = release.AwsRegion
+	failure.ProjectName = release.ProjectName
+	failure.ConfigName = release.ConfigName
+	failure.ReleaseID = release.ReleaseID
+
+	if release.Error != nil {
+		failure.ErrorType, failure.Cause = errorTypeCause(release.Error)
+	}
+
+	return failure, nil
+}
+
+// errorTypeCause returns the error name and message from a caught error
+func errorTypeCause(releaseError *bifrost.ReleaseError) (string, string) {
+	errorType := "Unknown"
+	if !is.EmptyStr(releaseError.Error) {
+		errorType = *releaseError.Error
+	}
+
+	cause := to.Strs(releaseError.Cause)
+
+	errJSON := map[string]string{}
+	if err := json.Unmarshal([]byte(cause), &errJSON); err == nil {
+		cause = errJSON["errorMessage"]
+		if errJSON["errorType"] != "" {
+			errorType = errJSON["errorType"]
+		}
+	}
+
+	// Timeouts halt the release
+	if errorType == "HaltError" && strings.HasPrefix(cause, "Timeout") {
+		errorType = "TimeoutError"
+	}
+
+	return errorType, cause
+}
+
+func failuresStr(groups map[string][]*Failure) string {
+	if len(groups) == 0 {
+		return "No failures found\n"
+	}
+
+	errorTypes := []string{}
+	for errorType := range groups {
+		errorTypes = append(errorTypes, errorType)
+	}
+	sort.Strings(errorTypes)
+
+	lines := []string{}
+	for _, errorType := range errorTypes {
+		lines = append(lines, fmt.Sprintf("%v (%v):", errorType, len(groups[errorType])))
+		for _, f := range groups[errorType] {
+			lines = append(lines, fmt.Sprintf("  %v -- %v -- %q", to.Strs(f.State), to.Strs(f.Name), f.Cause))
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func containsStr(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
 }
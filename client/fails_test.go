@@ -0,0 +1,151 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/bifrost"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockFailedExecution(t *testing.T, awsc *mocks.MockClients, state string, errorType string, cause string) {
+	mockFailedProjectExecution(t, awsc, "project", state, errorType, cause)
+}
+
+func mockFailedProjectExecution(t *testing.T, awsc *mocks.MockClients, projectName string, state string, errorType string, cause string) {
+	output, err := to.PrettyJSON(FailedRelease{
+		ProjectName: to.Strp(projectName),
+		ConfigName:  to.Strp("config"),
+		ReleaseID:   to.Strp("release"),
+		Error: &bifrost.ReleaseError{
+			Error: to.Strp(errorType),
+			Cause: to.Strp(cause),
+		},
+	})
+	assert.NoError(t, err)
+
+	awsc.SFN.GetExecutionHistoryResp = &sfn.GetExecutionHistoryOutput{
+		Events: []*sfn.HistoryEvent{
+			&sfn.HistoryEvent{
+				Type:                     to.Strp("FailStateEntered"),
+				StateEnteredEventDetails: &sfn.StateEnteredEventDetails{Name: to.Strp(state)},
+			},
+			&sfn.HistoryEvent{
+				Type:                    to.Strp("TaskStateExited"),
+				StateExitedEventDetails: &sfn.StateExitedEventDetails{Name: to.Strp("ReleaseLockFailure"), Output: &output},
+			},
+		},
+	}
+}
+
+func Test_Failures(t *testing.T) {
+	awsc := mocks.MockAWS()
+	awsc.SFN.ListExecutionsResp = &sfn.ListExecutionsOutput{
+		Executions: []*sfn.ExecutionListItem{
+			&sfn.ExecutionListItem{
+				Name:      to.Strp("deploy-project-config-1"),
+				StartDate: to.Timep(time.Now().Add(-1 * time.Hour)),
+			},
+			&sfn.ExecutionListItem{
+				Name:      to.Strp("deploy-other-config-1"),
+				StartDate: to.Timep(time.Now().Add(-2 * time.Hour)),
+			},
+			&sfn.ExecutionListItem{
+				Name:      to.Strp("deploy-project-config-old"),
+				StartDate: to.Timep(time.Now().Add(-100 * time.Hour)),
+			},
+		},
+	}
+
+	mockFailedExecution(t, awsc, "FailureClean", "States.TaskFailed", `{"errorMessage": "Timeout: Halting Release", "errorType": "HaltError"}`)
+
+	groups, err := failures(awsc.SFN, to.Strp("arn"), &FailuresFilter{
+		Since:       time.Now().Add(-72 * time.Hour),
+		ProjectName: to.Strp("project"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(groups["TimeoutError"]))
+
+	failure := groups["TimeoutError"][0]
+	assert.Equal(t, "deploy-project-config-1", *failure.Name)
+	assert.Equal(t, "FailureClean", *failure.State)
+	assert.Equal(t, "Timeout: Halting Release", failure.Cause)
+
+	// Older failures are included with a longer since
+	groups, err = failures(awsc.SFN, to.Strp("arn"), &FailuresFilter{
+		Since:       time.Now().Add(-200 * time.Hour),
+		ProjectName: to.Strp("project"),
+		ConfigName:  to.Strp("config"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(groups["TimeoutError"]))
+
+	// State filter
+	groups, err = failures(awsc.SFN, to.Strp("arn"), &FailuresFilter{
+		Since: time.Now().Add(-72 * time.Hour),
+		State: to.Strp("FailureDirty"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(groups))
+	assert.Equal(t, "No failures found\n", failuresStr(groups))
+}
+
+func Test_Failures_GroupedByErrorType(t *testing.T) {
+	awsc := mocks.MockAWS()
+	awsc.SFN.ListExecutionsResp = &sfn.ListExecutionsOutput{
+		Executions: []*sfn.ExecutionListItem{
+			&sfn.ExecutionListItem{
+				Name:      to.Strp("deploy-project-config-1"),
+				StartDate: to.Timep(time.Now()),
+			},
+		},
+	}
+
+	mockFailedExecution(t, awsc, "FailureDirty", "BadReleaseError", "Release invalid")
+
+	groups, err := failures(awsc.SFN, to.Strp("arn"), &FailuresFilter{Since: time.Now().Add(-time.Hour)})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(groups["BadReleaseError"]))
+
+	str := failuresStr(groups)
+	assert.Contains(t, str, "BadReleaseError (1):")
+	assert.Contains(t, str, `FailureDirty -- deploy-project-config-1 -- "Release invalid"`)
+}
+
+func Test_Failures_SlashedProjectName(t *testing.T) {
+	awsc := mocks.MockAWS()
+	awsc.SFN.ListExecutionsResp = &sfn.ListExecutionsOutput{
+		Executions: []*sfn.ExecutionListItem{
+			&sfn.ExecutionListItem{
+				Name:      to.Strp("deploy-coinbase-deploy-test-config-1"),
+				StartDate: to.Timep(time.Now()),
+			},
+		},
+	}
+
+	mockFailedProjectExecution(t, awsc, "coinbase/deploy-test", "FailureClean", "BadReleaseError", "Release invalid")
+
+	groups, err := failures(awsc.SFN, to.Strp("arn"), &FailuresFilter{
+		Since:       time.Now().Add(-time.Hour),
+		ProjectName: to.Strp("coinbase/deploy-test"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(groups["BadReleaseError"]))
+
+	groups, err = failures(awsc.SFN, to.Strp("arn"), &FailuresFilter{
+		Since:       time.Now().Add(-time.Hour),
+		ProjectName: to.Strp("coinbase/deploy-test"),
+		ConfigName:  to.Strp("config"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(groups["BadReleaseError"]))
+}
+
+func Test_FailuresFilter_Validate(t *testing.T) {
+	assert.NoError(t, (&FailuresFilter{State: to.Strp("FailureDirty")}).Validate())
+	assert.Error(t, (&FailuresFilter{State: to.Strp("Failed")}).Validate())
+	assert.Error(t, (&FailuresFilter{ConfigName: to.Strp("config")}).Validate())
+}
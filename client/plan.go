@@ -0,0 +1,205 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/deployer/models"
+	"github.com/coinbase/step/utils/is"
+	"github.com/coinbase/step/utils/to"
+)
+
+// Plan prints what deploying the release would do without changing anything
+func Plan(releaseFile *string) error {
+	region, accountID := to.RegionAccount()
+	release, err := releaseFromFile(releaseFile, region, accountID)
+	if err != nil {
+		return err
+	}
+
+	return plan(&aws.ClientsStr{}, release, region, accountID, planRole())
+}
+
+// planRole is the role assumed in the releases account, by default the callers own credentials are used
+func planRole() *string {
+	role := os.Getenv("ODIN_PLAN_ROLE")
+	if role == "" {
+		return nil
+	}
+	return &role
+}
+
+func plan(awsc aws.Clients, localRelease *models.Release, region *string, accountID *string, role *string) error {
+	// The release and userdata are never uploaded, so serve them from memory
+	s3c := newReadOnlyS3(awsc.S3Client(nil, nil, nil))
+	release, err := s3c.addRelease(localRelease)
+	if err != nil {
+		return err
+	}
+
+	// The same as the Validate handler
+	release.ReleaseSHA256 = to.SHA256Struct(release)
+	release.WipeControlledValues()
+	release.Release.SetDefaults(region, accountID, "coinbase-odin-")
+	release.SetDefaults()
+
+	if err := release.Validate(s3c, awsc.KMSClient(nil, nil, nil)); err != nil {
+		return err
+	}
+
+	resources, err := release.FetchResources(
+		awsc.ASGClient(release.AwsRegion, release.AwsAccountID, role),
+		awsc.EC2Client(release.AwsRegion, release.AwsAccountID, role),
+		awsc.ELBClient(release.AwsRegion, release.AwsAccountID, role),
+		awsc.ALBClient(release.AwsRegion, release.AwsAccountID, role),
+		awsc.IAMClient(release.AwsRegion, release.AwsAccountID, role),
+		awsc.SNSClient(release.AwsRegion, release.AwsAccountID, role),
+		awsc.SQSClient(release.AwsRegion, release.AwsAccountID, role),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := release.ValidateResources(resources); err != nil {
+		return err
+	}
+
+	previousRelease, err := release.PreviousRelease(s3c, resources)
+	if err != nil {
+		return err
+	}
+
+	release.UpdateWithResources(resources)
+	release.SetDefaults() // Recalculate the strategies with the previous capacities
+
+	fmt.Print(planStr(release, previousRelease))
+	return nil
+}
+
+func planStr(release *models.Release, previousRelease *models.Release) string {
+	lines := []string{
+		fmt.Sprintf("Plan for %v/%v release %v", *release.ProjectName, *release.ConfigName, *release.ReleaseID),
+	}
+
+	names := []string{}
+	for name := range release.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		service := release.Services[name]
+		resources := service.Resources
+		capacity := service.Capacity()
+
+		lines = append(lines,
+			fmt.Sprintf("%v:", name),
+			fmt.Sprintf("  ami:             %v", to.Strs(resources.Image)),
+			fmt.Sprintf("  subnets:         %v", strings.Join(to.StrSlice(resources.Subnets), ", ")),
+			fmt.Sprintf("  security_groups: %v", strings.Join(to.StrSlice(resources.SecurityGroups), ", ")),
+			fmt.Sprintf("  elbs:            %v", strings.Join(to.StrSlice(resources.ELBs), ", ")),
+			fmt.Sprintf("  target_groups:   %v", strings.Join(to.StrSlice(resources.TargetGroups), ", ")),
+			fmt.Sprintf("  previous_asg:    %v", to.Strs(resources.PrevASG)),
+			fmt.Sprintf("  capacity:        min %v, desired %v, max %v (launching %v, healthy at %v)",
+				capacity.MinSize, capacity.DesiredCapacity, capacity.MaxSize, capacity.TargetLaunched, capacity.TargetHealthy),
+		)
+	}
+
+	if previousRelease == nil {
+		lines = append(lines, "No release is currently deployed")
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	lines = append(lines, fmt.Sprintf("Diff with deployed release %v:", to.Strs(previousRelease.ReleaseID)))
+
+	diff := release.Diff(previousRelease)
+	if diff == nil {
+		lines = append(lines, "  No differences")
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	for _, line := range strings.Split(diff.Error(), "\n") {
+		if line != "" {
+			lines = append(lines, fmt.Sprintf("  %v", line))
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+//////////
+// Read Only S3
+//////////
+
+// readOnlyS3 serves the local release from memory and refuses to write anything
+type readOnlyS3 struct {
+	aws.S3API
+	objects map[string]string
+	kmsKey  *string
+}
+
+func newReadOnlyS3(s3c aws.S3API) *readOnlyS3 {
+	return &readOnlyS3{S3API: s3c, objects: map[string]string{}}
+}
+
+// addRelease stores the release and userdata as deploy would upload them
+// and returns the release as the deployer would receive it
+func (s *readOnlyS3) addRelease(release *models.Release) (*models.Release, error) {
+	raw, err := json.Marshal(release)
+	if err != nil {
+		return nil, err
+	}
+
+	s.objects[*release.ReleasePath()] = string(raw)
+	s.kmsKey = kMSKey(release)
+	s.objects[*release.UserDataPath()] = to.Strs(release.UserData())
+
+	for name, service := range release.Services {
+		if service == nil || !service.OverridesUserData() {
+			continue
+		}
+		s.objects[*release.ServiceUserDataPath(name)] = to.Strs(service.RawUserData())
+	}
+
+	var received models.Release
+	if err := json.Unmarshal(raw, &received); err != nil {
+		return nil, err
+	}
+
+	return &received, nil
+}
+
+func (s *readOnlyS3) GetObject(in *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	if !is.EmptyStr(in.Key) {
+		if body, ok := s.objects[*in.Key]; ok {
+			return &s3.GetObjectOutput{Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+		}
+	}
+
+	return s.S3API.GetObject(in)
+}
+
+// HeadObject reports the local objects encrypted with the key deploy would use
+func (s *readOnlyS3) HeadObject(in *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	if !is.EmptyStr(in.Key) {
+		if _, ok := s.objects[*in.Key]; ok {
+			return &s3.HeadObjectOutput{ServerSideEncryption: to.Strp("aws:kms"), SSEKMSKeyId: s.kmsKey}, nil
+		}
+	}
+
+	return s.S3API.HeadObject(in)
+}
+
+func (s *readOnlyS3) PutObject(in *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	return nil, fmt.Errorf("Plan is read only, cannot write s3://%v/%v", to.Strs(in.Bucket), to.Strs(in.Key))
+}
+
+func (s *readOnlyS3) DeleteObject(in *s3.DeleteObjectInput) (*s3.DeleteObjectOutput, error) {
+	return nil, fmt.Errorf("Plan is read only, cannot delete s3://%v/%v", to.Strs(in.Bucket), to.Strs(in.Key))
+}
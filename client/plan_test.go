@@ -0,0 +1,72 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/coinbase/odin/deployer/models"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Plan(t *testing.T) {
+	r := models.MockRelease(t)
+	awsc := models.MockAwsClients(r)
+
+	// The currently deployed release
+	prev := models.MockRelease(t)
+	prev.ReleaseID = to.Strp("old-release")
+	prev.Services["web"].InstanceType = to.Strp("t2.large")
+	models.AddReleaseS3Objects(awsc, prev)
+
+	objects := len(awsc.S3.GetObjectResp)
+
+	err := plan(awsc, r, to.Strp("region"), to.Strp("account"), nil)
+	assert.NoError(t, err)
+
+	// Nothing was written
+	assert.Equal(t, objects, len(awsc.S3.GetObjectResp))
+	assert.Nil(t, awsc.ASG.UpdateAutoScalingGroupLastInput)
+	assert.Equal(t, 0, len(awsc.EC2.LaunchTemplates))
+}
+
+func Test_Plan_SigningKeys(t *testing.T) {
+	r := models.MockRelease(t)
+	awsc := models.MockAwsClients(r)
+	addSigningKey(t, awsc, r, awsc.KMS.PublicKeyDER())
+
+	prev := models.MockRelease(t)
+	prev.ReleaseID = to.Strp("old-release")
+	models.AddReleaseS3Objects(awsc, prev)
+
+	// A dry run does not need a signing key
+	assert.NoError(t, plan(awsc, r, to.Strp("region"), to.Strp("account"), nil))
+}
+
+func Test_PlanStr(t *testing.T) {
+	r := models.MockRelease(t)
+	models.MockPrepareRelease(r)
+
+	str := planStr(r, nil)
+	assert.Contains(t, str, "web:")
+	assert.Contains(t, str, "capacity:        min 1, desired 1, max 1")
+	assert.Contains(t, str, "No release is currently deployed")
+
+	prev := models.MockRelease(t)
+	models.MockPrepareRelease(prev)
+	assert.Contains(t, planStr(r, prev), "No differences")
+
+	prev.Services["web"].InstanceType = to.Strp("t2.large")
+	assert.Contains(t, planStr(r, prev), "InstanceType different")
+}
+
+func Test_ReadOnlyS3(t *testing.T) {
+	r := models.MockRelease(t)
+	awsc := models.MockAwsClients(r)
+
+	s3c := newReadOnlyS3(awsc.S3)
+	_, err := s3c.addRelease(r)
+	assert.NoError(t, err)
+
+	assert.NoError(t, r.DownloadUserData(s3c))
+	assert.Error(t, r.Halt(s3c, to.Strp("halt")))
+}
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/deployer/models"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/bifrost"
+	"github.com/coinbase/step/utils/to"
+)
+
+// Rollback redeploys a previous release, by default the last successful one
+// The release is changed, so it is signed again with signKey if given
+func Rollback(step_fn *string, projectName *string, configName *string, releaseID *string, signKey *string) error {
+	region, accountID := to.RegionAccount()
+	deployerARN := to.StepArn(region, accountID, step_fn)
+
+	return rollback(&aws.ClientsStr{}, projectName, configName, releaseID, signKey, region, accountID, deployerARN)
+}
+
+func rollback(awsc aws.Clients, projectName *string, configName *string, releaseID *string, signKey *string, region *string, accountID *string, deployerARN *string) error {
+	// Scaffold the release to find its paths
+	release := &models.Release{
+		Release: bifrost.Release{
+			ProjectName: projectName,
+			ConfigName:  configName,
+		},
+	}
+
+	release.Release.SetDefaults(region, accountID, "coinbase-odin-")
+
+	if releaseID == nil {
+		id, err := lastSuccessfulReleaseID(awsc.SFNClient(nil, nil, nil), deployerARN, release.ExecutionPrefix())
+		if err != nil {
+			return err
+		}
+		releaseID = id
+	}
+
+	release.ReleaseID = releaseID
+
+	previous, err := previousRelease(awsc.S3Client(nil, nil, nil), release)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Rolling back %v/%v to release %v\n", *projectName, *configName, *releaseID)
+
+	// A fresh release_id and created_at so it is deployed like any other release
+	prepareRelease(previous, region, accountID)
+
+	if previous.Metadata == nil {
+		previous.Metadata = map[string]string{}
+	}
+	previous.Metadata["rollback_release_id"] = *releaseID
+
+	// The previous signature does not cover the changes
+	previous.Signature = nil
+
+	if err := validateClientAttributes(previous); err != nil {
+		return err
+	}
+
+	if err := signRelease(awsc, previous, signKey); err != nil {
+		return err
+	}
+
+	return deploy(awsc, previous, deployerARN)
+}
+
+// previousRelease downloads the release and its userdata from where they were uploaded
+func previousRelease(s3c aws.S3API, scaffold *models.Release) (*models.Release, error) {
+	var release models.Release
+	if err := s3.GetStruct(s3c, scaffold.Bucket, scaffold.ReleasePath(), &release); err != nil {
+		return nil, fmt.Errorf("Cannot find release s3://%v/%v: %v", *scaffold.Bucket, *scaffold.ReleasePath(), err.Error())
+	}
+
+	// The paths are for the previous release, the bucket may be the default
+	release.ReleaseID = scaffold.ReleaseID
+	release.Bucket = scaffold.Bucket
+	if release.AwsAccountID == nil {
+		release.AwsAccountID = scaffold.AwsAccountID
+	}
+
+	if err := release.DownloadUserData(s3c); err != nil {
+		return nil, err
+	}
+	release.UserDataSHA256 = to.Strp(to.SHA256Str(release.UserData()))
+
+	for name, service := range release.Services {
+		if service == nil || !service.OverridesUserData() {
+			continue
+		}
+
+		userdata, err := s3.GetStr(s3c, release.Bucket, release.ServiceUserDataPath(name))
+		if err != nil {
+			return nil, err
+		}
+
+		service.SetUserData(userdata)
+		service.UserDataSHA256 = to.Strp(to.SHA256Str(userdata))
+	}
+
+	return &release, nil
+}
+
+// lastSuccessfulReleaseID returns the release ID of the most recent successful execution
+func lastSuccessfulReleaseID(sfnc aws.SFNAPI, deployerARN *string, prefix string) (*string, error) {
+	id, err := lastReleaseID(sfnc, deployerARN, prefix, to.Strp("SUCCEEDED"))
+	if err != nil {
+		return nil, err
+	}
+
+	if id == nil {
+		return nil, fmt.Errorf("Cannot find a successful execution with prefix %q", prefix)
+	}
+
+	return id, nil
+}
+
+// lastReleaseID returns the release ID of the most recent execution with the status, any status if nil
+// It returns nil if there is no execution with the prefix
+func lastReleaseID(sfnc aws.SFNAPI, deployerARN *string, prefix string, status *string) (*string, error) {
+	input := &sfn.ListExecutionsInput{
+		MaxResults:      to.Int64p(100),
+		StatusFilter:    status,
+		StateMachineArn: deployerARN,
+	}
+
+	for {
+		out, err := sfnc.ListExecutions(input)
+		if err != nil {
+			return nil, err
+		}
+
+		// Executions are listed most recent first
+		for _, exec := range out.Executions {
+			if exec.Name == nil || !strings.HasPrefix(*exec.Name, prefix) {
+				continue
+			}
+
+			release, err := executionRelease(sfnc, exec.ExecutionArn)
+			if err != nil {
+				return nil, err
+			}
+
+			return release.ReleaseID, nil
+		}
+
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+
+	return nil, nil
+}
+
+// executionRelease returns the release an execution was started with
+func executionRelease(sfnc aws.SFNAPI, executionArn *string) (*bifrost.Release, error) {
+	out, err := sfnc.DescribeExecution(&sfn.DescribeExecutionInput{ExecutionArn: executionArn})
+	if err != nil {
+		return nil, err
+	}
+
+	if out.Input == nil {
+		return nil, fmt.Errorf("Execution %v has no input", to.Strs(executionArn))
+	}
+
+	var release bifrost.Release
+	if err := json.Unmarshal([]byte(*out.Input), &release); err != nil {
+		return nil, err
+	}
+
+	if release.ReleaseID == nil {
+		return nil, fmt.Errorf("Execution %v has no release_id", to.Strs(executionArn))
+	}
+
+	return &release, nil
+}
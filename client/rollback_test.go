@@ -0,0 +1,99 @@
+package client
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/odin/deployer/models"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Rollback(t *testing.T) {
+	awsc := mocks.MockAWS()
+	r := minimalRelease(t)
+	r.Release.SetDefaults(to.Strp("region"), to.Strp("accountid"), "coinbase-odin-")
+	r.SetUserData(to.Strp("#cloud_config"))
+
+	// Upload the release as deploy would
+	assert.NoError(t, deploy(awsc, r, to.Strp("deployerARN")))
+
+	awsc.SFN.ListExecutionsResp = &sfn.ListExecutionsOutput{
+		Executions: []*sfn.ExecutionListItem{
+			&sfn.ExecutionListItem{
+				Name:         to.Strp("deploy-other-config-1"),
+				ExecutionArn: to.Strp("other"),
+				StartDate:    to.Timep(time.Now()),
+			},
+			&sfn.ExecutionListItem{
+				Name:         r.ExecutionName(),
+				ExecutionArn: to.Strp("arn"),
+				StartDate:    to.Timep(time.Now()),
+			},
+		},
+	}
+
+	input, _ := to.PrettyJSON(r)
+	awsc.SFN.DescribeExecutionResp = &sfn.DescribeExecutionOutput{
+		Input:  &input,
+		Status: to.Strp("SUCCEEDED"),
+	}
+
+	id, err := lastSuccessfulReleaseID(awsc.SFN, to.Strp("deployerARN"), r.ExecutionPrefix())
+	assert.NoError(t, err)
+	assert.Equal(t, *r.ReleaseID, *id)
+
+	// Default to the last successful release
+	err = rollback(awsc, r.ProjectName, r.ConfigName, nil, nil, to.Strp("region"), to.Strp("accountid"), to.Strp("deployerARN"))
+	assert.NoError(t, err)
+
+	// Unknown release
+	err = rollback(awsc, r.ProjectName, r.ConfigName, to.Strp("unknown"), nil, to.Strp("region"), to.Strp("accountid"), to.Strp("deployerARN"))
+	assert.Error(t, err)
+}
+
+func Test_Rollback_Signed(t *testing.T) {
+	awsc := mocks.MockAWS()
+	r := minimalRelease(t)
+	r.Release.SetDefaults(to.Strp("region"), to.Strp("accountid"), "coinbase-odin-")
+	r.SetUserData(to.Strp("#cloud_config"))
+	addSigningKey(t, awsc, r, awsc.KMS.PublicKeyDER())
+
+	keyARN := to.Strp("arn:aws:kms:us-west-2:000000000000:key/1234")
+	assert.NoError(t, signRelease(awsc, r, keyARN))
+	assert.NoError(t, deploy(awsc, r, to.Strp("deployerARN")))
+	previousPath := *r.ReleasePath()
+
+	err := rollback(awsc, r.ProjectName, r.ConfigName, r.ReleaseID, keyARN, to.Strp("region"), to.Strp("accountid"), to.Strp("deployerARN"))
+	assert.NoError(t, err)
+
+	// The uploaded rollback release is signed again and passes the deployers check
+	rollbacks := 0
+	for key := range awsc.S3.GetObjectResp {
+		if !strings.HasSuffix(key, "/release") || key == previousPath {
+			continue
+		}
+
+		var uploaded models.Release
+		assert.NoError(t, s3.GetStruct(awsc.S3, r.Bucket, &key, &uploaded))
+		assert.Equal(t, *r.ReleaseID, uploaded.Metadata["rollback_release_id"])
+		assert.NoError(t, uploaded.ValidateSignature(awsc.S3, &uploaded))
+
+		// The previous signature does not cover the rollback
+		uploaded.Signature = r.Signature
+		assert.Error(t, uploaded.ValidateSignature(awsc.S3, &uploaded))
+		rollbacks++
+	}
+	assert.Equal(t, 1, rollbacks)
+}
+
+func Test_Rollback_NoSuccessfulRelease(t *testing.T) {
+	awsc := mocks.MockAWS()
+
+	_, err := lastSuccessfulReleaseID(awsc.SFN, to.Strp("deployerARN"), "deploy-project-config-")
+	assert.Error(t, err)
+}
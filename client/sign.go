@@ -0,0 +1,113 @@
+package client
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/deployer/models"
+	"github.com/coinbase/step/utils/is"
+	"github.com/coinbase/step/utils/to"
+)
+
+// signRelease signs the release with a KMS asymmetric key ARN or alias, else an ed25519 PEM private key file
+// It must be called after the release is last changed, the signature covers all of it
+func signRelease(awsc aws.Clients, release *models.Release, signKey *string) error {
+	if is.EmptyStr(signKey) {
+		return nil
+	}
+
+	release.Signature = nil
+	message := release.SignedMessage()
+
+	var signature *models.ReleaseSignature
+	var err error
+	if isKMSKey(*signKey) {
+		signature, err = kmsSign(awsc.KMSClient(kmsKeyRegion(*signKey), nil, nil), signKey, message)
+	} else {
+		signature, err = ed25519Sign(*signKey, message)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Error signing release with %v: %v", *signKey, err.Error())
+	}
+
+	release.Signature = signature
+	return nil
+}
+
+func isKMSKey(signKey string) bool {
+	return strings.HasPrefix(signKey, "arn:aws:kms:") || strings.HasPrefix(signKey, "alias/")
+}
+
+// kmsKeyRegion returns the region of a KMS key ARN, nil for an alias in the default region
+func kmsKeyRegion(signKey string) *string {
+	parts := strings.Split(signKey, ":")
+	if len(parts) < 4 || parts[0] != "arn" || parts[3] == "" {
+		return nil
+	}
+	return to.Strp(parts[3])
+}
+
+// kmsSign signs with the first signing algorithm of the KMS key
+func kmsSign(kmsc aws.KMSAPI, keyID *string, message []byte) (*models.ReleaseSignature, error) {
+	key, err := kmsc.GetPublicKey(&kms.GetPublicKeyInput{KeyId: keyID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key.SigningAlgorithms) == 0 {
+		return nil, fmt.Errorf("KMS key is not a signing key")
+	}
+
+	out, err := kmsc.Sign(&kms.SignInput{
+		KeyId:            keyID,
+		Message:          message,
+		MessageType:      to.Strp("RAW"),
+		SigningAlgorithm: key.SigningAlgorithms[0],
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ReleaseSignature{
+		Algorithm: out.SigningAlgorithm,
+		Signature: to.Strp(base64.StdEncoding.EncodeToString(out.Signature)),
+		KeyID:     keyID,
+	}, nil
+}
+
+// ed25519Sign signs with a PKCS8 PEM private key, e.g. from "openssl genpkey -algorithm ed25519"
+func ed25519Sign(keyFile string, message []byte) (*models.ReleaseSignature, error) {
+	raw, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("key file must be PEM encoded")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("key file must be an ed25519 private key")
+	}
+
+	return &models.ReleaseSignature{
+		Algorithm: to.Strp("ED25519"),
+		Signature: to.Strp(base64.StdEncoding.EncodeToString(ed25519.Sign(key, message))),
+	}, nil
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/odin/deployer/models"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func addSigningKey(t *testing.T, awsc *mocks.MockClients, r *models.Release, key string) {
+	raw, err := json.Marshal(&models.SigningKeys{SigningKeys: []*models.ProjectSigningKeys{
+		&models.ProjectSigningKeys{ProjectName: r.ProjectName, PublicKeys: map[string]string{"key": key}},
+	}})
+	assert.NoError(t, err)
+	awsc.S3.AddGetObject(*r.SigningKeysPath(), string(raw), nil)
+}
+
+func Test_SignRelease_KMS(t *testing.T) {
+	awsc := mocks.MockAWS()
+	r := minimalRelease(t)
+	r.Release.SetDefaults(to.Strp("region"), to.Strp("accountid"), "coinbase-odin-")
+	addSigningKey(t, awsc, r, awsc.KMS.PublicKeyDER())
+
+	// Unsigned
+	assert.Error(t, r.ValidateSignature(awsc.S3, r))
+
+	keyARN := "arn:aws:kms:us-west-2:000000000000:key/1234"
+	assert.NoError(t, signRelease(awsc, r, to.Strp(keyARN)))
+	assert.Equal(t, "ECDSA_SHA_256", *r.Signature.Algorithm)
+	assert.Equal(t, keyARN, *r.Signature.KeyID)
+	assert.Equal(t, "RAW", *awsc.KMS.SignInputs[0].MessageType)
+
+	assert.NoError(t, r.ValidateSignature(awsc.S3, r))
+
+	// Changed after it was signed
+	r.ConfigName = to.Strp("other")
+	addSigningKey(t, awsc, r, awsc.KMS.PublicKeyDER())
+	assert.Error(t, r.ValidateSignature(awsc.S3, r))
+
+	// Resigning replaces the signature
+	assert.NoError(t, signRelease(awsc, r, to.Strp("alias/odin")))
+	assert.NoError(t, r.ValidateSignature(awsc.S3, r))
+
+	assert.Equal(t, "us-west-2", *kmsKeyRegion(keyARN))
+	assert.Nil(t, kmsKeyRegion("alias/odin"))
+}
+
+func Test_SignRelease_ED25519(t *testing.T) {
+	awsc := mocks.MockAWS()
+	r := minimalRelease(t)
+	r.Release.SetDefaults(to.Strp("region"), to.Strp("accountid"), "coinbase-odin-")
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	assert.NoError(t, err)
+
+	keyFile, err := ioutil.TempFile("", "odin-sign-key")
+	assert.NoError(t, err)
+	defer os.Remove(keyFile.Name())
+
+	assert.NoError(t, pem.Encode(keyFile, &pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	assert.NoError(t, keyFile.Close())
+
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	assert.NoError(t, err)
+	addSigningKey(t, awsc, r, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})))
+
+	assert.NoError(t, signRelease(awsc, r, to.Strp(keyFile.Name())))
+	assert.Equal(t, "ED25519", *r.Signature.Algorithm)
+	assert.NoError(t, r.ValidateSignature(awsc.S3, r))
+
+	// Not a key
+	assert.Error(t, signRelease(awsc, r, to.Strp("/does/not/exist")))
+
+	// No key leaves the release unsigned
+	r.Signature = nil
+	assert.NoError(t, signRelease(awsc, r, nil))
+	assert.Nil(t, r.Signature)
+}
@@ -0,0 +1,356 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/aws/alb"
+	"github.com/coinbase/odin/aws/asg"
+	"github.com/coinbase/odin/aws/elb"
+	"github.com/coinbase/odin/deployer/models"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/bifrost"
+	"github.com/coinbase/step/utils/to"
+)
+
+// StatusReport is what is currently deployed for a project config
+type StatusReport struct {
+	ProjectName *string `json:"project_name,omitempty"`
+	ConfigName  *string `json:"config_name,omitempty"`
+
+	Services   []*ServiceStatus   `json:"services"`
+	Executions []*ExecutionStatus `json:"executions"`
+
+	Lock     *LockStatus     `json:"lock,omitempty"`
+	UserLock *UserLockStatus `json:"user_lock,omitempty"`
+}
+
+// ServiceStatus is a live ASG for a service
+type ServiceStatus struct {
+	ServiceName          *string   `json:"service_name,omitempty"`
+	AutoScalingGroupName *string   `json:"autoscaling_group_name,omitempty"`
+	ReleaseID            *string   `json:"release_id,omitempty"`
+	Image                *string   `json:"ami,omitempty"`
+	InstanceTypes        []*string `json:"instance_types,omitempty"`
+
+	MinSize         int64 `json:"min_size"`
+	DesiredCapacity int64 `json:"desired_capacity"`
+	MaxSize         int64 `json:"max_size"`
+
+	Healthy     int `json:"healthy"`
+	Unhealthy   int `json:"unhealthy"`
+	Terminating int `json:"terminating"`
+
+	RetainUntil *time.Time `json:"retain_until,omitempty"` // Set if kept for rollback
+
+	Error *string `json:"error,omitempty"` // Why the services details could not be found
+}
+
+// ExecutionStatus is an in-flight deploy
+type ExecutionStatus struct {
+	Name         *string    `json:"name,omitempty"`
+	ExecutionArn *string    `json:"execution_arn,omitempty"`
+	ReleaseID    *string    `json:"release_id,omitempty"`
+	StartDate    *time.Time `json:"start_date,omitempty"`
+
+	uuid      *string
+	accountID *string
+}
+
+// LockStatus is the holder of the project config lock
+type LockStatus struct {
+	UUID      string  `json:"uuid"`
+	Execution *string `json:"execution,omitempty"`
+	ReleaseID *string `json:"release_id,omitempty"`
+}
+
+// UserLockStatus is a lock set by a user to stop deploys
+type UserLockStatus struct {
+	User   string `json:"user"`
+	Reason string `json:"reason"`
+}
+
+// Status prints the live ASGs, in-flight executions and locks for a project config
+func Status(step_fn *string, projectName *string, configName *string, jsonOutput bool) error {
+	region, accountID := to.RegionAccount()
+	deployerARN := to.StepArn(region, accountID, step_fn)
+
+	report, err := status(&aws.ClientsStr{}, projectName, configName, region, accountID, deployerARN)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		j, err := to.PrettyJSON(report)
+		if err != nil {
+			return err
+		}
+		fmt.Println(j)
+		return nil
+	}
+
+	fmt.Print(statusStr(report))
+	return nil
+}
+
+func status(awsc aws.Clients, projectName *string, configName *string, region *string, accountID *string, deployerARN *string) (*StatusReport, error) {
+	// Scaffold the release to find its paths
+	release := &models.Release{
+		Release: bifrost.Release{
+			ProjectName: projectName,
+			ConfigName:  configName,
+		},
+	}
+
+	release.Release.SetDefaults(region, accountID, "coinbase-odin-")
+
+	report := &StatusReport{
+		ProjectName: projectName,
+		ConfigName:  configName,
+	}
+
+	services, err := serviceStatuses(awsc, projectName, configName)
+	if err != nil {
+		return nil, err
+	}
+	report.Services = services
+
+	executions, err := runningExecutions(awsc.SFNClient(nil, nil, nil), deployerARN, release.ExecutionPrefix())
+	if err != nil {
+		return nil, err
+	}
+	report.Executions = executions
+
+	s3c := awsc.S3Client(nil, nil, nil)
+
+	lock, err := lockStatus(s3c, release, executions)
+	if err != nil {
+		return nil, err
+	}
+	report.Lock = lock
+
+	userLock, err := userLockStatus(s3c, release)
+	if err != nil {
+		return nil, err
+	}
+	report.UserLock = userLock
+
+	return report, nil
+}
+
+func serviceStatuses(awsc aws.Clients, projectName *string, configName *string) ([]*ServiceStatus, error) {
+	asgc := awsc.ASGClient(nil, nil, nil)
+	ec2c := awsc.EC2Client(nil, nil, nil)
+	elbc := awsc.ELBClient(nil, nil, nil)
+	albc := awsc.ALBClient(nil, nil, nil)
+
+	groups, err := asg.ForProjectConfig(asgc, projectName, configName)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []*ServiceStatus{}
+	for _, group := range groups {
+		// A missing launch template or configuration should not hide the other services
+		var launchErr *string
+		image, instanceTypes, err := group.LaunchDetails(asgc, ec2c)
+		if err != nil {
+			launchErr = to.Strp(fmt.Sprintf("Error getting launch details: %v", err.Error()))
+		}
+
+		all := group.Instances()
+
+		for _, name := range group.LoadBalancerNames {
+			elbInstances, err := elb.GetInstances(elbc, name, all.InstanceIDs())
+			if err != nil {
+				return nil, err
+			}
+			all = all.MergeInstances(elbInstances)
+		}
+
+		for _, arn := range group.TargetGroupARNs {
+			tgInstances, err := alb.GetInstances(albc, arn, all.InstanceIDs())
+			if err != nil {
+				return nil, err
+			}
+			all = all.MergeInstances(tgInstances)
+		}
+
+		healthy, unhealthy, terming := all.HealthyUnhealthyTerming()
+
+		statuses = append(statuses, &ServiceStatus{
+			ServiceName:          group.ServiceName(),
+			AutoScalingGroupName: group.ServiceID(),
+			ReleaseID:            group.ReleaseID(),
+			Image:                image,
+			InstanceTypes:        instanceTypes,
+			MinSize:              *group.MinSize,
+			DesiredCapacity:      *group.DesiredCapacity,
+			MaxSize:              *group.MaxSize,
+			Healthy:              healthy,
+			Unhealthy:            unhealthy,
+			Terminating:          terming,
+			RetainUntil:          group.RetainUntil(),
+			Error:                launchErr,
+		})
+	}
+
+	// During a deploy a service has both the previous and new ASG
+	sort.Slice(statuses, func(i, j int) bool {
+		si, sj := to.Strs(statuses[i].ServiceName), to.Strs(statuses[j].ServiceName)
+		if si != sj {
+			return si < sj
+		}
+		return to.Strs(statuses[i].AutoScalingGroupName) < to.Strs(statuses[j].AutoScalingGroupName)
+	})
+
+	return statuses, nil
+}
+
+// runningExecutions returns the running executions with the prefix
+func runningExecutions(sfnc aws.SFNAPI, deployerARN *string, prefix string) ([]*ExecutionStatus, error) {
+	input := &sfn.ListExecutionsInput{
+		MaxResults:      to.Int64p(100),
+		StatusFilter:    to.Strp("RUNNING"),
+		StateMachineArn: deployerARN,
+	}
+
+	executions := []*ExecutionStatus{}
+	for {
+		out, err := sfnc.ListExecutions(input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, exec := range out.Executions {
+			if exec.Name == nil || !strings.HasPrefix(*exec.Name, prefix) {
+				continue
+			}
+
+			release, err := executionRelease(sfnc, exec.ExecutionArn)
+			if err != nil {
+				return nil, err
+			}
+
+			executions = append(executions, &ExecutionStatus{
+				Name:         exec.Name,
+				ExecutionArn: exec.ExecutionArn,
+				ReleaseID:    release.ReleaseID,
+				StartDate:    exec.StartDate,
+				uuid:         release.UUID,
+				accountID:    release.AwsAccountID,
+			})
+		}
+
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+
+	return executions, nil
+}
+
+// lockStatus returns the holder of the root lock, matched to its execution
+func lockStatus(s3c aws.S3API, release *models.Release, executions []*ExecutionStatus) (*LockStatus, error) {
+	var lock s3.Lock
+	if err := s3.GetStruct(s3c, release.Bucket, release.RootLockPath(), &lock); err != nil {
+		switch err.(type) {
+		case *s3.NotFoundError:
+			return nil, nil
+		default:
+			return nil, err
+		}
+	}
+
+	if lock.UUID == "" {
+		return nil, nil
+	}
+
+	status := &LockStatus{UUID: lock.UUID}
+	for _, exec := range executions {
+		if exec.uuid != nil && *exec.uuid == lock.UUID {
+			status.Execution = exec.Name
+			status.ReleaseID = exec.ReleaseID
+		}
+	}
+
+	return status, nil
+}
+
+func userLockStatus(s3c aws.S3API, release *models.Release) (*UserLockStatus, error) {
+	var lock s3.UserLock
+	if err := s3.GetStruct(s3c, release.Bucket, release.UserLockPath(), &lock); err != nil {
+		switch err.(type) {
+		case *s3.NotFoundError:
+			return nil, nil
+		default:
+			return nil, err
+		}
+	}
+
+	if lock == (s3.UserLock{}) {
+		return nil, nil
+	}
+
+	return &UserLockStatus{User: lock.User, Reason: lock.LockReason}, nil
+}
+
+func statusStr(report *StatusReport) string {
+	lines := []string{
+		fmt.Sprintf("Status of %v/%v", to.Strs(report.ProjectName), to.Strs(report.ConfigName)),
+	}
+
+	if len(report.Services) == 0 {
+		lines = append(lines, "No autoscaling groups found")
+	}
+
+	for _, s := range report.Services {
+		lines = append(lines,
+			fmt.Sprintf("%v (%v):", to.Strs(s.ServiceName), to.Strs(s.AutoScalingGroupName)),
+			fmt.Sprintf("  release_id:     %v", to.Strs(s.ReleaseID)),
+			fmt.Sprintf("  ami:            %v", to.Strs(s.Image)),
+			fmt.Sprintf("  instance_types: %v", strings.Join(to.StrSlice(s.InstanceTypes), ", ")),
+			fmt.Sprintf("  capacity:       min %v, desired %v, max %v", s.MinSize, s.DesiredCapacity, s.MaxSize),
+			fmt.Sprintf("  instances:      %v healthy, %v unhealthy, %v terminating", s.Healthy, s.Unhealthy, s.Terminating),
+		)
+
+		if s.RetainUntil != nil {
+			lines = append(lines, fmt.Sprintf("  retained until: %v", s.RetainUntil.Format(time.RFC3339)))
+		}
+
+		if s.Error != nil {
+			lines = append(lines, fmt.Sprintf("  error:          %v", *s.Error))
+		}
+	}
+
+	if len(report.Executions) == 0 {
+		lines = append(lines, "No deploy in progress")
+	}
+
+	for _, e := range report.Executions {
+		started := ""
+		if e.StartDate != nil {
+			started = fmt.Sprintf(" started %v", e.StartDate.Format(time.RFC3339))
+		}
+		lines = append(lines, fmt.Sprintf("Deploying release %v with execution %v%v", to.Strs(e.ReleaseID), to.Strs(e.Name), started))
+	}
+
+	if report.Lock != nil {
+		holder := report.Lock.UUID
+		if report.Lock.Execution != nil {
+			holder = fmt.Sprintf("%v (release %v)", *report.Lock.Execution, to.Strs(report.Lock.ReleaseID))
+		}
+		lines = append(lines, fmt.Sprintf("Locked by %v", holder))
+	}
+
+	if report.UserLock != nil {
+		lines = append(lines, fmt.Sprintf("Deploys locked by %v for reason: %v", report.UserLock.User, report.UserLock.Reason))
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,140 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/bifrost"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Status(t *testing.T) {
+	awsc := mocks.MockAWS()
+
+	awsc.ASG.AddASG(mocks.MakeMockASG("project-config-web-old", "project", "config", "web", "old"))
+	awsc.ASG.AddASG(mocks.MakeMockASG("other-config-web-old", "other", "config", "web", "old"))
+	awsc.ELB.AddELB("elb", "project", "config", "web")
+	awsc.ALB.AddTargetGroup(mocks.MockTargetGroup{Name: "tg", ProjectName: "project", ConfigName: "config", ServiceName: "web"})
+	awsc.EC2.CreateLaunchTemplate(&ec2.CreateLaunchTemplateInput{
+		LaunchTemplateName: to.Strp("project-config-web-old"),
+		LaunchTemplateData: &ec2.RequestLaunchTemplateData{
+			ImageId:      to.Strp("ami-123456"),
+			InstanceType: to.Strp("t2.small"),
+		},
+	})
+
+	report, err := status(awsc, to.Strp("project"), to.Strp("config"), to.Strp("region"), to.Strp("account"), to.Strp("deployerARN"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, len(report.Services))
+	web := report.Services[0]
+	assert.Equal(t, "web", *web.ServiceName)
+	assert.Equal(t, "old", *web.ReleaseID)
+	assert.Equal(t, "ami-123456", *web.Image)
+	assert.Equal(t, []string{"t2.small"}, to.StrSlice(web.InstanceTypes))
+	assert.Equal(t, int64(3), web.MaxSize)
+	assert.Equal(t, 1, web.Healthy)
+
+	assert.Equal(t, 0, len(report.Executions))
+	assert.Nil(t, report.Lock)
+	assert.Nil(t, report.UserLock)
+
+	str := statusStr(report)
+	assert.Contains(t, str, "web (project-config-web-old):")
+	assert.Contains(t, str, "capacity:       min 1, desired 1, max 3")
+	assert.Contains(t, str, "No deploy in progress")
+}
+
+func Test_Status_LaunchDetailsError(t *testing.T) {
+	awsc := mocks.MockAWS()
+
+	awsc.ASG.AddASG(mocks.MakeMockASG("project-config-web-old", "project", "config", "web", "old"))
+	awsc.ASG.AddASG(mocks.MakeMockASG("project-config-worker-old", "project", "config", "worker", "old"))
+	awsc.ELB.AddELB("elb", "project", "config", "web")
+	awsc.ALB.AddTargetGroup(mocks.MockTargetGroup{Name: "tg", ProjectName: "project", ConfigName: "config", ServiceName: "web"})
+	awsc.EC2.CreateLaunchTemplate(&ec2.CreateLaunchTemplateInput{
+		LaunchTemplateName: to.Strp("project-config-web-old"),
+		LaunchTemplateData: &ec2.RequestLaunchTemplateData{
+			ImageId:      to.Strp("ami-123456"),
+			InstanceType: to.Strp("t2.small"),
+		},
+	})
+
+	// The workers launch template is missing
+	report, err := status(awsc, to.Strp("project"), to.Strp("config"), to.Strp("region"), to.Strp("account"), to.Strp("deployerARN"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(report.Services))
+
+	web, worker := report.Services[0], report.Services[1]
+	assert.Equal(t, "ami-123456", *web.Image)
+	assert.Nil(t, web.Error)
+
+	assert.Equal(t, "worker", *worker.ServiceName)
+	assert.Nil(t, worker.Image)
+	assert.Contains(t, *worker.Error, "LaunchTemplate not found")
+	assert.Equal(t, 1, worker.Healthy)
+
+	assert.Contains(t, statusStr(report), "error:          Error getting launch details")
+}
+
+func Test_Status_Deploying(t *testing.T) {
+	awsc := mocks.MockAWS()
+
+	release := bifrost.Release{
+		ProjectName: to.Strp("project"),
+		ConfigName:  to.Strp("config"),
+		ReleaseID:   to.Strp("new"),
+		UUID:        to.Strp("uuid"),
+	}
+	release.SetDefaults(to.Strp("region"), to.Strp("account"), "coinbase-odin-")
+	name := release.ExecutionName()
+
+	awsc.SFN.ListExecutionsResp = &sfn.ListExecutionsOutput{
+		Executions: []*sfn.ExecutionListItem{
+			&sfn.ExecutionListItem{
+				Name:         name,
+				ExecutionArn: to.Strp("arn"),
+				StartDate:    to.Timep(time.Now()),
+			},
+		},
+	}
+
+	input, _ := to.PrettyJSON(release)
+	awsc.SFN.DescribeExecutionResp = &sfn.DescribeExecutionOutput{Input: &input, Status: to.Strp("RUNNING")}
+
+	awsc.S3.AddGetObject(*release.RootLockPath(), `{"uuid": "uuid"}`, nil)
+	awsc.S3.AddGetObject(*release.UserLockPath(), `{"user": "ops", "lock_reason": "incident"}`, nil)
+
+	report, err := status(awsc, to.Strp("project"), to.Strp("config"), to.Strp("region"), to.Strp("account"), to.Strp("deployerARN"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, len(report.Executions))
+	assert.Equal(t, "new", *report.Executions[0].ReleaseID)
+
+	assert.Equal(t, "uuid", report.Lock.UUID)
+	assert.Equal(t, *name, *report.Lock.Execution)
+	assert.Equal(t, "ops", report.UserLock.User)
+
+	str := statusStr(report)
+	assert.Contains(t, str, "No autoscaling groups found")
+	assert.Contains(t, str, "Deploying release new")
+	assert.Contains(t, str, "(release new)")
+	assert.Contains(t, str, "Deploys locked by ops for reason: incident")
+}
+
+func Test_StatusStr_Retained(t *testing.T) {
+	until := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	report := &StatusReport{
+		ProjectName: to.Strp("project"),
+		ConfigName:  to.Strp("config"),
+		Services: []*ServiceStatus{
+			&ServiceStatus{ServiceName: to.Strp("web"), AutoScalingGroupName: to.Strp("project-config-web-old"), RetainUntil: &until},
+		},
+	}
+
+	assert.Contains(t, statusStr(report), "retained until: 2020-01-02T03:04:05Z")
+}
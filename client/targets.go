@@ -0,0 +1,228 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/deployer/models"
+	"github.com/coinbase/step/execution"
+	"github.com/coinbase/step/utils/to"
+)
+
+// targetDeploy is the progress of the release in one account and region
+type targetDeploy struct {
+	name    string
+	stage   int
+	release *models.Release
+	exec    *execution.Execution
+	status  string // PENDING, SKIPPED or the executions status
+	line    string
+	halted  bool
+}
+
+// deployTargets deploys a copy of the release to each of its accounts and regions
+// The deployer runs in accountID, in each region
+func deployTargets(awsc aws.Clients, release *models.Release, step_fn *string, accountID *string, signKey *string, sleep time.Duration) error {
+	stages, err := release.TargetReleases()
+	if err != nil {
+		return err
+	}
+
+	// Each copy is different so is signed separately
+	for _, releases := range stages {
+		for _, r := range releases {
+			if err := signRelease(awsc, r, signKey); err != nil {
+				return err
+			}
+		}
+	}
+
+	deploys := newTargetDeploys(release, stages)
+
+	if err := waitForTargets(awsc, deploys, step_fn, accountID, sleep); err != nil {
+		return err
+	}
+
+	return targetsError(deploys)
+}
+
+func newTargetDeploys(release *models.Release, stages [][]*models.Release) []*targetDeploy {
+	deploys := []*targetDeploy{}
+	for stage, releases := range stages {
+		for _, r := range releases {
+			name := to.Strs(r.AwsRegion)
+			if len(release.Accounts) > 0 {
+				name = fmt.Sprintf("%v %v", to.Strs(r.AwsAccountID), name)
+			}
+
+			deploys = append(deploys, &targetDeploy{name: name, stage: stage, release: r, status: "PENDING"})
+		}
+	}
+	return deploys
+}
+
+// waitForTargets starts each stage once the previous stage succeeds and prints the progress until all have finished
+// After any target fails the targets not yet started are skipped and the running ones are halted
+func waitForTargets(awsc aws.Clients, deploys []*targetDeploy, step_fn *string, accountID *string, sleep time.Duration) error {
+	for printed := false; ; printed = true {
+		if err := startTargets(awsc, deploys, step_fn, accountID); err != nil {
+			return err
+		}
+
+		if err := pollTargets(awsc, deploys); err != nil {
+			return err
+		}
+
+		if err := stopTargets(awsc, deploys); err != nil {
+			return err
+		}
+
+		spinnerCounter++
+		printTargets(deploys, printed)
+
+		if targetsDone(deploys) {
+			return nil
+		}
+
+		time.Sleep(sleep)
+	}
+}
+
+func startTargets(awsc aws.Clients, deploys []*targetDeploy, step_fn *string, accountID *string) error {
+	// The first stage that has not succeeded
+	stage := -1
+	for _, d := range deploys {
+		if d.status != "SUCCEEDED" {
+			stage = d.stage
+			break
+		}
+	}
+
+	for _, d := range deploys {
+		if d.status != "PENDING" || d.stage != stage {
+			continue
+		}
+
+		region := d.release.AwsRegion
+		exec, err := startDeploy(awsc, region, d.release, to.StepArn(region, accountID, step_fn))
+		if err != nil {
+			return fmt.Errorf("%v Error %v", d.name, err.Error())
+		}
+
+		d.exec = exec
+		d.status = "RUNNING"
+	}
+
+	return nil
+}
+
+func pollTargets(awsc aws.Clients, deploys []*targetDeploy) error {
+	for _, d := range deploys {
+		if d.status != "RUNNING" {
+			continue
+		}
+
+		exec, sd, err := execution.GetDetails(awsc.SFNClient(d.release.AwsRegion, nil, nil), d.exec.ExecutionArn)
+		if err != nil {
+			return fmt.Errorf("%v Unexpected Error %v", d.name, err.Error())
+		}
+
+		line, err := waiterStr(exec.Status, sd)
+		if err != nil {
+			return err
+		}
+
+		d.status = *exec.Status
+		d.line = line
+	}
+
+	return nil
+}
+
+// stopTargets skips the targets not yet started and halts the running ones once a target has failed
+func stopTargets(awsc aws.Clients, deploys []*targetDeploy) error {
+	var failed *targetDeploy
+	for _, d := range deploys {
+		if isTargetFailed(d) {
+			failed = d
+			break
+		}
+	}
+
+	if failed == nil {
+		return nil
+	}
+
+	for _, d := range deploys {
+		switch {
+		case d.status == "PENDING":
+			d.status = "SKIPPED"
+		case d.status == "RUNNING" && !d.halted:
+			reason := to.Strp(fmt.Sprintf("Odin client Halted deploy after %v failed", failed.name))
+			if err := d.release.Halt(awsc.S3Client(d.release.AwsRegion, nil, nil), reason); err != nil {
+				return fmt.Errorf("%v Error %v", d.name, err.Error())
+			}
+			d.halted = true
+		}
+	}
+
+	return nil
+}
+
+func isTargetFailed(d *targetDeploy) bool {
+	switch d.status {
+	case "PENDING", "RUNNING", "SUCCEEDED", "SKIPPED":
+		return false
+	}
+	return true
+}
+
+// printTargets prints a line per target, redrawing the previous lines
+func printTargets(deploys []*targetDeploy, redraw bool) {
+	if redraw {
+		fmt.Printf("\x1b[%dA", len(deploys))
+	}
+
+	for _, d := range deploys {
+		fmt.Printf("\r%v\x1b[K\n", targetStr(d))
+	}
+}
+
+func targetStr(d *targetDeploy) string {
+	line := d.line
+	if d.status == "PENDING" || d.status == "SKIPPED" {
+		line = d.status
+	}
+
+	if d.halted {
+		line = fmt.Sprintf("%v HALTED", line)
+	}
+
+	return fmt.Sprintf("%v: %v", d.name, line)
+}
+
+func targetsDone(deploys []*targetDeploy) bool {
+	for _, d := range deploys {
+		if d.status == "PENDING" || d.status == "RUNNING" {
+			return false
+		}
+	}
+	return true
+}
+
+func targetsError(deploys []*targetDeploy) error {
+	failed := []string{}
+	for _, d := range deploys {
+		if d.status != "SUCCEEDED" {
+			failed = append(failed, fmt.Sprintf("%v %v", d.name, d.status))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("Targets did not succeed: %v", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
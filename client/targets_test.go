@@ -0,0 +1,146 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/odin/deployer/models"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func regionsRelease(t *testing.T) *models.Release {
+	r := minimalRelease(t)
+	r.Release.SetDefaults(to.Strp("region"), to.Strp("accountid"), "")
+	r.SetUserData(to.Strp("#cloud_config"))
+	r.Regions = []*models.RegionOverride{
+		&models.RegionOverride{Region: to.Strp("us-east-1")},
+		&models.RegionOverride{Region: to.Strp("us-west-2"), Bucket: to.Strp("bucket-west")},
+	}
+	return r
+}
+
+func accountsRelease(t *testing.T) *models.Release {
+	r := minimalRelease(t)
+	r.Release.SetDefaults(to.Strp("region"), to.Strp("accountid"), "")
+	r.SetUserData(to.Strp("#cloud_config"))
+	r.Accounts = []*models.AccountOverride{
+		&models.AccountOverride{AccountID: to.Strp("sandbox")},
+		&models.AccountOverride{AccountID: to.Strp("staging")},
+		&models.AccountOverride{AccountID: to.Strp("prod")},
+	}
+	return r
+}
+
+func targetDeploys(t *testing.T, r *models.Release) []*targetDeploy {
+	stages, err := r.TargetReleases()
+	assert.NoError(t, err)
+	return newTargetDeploys(r, stages)
+}
+
+func Test_DeployTargets_Regions(t *testing.T) {
+	awsc := mocks.MockAWS()
+	r := regionsRelease(t)
+
+	assert.NoError(t, deployTargets(awsc, r, to.Strp("coinbase-odin"), to.Strp("accountid"), nil, 0))
+
+	r.RegionStrategy = to.Strp("Parallel")
+	assert.NoError(t, deployTargets(awsc, r, to.Strp("coinbase-odin"), to.Strp("accountid"), nil, 0))
+}
+
+func Test_DeployTargets_Signed(t *testing.T) {
+	awsc := mocks.MockAWS()
+	r := regionsRelease(t)
+
+	assert.NoError(t, deployTargets(awsc, r, to.Strp("coinbase-odin"), to.Strp("accountid"), to.Strp("alias/odin"), 0))
+
+	// Each region is sent its own release, so each is signed
+	assert.Equal(t, 2, len(awsc.KMS.SignInputs))
+	assert.NotEqual(t, awsc.KMS.SignInputs[0].Message, awsc.KMS.SignInputs[1].Message)
+	assert.Nil(t, r.Signature)
+}
+
+func Test_DeployTargets_SkipsAfterFailure(t *testing.T) {
+	awsc := mocks.MockAWS()
+	awsc.SFN.DescribeExecutionResp = &sfn.DescribeExecutionOutput{Status: to.Strp("FAILED")}
+	r := regionsRelease(t)
+
+	deploys := targetDeploys(t, r)
+	assert.NoError(t, waitForTargets(awsc, deploys, to.Strp("coinbase-odin"), to.Strp("accountid"), 0))
+
+	assert.Equal(t, "FAILED", deploys[0].status)
+	assert.Equal(t, "SKIPPED", deploys[1].status)
+	assert.Nil(t, deploys[1].exec)
+	assert.Equal(t, "us-west-2: SKIPPED", targetStr(deploys[1]))
+
+	err := targetsError(deploys)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "us-east-1 FAILED, us-west-2 SKIPPED")
+}
+
+func Test_DeployTargets_Parallel(t *testing.T) {
+	awsc := mocks.MockAWS()
+	awsc.SFN.DescribeExecutionResp = &sfn.DescribeExecutionOutput{Status: to.Strp("FAILED")}
+	r := regionsRelease(t)
+	r.RegionStrategy = to.Strp("Parallel")
+
+	// Parallel regions all start at once
+	deploys := targetDeploys(t, r)
+	assert.NoError(t, waitForTargets(awsc, deploys, to.Strp("coinbase-odin"), to.Strp("accountid"), 0))
+
+	assert.Equal(t, "FAILED", deploys[0].status)
+	assert.Equal(t, "FAILED", deploys[1].status)
+	assert.NotNil(t, deploys[1].exec)
+}
+
+func Test_DeployTargets_Accounts(t *testing.T) {
+	awsc := mocks.MockAWS()
+	r := accountsRelease(t)
+
+	deploys := targetDeploys(t, r)
+	assert.Equal(t, 3, len(deploys))
+	assert.Equal(t, "sandbox region", deploys[0].name)
+	assert.Equal(t, 2, deploys[2].stage)
+
+	assert.NoError(t, waitForTargets(awsc, deploys, to.Strp("coinbase-odin"), to.Strp("accountid"), 0))
+	assert.NoError(t, targetsError(deploys))
+
+	// Each account has its own release in the deployers bucket
+	for _, d := range deploys {
+		assert.Equal(t, "accountid", *d.release.Bucket)
+		assert.NotNil(t, awsc.S3.GetObjectResp[*d.release.ReleasePath()])
+	}
+}
+
+func Test_DeployTargets_Accounts_HaltsRunning(t *testing.T) {
+	awsc := mocks.MockAWS()
+	r := accountsRelease(t)
+	r.AccountStrategy = to.Strp("Parallel")
+
+	deploys := targetDeploys(t, r)
+	assert.NoError(t, startTargets(awsc, deploys, to.Strp("coinbase-odin"), to.Strp("accountid")))
+	for _, d := range deploys {
+		assert.Equal(t, "RUNNING", d.status)
+	}
+
+	deploys[1].status = "FAILED"
+	assert.NoError(t, stopTargets(awsc, deploys))
+
+	assert.True(t, deploys[0].halted)
+	assert.False(t, deploys[1].halted)
+	assert.True(t, deploys[2].halted)
+
+	halt := awsc.S3.GetObjectResp[*deploys[0].release.HaltPath()]
+	assert.NotNil(t, halt)
+	assert.Equal(t, "Odin client Halted deploy after staging region failed", halt.Body)
+	assert.Nil(t, awsc.S3.GetObjectResp[*deploys[1].release.HaltPath()])
+}
+
+func Test_DeployTargets_Invalid(t *testing.T) {
+	awsc := mocks.MockAWS()
+	r := regionsRelease(t)
+	r.Regions[1].Bucket = nil
+
+	assert.Error(t, deployTargets(awsc, r, to.Strp("coinbase-odin"), to.Strp("accountid"), nil, 0))
+}
@@ -3,10 +3,12 @@ package deployer
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/coinbase/odin/aws"
 	"github.com/coinbase/odin/deployer/models"
 	"github.com/coinbase/step/aws/dynamodb"
+	"github.com/coinbase/step/bifrost"
 	"github.com/coinbase/step/errors"
 	"github.com/coinbase/step/utils/to"
 )
@@ -29,6 +31,12 @@ func (e DetachError) Error() string {
 
 var assumedRole = to.Strp("coinbase-odin-assumed")
 
+// requireSignedReleases is true if the deployer Lambda is set to reject unsigned releases,
+// with the environment so it cannot be turned off by writing to S3
+func requireSignedReleases() bool {
+	return os.Getenv("ODIN_REQUIRE_SIGNED_RELEASES") == "true"
+}
+
 // Validate checks the release for issues
 func Validate(awsc aws.Clients) DeployHandler {
 	return func(ctx context.Context, release *models.Release) (*models.Release, error) {
@@ -41,10 +49,24 @@ func Validate(awsc aws.Clients) DeployHandler {
 		release.Release.SetDefaults(region, account, "coinbase-odin-")
 		release.SetDefaults() // Fill in all the blank Attributes
 
-		if err := release.Validate(awsc.S3Client(release.AwsRegion, nil, nil)); err != nil {
+		if requireSignedReleases() {
+			release.RequireSignature()
+		}
+
+		s3c := awsc.S3Client(release.AwsRegion, nil, nil)
+		if err := release.Validate(s3c, awsc.KMSClient(release.AwsRegion, nil, nil)); err != nil {
+			// Bad releases go straight to FailureClean
+			// They are not trusted, so the deployer does not send their notifications
 			return nil, &errors.BadReleaseError{err.Error()}
 		}
 
+		if err := release.ValidateUploadedSignature(s3c); err != nil {
+			return nil, &errors.BadReleaseError{err.Error()}
+		}
+
+		release.RecordEvent("Validated", nil, "", nil)
+		notify(awsc, release, "ValidatePassed")
+
 		return release, nil
 	}
 }
@@ -57,7 +79,17 @@ func Lock(awsc aws.Clients) DeployHandler {
 		locker := dynamodb.NewDynamoDBLocker(awsc.DynamoDBClient(nil, nil, nil))
 		lockTableName := getLockTableNameFromContext(ctx, "-locks")
 
-		return release, release.GrabLocks(awsc.S3Client(release.AwsRegion, nil, nil), locker, lockTableName)
+		err := release.GrabLocks(awsc.S3Client(release.AwsRegion, nil, nil), locker, lockTableName)
+		switch err.(type) {
+		case nil:
+			release.RecordEvent("LockAcquired", nil, "", nil)
+			notify(awsc, release, "LockAcquired")
+		case *errors.LockExistsError:
+			// Another release has the lock, straight to FailureClean
+			notifyError(awsc, release, "FailureClean", "LockExistsError", err)
+		}
+
+		return release, err
 	}
 }
 
@@ -75,6 +107,7 @@ func ValidateResources(awsc aws.Clients) DeployHandler {
 			awsc.ALBClient(release.AwsRegion, release.AwsAccountID, assumedRole),
 			awsc.IAMClient(release.AwsRegion, release.AwsAccountID, assumedRole),
 			awsc.SNSClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+			awsc.SQSClient(release.AwsRegion, release.AwsAccountID, assumedRole),
 		)
 
 		if err != nil {
@@ -96,6 +129,22 @@ func ValidateResources(awsc aws.Clients) DeployHandler {
 		}
 
 		release.UpdateWithResources(resources)
+		release.SetDefaults() // Recalculate the strategies with the previous capacities
+
+		for name, service := range release.Services {
+			release.RecordEvent("ResourcesResolved", to.Strp(name), "", service.Resources)
+		}
+
+		// Fail before creating any ASG if the account cannot launch the release
+		if err := release.ValidateCapacity(
+			awsc.ASGClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+			awsc.EC2Client(release.AwsRegion, release.AwsAccountID, assumedRole),
+			awsc.CWClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+			awsc.ServiceQuotasClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+			resources,
+		); err != nil {
+			return nil, &errors.BadReleaseError{err.Error()}
+		}
 
 		return release, nil
 	}
@@ -116,11 +165,14 @@ func Deploy(awsc aws.Clients) DeployHandler {
 
 		if err := release.CreateResources(
 			awsc.ASGClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+			awsc.EC2Client(release.AwsRegion, release.AwsAccountID, assumedRole),
 			awsc.CWClient(release.AwsRegion, release.AwsAccountID, assumedRole),
 		); err != nil {
 			return nil, &errors.DeployError{err.Error()}
 		}
 
+		notify(awsc, release, "DeployStarted")
+
 		return release, nil
 	}
 }
@@ -134,10 +186,18 @@ func CheckHealthy(awsc aws.Clients) DeployHandler {
 			return nil, &errors.HaltError{err.Error()}
 		}
 
+		// Paused rollouts continue once approved
+		if err := release.UpdateApproved(awsc.S3Client(release.AwsRegion, nil, nil)); err != nil {
+			return nil, &errors.HealthError{err.Error()}
+		}
+
+		steps := release.RolloutSteps()
+
 		err := release.UpdateHealthy(
 			awsc.ASGClient(release.AwsRegion, release.AwsAccountID, assumedRole),
 			awsc.ELBClient(release.AwsRegion, release.AwsAccountID, assumedRole),
 			awsc.ALBClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+			awsc.CWClient(release.AwsRegion, release.AwsAccountID, assumedRole),
 		)
 
 		if err != nil {
@@ -151,6 +211,14 @@ func CheckHealthy(awsc aws.Clients) DeployHandler {
 			}
 		}
 
+		if release.RolloutStepChanged(steps) {
+			notify(awsc, release, "RolloutStep")
+		}
+
+		if release.Healthy != nil && *release.Healthy {
+			release.RecordEvent("Healthy", nil, "", nil)
+		}
+
 		return release, nil
 	}
 }
@@ -182,6 +250,7 @@ func CleanUpSuccess(awsc aws.Clients) DeployHandler {
 
 		if err := release.SuccessfulTearDown(
 			awsc.ASGClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+			awsc.EC2Client(release.AwsRegion, release.AwsAccountID, assumedRole),
 			awsc.CWClient(release.AwsRegion, release.AwsAccountID, assumedRole),
 		); err != nil {
 			return nil, &errors.CleanUpError{err.Error()}
@@ -206,6 +275,9 @@ func CleanUpSuccess(awsc aws.Clients) DeployHandler {
 		release.RemoveHalt(awsc.S3Client(release.AwsRegion, nil, nil)) // Delete Halt
 
 		release.Success = to.Boolp(true) // Wait till the end to mark success
+		release.RecordEvent("Success", nil, "", nil)
+
+		notify(awsc, release, "Success")
 
 		return release, nil
 	}
@@ -216,6 +288,13 @@ func DetachForFailure(awsc aws.Clients) DeployHandler {
 	return func(_ context.Context, release *models.Release) (*models.Release, error) {
 		release.SetDefaults() // Wire up non-serialized relationships
 
+		// Send traffic back to the previous release before detaching
+		if err := release.ResetTraffic(
+			awsc.ALBClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+		); err != nil {
+			return nil, &errors.CleanUpError{err.Error()}
+		}
+
 		if err := release.DetachForFailure(
 			awsc.ASGClient(release.AwsRegion, release.AwsAccountID, assumedRole),
 		); err != nil {
@@ -238,8 +317,16 @@ func CleanUpFailure(awsc aws.Clients) DeployHandler {
 
 		release.Success = to.Boolp(false) // Quickly Mark Failure
 
+		// Never tear down the new release while it still has traffic
+		if err := release.ResetTraffic(
+			awsc.ALBClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+		); err != nil {
+			return nil, &errors.CleanUpError{err.Error()}
+		}
+
 		if err := release.UnsuccessfulTearDown(
 			awsc.ASGClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+			awsc.EC2Client(release.AwsRegion, release.AwsAccountID, assumedRole),
 			awsc.CWClient(release.AwsRegion, release.AwsAccountID, assumedRole),
 		); err != nil {
 			switch err.(type) {
@@ -269,10 +356,58 @@ func ReleaseLockFailure(awsc aws.Clients) DeployHandler {
 
 		release.RemoveHalt(awsc.S3Client(release.AwsRegion, nil, nil)) // Delete Halt
 
+		release.RecordEvent("LockReleased", nil, "", nil)
+		notify(awsc, release, "FailureClean")
+
+		return release, nil
+	}
+}
+
+// NotifyFailureDirty sends the FailureDirty notifications
+func NotifyFailureDirty(awsc aws.Clients) DeployHandler {
+	return func(_ context.Context, release *models.Release) (*models.Release, error) {
+		release.SetDefaults() // Wire up non-serialized relationships
+
+		notify(awsc, release, "FailureDirty")
+
 		return release, nil
 	}
 }
 
+// withEvents records the handlers error and appends its events to the releases events.jsonl
+// Failing to write the events never fails the deploy
+func withEvents(awsc aws.Clients, state string, handler DeployHandler) DeployHandler {
+	return func(ctx context.Context, release *models.Release) (*models.Release, error) {
+		out, err := handler(ctx, release)
+
+		// A release that fails Validate is not trusted, its paths could be another releases events.jsonl
+		if state == "Validate" && err != nil {
+			return out, err
+		}
+
+		if err != nil {
+			release.RecordError(err)
+		}
+
+		if werr := release.WriteEvents(awsc.S3Client(release.AwsRegion, nil, nil), state); werr != nil {
+			fmt.Printf("IGNORED: events %v\n", werr)
+		}
+
+		return out, err
+	}
+}
+
+// notify sends the event with the deployers SNS client, it never fails
+func notify(awsc aws.Clients, release *models.Release, event string) {
+	release.Notify(awsc.SNSClient(release.AwsRegion, nil, nil), event)
+}
+
+// notifyError sends the event for an error the state machine fails with without another handler
+func notifyError(awsc aws.Clients, release *models.Release, event string, errorName string, err error) {
+	release.Error = &bifrost.ReleaseError{Error: to.Strp(errorName), Cause: to.Strp(err.Error())}
+	notify(awsc, release, event)
+}
+
 func getLockTableNameFromContext(ctx context.Context, postfix string) string {
 	_, _, lambdaName := to.AwsRegionAccountLambdaNameFromContext(ctx)
 	return fmt.Sprintf("%s%s", lambdaName, postfix)
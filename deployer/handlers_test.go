@@ -7,6 +7,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/coinbase/odin/aws/mocks"
 	"github.com/coinbase/odin/deployer/models"
+	"github.com/coinbase/step/errors"
 	"github.com/coinbase/step/utils/to"
 	"github.com/stretchr/testify/assert"
 )
@@ -96,6 +97,43 @@ func Test_ValidateResources_AllowedServiceTg(t *testing.T) {
 	assert.Equal(t, []string{"other-project-target"}, to.StrSlice(res.TargetGroups))
 }
 
+func Test_ValidateResources_ASGLimit(t *testing.T) {
+	release := models.MockRelease(t)
+	models.MockPrepareRelease(release)
+
+	awsc := models.MockAwsClients(release)
+	awsc.ASG.DescribeAccountLimitsOutput = &autoscaling.DescribeAccountLimitsOutput{
+		MaxNumberOfAutoScalingGroups: to.Int64p(200),
+		NumberOfAutoScalingGroups:    to.Int64p(200),
+	}
+	_, err := ValidateResources(awsc)(nil, release)
+	assert.IsType(t, &errors.BadReleaseError{}, err)
+	assert.Contains(t, err.Error(), "Auto Scaling group limit reached")
+}
+
+func Test_ValidateResources_InstanceTypeNotOffered(t *testing.T) {
+	release := models.MockRelease(t)
+	models.MockPrepareRelease(release)
+
+	awsc := models.MockAwsClients(release)
+	awsc.EC2.NotOfferedInstanceTypes = map[string][]string{"us-east-1a": []string{"t2.small"}}
+	_, err := ValidateResources(awsc)(nil, release)
+	assert.IsType(t, &errors.BadReleaseError{}, err)
+	assert.Contains(t, err.Error(), "instance type t2.small is not offered in us-east-1a")
+}
+
+func Test_ValidateResources_VCPUQuota(t *testing.T) {
+	release := models.MockRelease(t)
+	models.MockPrepareRelease(release)
+
+	awsc := models.MockAwsClients(release)
+	awsc.SQ.AddQuota("L-1216C47A", 100)
+	awsc.CW.AddMetricSum("ResourceCount", 99)
+	_, err := ValidateResources(awsc)(nil, release)
+	assert.IsType(t, &errors.BadReleaseError{}, err)
+	assert.Contains(t, err.Error(), "Standard/OnDemand vCPU quota L-1216C47A reached, 1 remaining but 2 required")
+}
+
 // Test Check Healthy
 func Test_CheckHealthy_CorrectReport(t *testing.T) {
 	release := models.MockRelease(t)
@@ -141,3 +179,47 @@ func Test_CheckHealthy_Terming(t *testing.T) {
 	_, err := CheckHealthy(awsc)(nil, release)
 	assert.Error(t, err)
 }
+
+// Test Detach For Failure sends the shifted traffic back to the previous target group
+func Test_DetachForFailure_ResetsTraffic(t *testing.T) {
+	release := models.MockRelease(t)
+	release.Services["web"].TrafficShift = &models.TrafficShiftConfig{
+		ListenerRule: to.Strp("web-rule"),
+		TargetGroups: []*string{to.Strp("web-blue"), to.Strp("web-green")},
+	}
+	models.MockPrepareRelease(release)
+	release.Services["web"].Resources = &models.ServiceResourceNames{
+		TrafficRule:            to.Strp("web-rule"),
+		TrafficLiveTargetGroup: to.Strp("web-blue"),
+		TrafficNewTargetGroup:  to.Strp("web-green"),
+	}
+	release.Services["web"].TrafficStep = 2
+
+	awsc := models.MockAwsClients(release)
+	awsc.ALB.AddRule("web-rule", map[string]int64{"web-blue": 75, "web-green": 25})
+
+	_, err := DetachForFailure(awsc)(nil, release)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, len(awsc.ALB.ModifyRuleInputs))
+	tgs := awsc.ALB.ModifyRuleInputs[0].Actions[0].ForwardConfig.TargetGroups
+	assert.Equal(t, "web-blue", *tgs[0].TargetGroupArn)
+	assert.EqualValues(t, 100, *tgs[0].Weight)
+	assert.EqualValues(t, 0, *tgs[1].Weight)
+}
+
+func Test_NotifyFailureDirty(t *testing.T) {
+	release := models.MockRelease(t)
+	models.MockPrepareRelease(release)
+	release.Notifications = &models.NotificationConfig{
+		SNSTopics: []*string{to.Strp("arn:aws:sns:us-east-1:000000:odin-notifications-deploys")},
+	}
+
+	awsc := models.MockAwsClients(release)
+	awsc.SNS.PublishError = fmt.Errorf("SNS is down")
+
+	_, err := NotifyFailureDirty(awsc)(nil, release)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(awsc.SNS.PublishInputs))
+	assert.Equal(t, "odin FailureDirty", *awsc.SNS.PublishInputs[0].Subject)
+}
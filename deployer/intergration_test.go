@@ -1,13 +1,16 @@
 package deployer
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 
 	"github.com/coinbase/odin/aws/mocks"
 	"github.com/coinbase/odin/deployer/models"
+	"github.com/coinbase/step/aws/s3"
 	"github.com/coinbase/step/utils/to"
 	"github.com/stretchr/testify/assert"
 )
@@ -22,6 +25,63 @@ func Test_Successful_Execution_Works(t *testing.T) {
 	assertSuccessfulExecution(t, release)
 }
 
+func Test_Successful_Execution_Notifications(t *testing.T) {
+	release := models.MockRelease(t)
+	release.Notifications = &models.NotificationConfig{
+		SNSTopics: []*string{to.Strp("arn:aws:sns:us-east-1:000000:odin-notifications-deploys")},
+	}
+
+	awsc := models.MockAwsClients(release)
+	awsc.SNS.PublishError = fmt.Errorf("SNS is down") // Never fails the deploy
+	assertSuccessfulExecutionWithAWS(t, release, awsc)
+
+	assert.Equal(t, []string{
+		"ValidatePassed",
+		"LockAcquired",
+		"DeployStarted",
+		"Success",
+	}, publishedEvents(t, awsc))
+}
+
+func publishedEvents(t *testing.T, awsc *mocks.MockClients) []string {
+	events := []string{}
+	for _, input := range awsc.SNS.PublishInputs {
+		var event models.NotificationEvent
+		assert.NoError(t, json.Unmarshal([]byte(*input.Message), &event))
+		assert.Equal(t, "1", *event.ReleaseID)
+		assert.Contains(t, event.Services, "web")
+		events = append(events, event.Event)
+	}
+	return events
+}
+
+func Test_Successful_Execution_Events(t *testing.T) {
+	release := models.MockRelease(t)
+	awsc := models.MockAwsClients(release)
+	assertSuccessfulExecutionWithAWS(t, release, awsc)
+
+	assert.Equal(t, []string{
+		"Validate Validated",
+		"Lock LockAcquired",
+		"ValidateResources ResourcesResolved",
+		"Deploy ASGCreated",
+		"CheckHealthy Healthy",
+		"CleanUpSuccess ASGDeleted",
+		"CleanUpSuccess Success",
+	}, recordedEvents(t, awsc, release))
+}
+
+func recordedEvents(t *testing.T, awsc *mocks.MockClients, release *models.Release) []string {
+	events, err := release.ReadEvents(awsc.S3)
+	assert.NoError(t, err)
+
+	types := []string{}
+	for _, event := range events {
+		types = append(types, fmt.Sprintf("%v %v", event.State, event.Type))
+	}
+	return types
+}
+
 func Test_Successful_Execution_Works_With_Minimal_Release(t *testing.T) {
 	// Should end in Alert Bad Thing Happened State
 	release := models.MockMinimalRelease(t)
@@ -87,6 +147,129 @@ func Test_UnsuccessfulDeploy_Bad_Userdata_SHA(t *testing.T) {
 	}, exec.Path())
 }
 
+func Test_UnsuccessfulDeploy_Unsigned_Release(t *testing.T) {
+	release := models.MockRelease(t)
+	awsc := models.MockAwsClients(release)
+	awsc.S3.AddGetObject(*release.SigningKeysPath(), `{"signing_keys": [
+		{"project_name": "project", "public_keys": {"ci": "MCowBQYDK2VwAyEAGb9ECWmEzf6FQbrBZ9w7lshQhqowtrbLDFw4rXAxZuE="}}
+	]}`, nil)
+
+	stateMachine := createTestStateMachine(t, awsc)
+
+	exec, err := stateMachine.Execute(release)
+
+	assert.Error(t, err)
+	assert.Equal(t, "FailureClean", exec.Output["Error"])
+	assert.Regexp(t, "BadReleaseError", exec.LastOutputJSON)
+	assert.Regexp(t, "Release must be signed", exec.LastOutputJSON)
+
+	assert.Equal(t, []string{
+		"Validate",
+		"FailureClean",
+	}, exec.Path())
+}
+
+func Test_UnsuccessfulDeploy_Required_Signature(t *testing.T) {
+	os.Setenv("ODIN_REQUIRE_SIGNED_RELEASES", "true")
+	defer os.Unsetenv("ODIN_REQUIRE_SIGNED_RELEASES")
+
+	// No signing keys does not turn signing off
+	release := models.MockRelease(t)
+	awsc := models.MockAwsClients(release)
+	stateMachine := createTestStateMachine(t, awsc)
+
+	exec, err := stateMachine.Execute(release)
+
+	assert.Error(t, err)
+	assert.Equal(t, "FailureClean", exec.Output["Error"])
+	assert.Regexp(t, "Release must be signed", exec.LastOutputJSON)
+
+	assert.Equal(t, []string{
+		"Validate",
+		"FailureClean",
+	}, exec.Path())
+}
+
+func Test_UnsuccessfulDeploy_Invalid_Release_Notifications(t *testing.T) {
+	release := models.MockRelease(t)
+	release.Timeout = to.Intp(200000)
+	release.Notifications = &models.NotificationConfig{
+		SNSTopics: []*string{to.Strp("arn:aws:sns:us-east-1:000000:odin-notifications-deploys")},
+	}
+
+	awsc := models.MockAwsClients(release)
+	stateMachine := createTestStateMachine(t, awsc)
+
+	exec, err := stateMachine.Execute(release)
+
+	assert.Error(t, err)
+	assert.Equal(t, "FailureClean", exec.Output["Error"])
+	assert.Regexp(t, "BadReleaseError", exec.LastOutputJSON)
+
+	// A release that fails Validate is not trusted to send notifications
+	assert.Equal(t, 0, len(awsc.SNS.PublishInputs))
+}
+
+func Test_UnsuccessfulDeploy_Invalid_Release_Events(t *testing.T) {
+	release := models.MockRelease(t)
+	awsc := models.MockAwsClients(release)
+
+	// A real releases audit log at the same path
+	existing := `{"time":"2026-01-01T00:00:00Z","state":"Validate","type":"Validated","message":""}` + "\n"
+	awsc.S3.AddGetObject(*release.EventsPath(), existing, nil)
+
+	release.Timeout = to.Intp(200000)
+	stateMachine := createTestStateMachine(t, awsc)
+
+	exec, err := stateMachine.Execute(release)
+
+	assert.Error(t, err)
+	assert.Regexp(t, "BadReleaseError", exec.LastOutputJSON)
+
+	// The invalid release did not write to it
+	events, err := s3.GetStr(awsc.S3, release.Bucket, release.EventsPath())
+	assert.NoError(t, err)
+	assert.Equal(t, existing, *events)
+}
+
+func Test_UnsuccessfulDeploy_Frozen(t *testing.T) {
+	release := models.MockRelease(t)
+	awsc := models.MockAwsClients(release)
+	awsc.S3.AddGetObject(*release.FreezeRulesPath(), `{"freezes": [{"name": "incident", "project_name": "*", "config_name": "*", "reason": "Incident", "cron": "* * * * *", "duration": "1h"}]}`, nil)
+
+	stateMachine := createTestStateMachine(t, awsc)
+
+	exec, err := stateMachine.Execute(release)
+
+	assert.Error(t, err)
+	assert.Equal(t, "FailureClean", exec.Output["Error"])
+	assert.Regexp(t, "BadReleaseError", exec.LastOutputJSON)
+	assert.Regexp(t, "Deploys are frozen", exec.LastOutputJSON)
+
+	assert.Equal(t, []string{
+		"Validate",
+		"FailureClean",
+	}, exec.Path())
+}
+
+func Test_Successful_Execution_Override_Freeze(t *testing.T) {
+	release := models.MockRelease(t)
+	release.OverrideFreeze = to.Strp("Fixing the incident")
+
+	awsc := models.MockAwsClients(release)
+	awsc.S3.AddGetObject(*release.FreezeRulesPath(), `{"freezes": [{"name": "incident", "project_name": "*", "config_name": "*", "reason": "Incident", "cron": "* * * * *", "duration": "1h"}]}`, nil)
+
+	stateMachine := createTestStateMachine(t, awsc)
+
+	exec, err := stateMachine.Execute(release)
+
+	assert.NoError(t, err)
+	assert.Equal(t, true, exec.Output["success"])
+	assert.Regexp(t, `"justification": "Fixing the incident"`, exec.LastOutputJSON)
+	assert.Regexp(t, "incident: Incident", exec.LastOutputJSON)
+	assert.Contains(t, recordedEvents(t, awsc, release), "Validate FreezeOverridden")
+}
+
 func Test_UnsuccessfulDeploy_Execution_Works(t *testing.T) {
 	release := models.MockRelease(t)
 	release.Timeout = to.Intp(-10) // This will cause immediate timeout
@@ -187,6 +370,27 @@ func Test_Execution_FetchDeploy_RootLockError(t *testing.T) {
 	})
 }
 
+func Test_Execution_FetchDeploy_RootLockError_Notifications(t *testing.T) {
+	release := models.MockRelease(t)
+	release.Notifications = &models.NotificationConfig{
+		SNSTopics: []*string{to.Strp("arn:aws:sns:us-east-1:000000:odin-notifications-deploys")},
+	}
+
+	awsClients := models.MockAwsClients(release)
+	awsClients.S3.AddGetObject(*release.RootLockPath(), `{"uuid": "already"}`, nil)
+
+	stateMachine := createTestStateMachine(t, awsClients)
+
+	_, err := stateMachine.Execute(release)
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"ValidatePassed", "FailureClean"}, publishedEvents(t, awsClients))
+
+	var event models.NotificationEvent
+	assert.NoError(t, json.Unmarshal([]byte(*awsClients.SNS.PublishInputs[1].Message), &event))
+	assert.Equal(t, "LockExistsError", *event.Error.Error)
+}
+
 func Test_Execution_FetchDeploy_ReleaseLockError(t *testing.T) {
 	release := models.MockRelease(t)
 
@@ -246,6 +450,24 @@ func Test_Execution_CheckHealthy_HaltError_WithTermination(t *testing.T) {
 	})
 }
 
+func Test_Execution_CheckHealthy_HaltError_Events(t *testing.T) {
+	release := models.MockRelease(t)
+	maws := models.MockAwsClients(release)
+	maws.ASG.DescribeAutoScalingGroupsPageResp = nil
+
+	termingASG := mocks.MakeMockASG("odin", *release.ProjectName, *release.ConfigName, "web", "Old release")
+	termingASG.Instances[0].LifecycleState = to.Strp("Terminating")
+	maws.ASG.AddASG(termingASG)
+
+	stateMachine := createTestStateMachine(t, maws)
+	_, err := stateMachine.Execute(release)
+	assert.Error(t, err)
+
+	events := recordedEvents(t, maws, release)
+	assert.Contains(t, events, "CheckHealthy Halted")
+	assert.Equal(t, "ReleaseLockFailure LockReleased", events[len(events)-1])
+}
+
 func Test_Execution_CheckHealthy_Never_Healthy_ELB(t *testing.T) {
 	// Should end in Alert Bad Thing Happened State
 	release := models.MockRelease(t)
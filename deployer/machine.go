@@ -182,7 +182,7 @@ func StateMachine() (*machine.StateMachine, error) {
         "Catch": [{
           "ErrorEquals": ["States.ALL"],
           "ResultPath": "$.error",
-          "Next": "FailureDirty"
+          "Next": "NotifyFailureDirty"
         }]
       },
       "DetachForFailure": {
@@ -223,7 +223,7 @@ func StateMachine() (*machine.StateMachine, error) {
         "Catch": [{
           "ErrorEquals": ["States.ALL"],
           "ResultPath": "$.error",
-          "Next": "FailureDirty"
+          "Next": "NotifyFailureDirty"
         }]
       },
       "ReleaseLockFailure": {
@@ -240,6 +240,17 @@ func StateMachine() (*machine.StateMachine, error) {
         "Catch": [{
           "ErrorEquals": ["States.ALL"],
           "ResultPath": "$.error",
+          "Next": "NotifyFailureDirty"
+        }]
+      },
+      "NotifyFailureDirty": {
+        "Type": "TaskFn",
+        "Resource": "arn:aws:lambda:{{aws_region}}:{{aws_account}}:function:{{lambda_name}}",
+        "Comment": "Send the FailureDirty notifications, they never fail",
+        "Next": "FailureDirty",
+        "Catch": [{
+          "ErrorEquals": ["States.ALL"],
+          "ResultPath": "$.notify_error",
           "Next": "FailureDirty"
         }]
       },
@@ -273,19 +284,20 @@ func TaskHandlers() *handler.TaskHandlers {
 // CreateTaskFunctinons returns
 func CreateTaskFunctinons(awsc aws.Clients) *handler.TaskHandlers {
 	tm := handler.TaskHandlers{}
-	tm["Validate"] = Validate(awsc)
-	tm["Lock"] = Lock(awsc)
-	tm["ValidateResources"] = ValidateResources(awsc)
-	tm["Deploy"] = Deploy(awsc)
-	tm["CheckHealthy"] = CheckHealthy(awsc)
+	tm["Validate"] = withEvents(awsc, "Validate", Validate(awsc))
+	tm["Lock"] = withEvents(awsc, "Lock", Lock(awsc))
+	tm["ValidateResources"] = withEvents(awsc, "ValidateResources", ValidateResources(awsc))
+	tm["Deploy"] = withEvents(awsc, "Deploy", Deploy(awsc))
+	tm["CheckHealthy"] = withEvents(awsc, "CheckHealthy", CheckHealthy(awsc))
 
 	// success
-	tm["DetachForSuccess"] = DetachForSuccess(awsc)
-	tm["CleanUpSuccess"] = CleanUpSuccess(awsc)
+	tm["DetachForSuccess"] = withEvents(awsc, "DetachForSuccess", DetachForSuccess(awsc))
+	tm["CleanUpSuccess"] = withEvents(awsc, "CleanUpSuccess", CleanUpSuccess(awsc))
 
 	// Failure
-	tm["DetachForFailure"] = DetachForFailure(awsc)
-	tm["CleanUpFailure"] = CleanUpFailure(awsc)
-	tm["ReleaseLockFailure"] = ReleaseLockFailure(awsc)
+	tm["DetachForFailure"] = withEvents(awsc, "DetachForFailure", DetachForFailure(awsc))
+	tm["CleanUpFailure"] = withEvents(awsc, "CleanUpFailure", CleanUpFailure(awsc))
+	tm["ReleaseLockFailure"] = withEvents(awsc, "ReleaseLockFailure", ReleaseLockFailure(awsc))
+	tm["NotifyFailureDirty"] = NotifyFailureDirty(awsc)
 	return &tm
 }
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/coinbase/step/utils/is"
+)
+
+// AccountOverride deploys the release to an account, replacing the values that differ between accounts
+// The deployer assumes its role in the account, so the release stays in the deployers bucket
+type AccountOverride struct {
+	AccountID *string   `json:"account_id,omitempty"`
+	Subnets   []*string `json:"subnets,omitempty"`
+	Image     *string   `json:"ami,omitempty"`
+
+	Services map[string]*ServiceOverride `json:"services,omitempty"`
+}
+
+// IsParallelAccounts returns true if all accounts are deployed at once
+func (release *Release) IsParallelAccounts() bool {
+	return release.AccountStrategy != nil && *release.AccountStrategy == "Parallel"
+}
+
+// ValidateAccounts validates the accounts the client deploys the release to
+func (release *Release) ValidateAccounts() error {
+	if err := validateStrategy("account_strategy", release.AccountStrategy); err != nil {
+		return err
+	}
+
+	accounts := map[string]bool{}
+	for _, ao := range release.Accounts {
+		if ao == nil || is.EmptyStr(ao.AccountID) {
+			return fmt.Errorf("Accounts must each have an account_id")
+		}
+
+		if accounts[*ao.AccountID] {
+			return fmt.Errorf("Account %v is defined more than once", *ao.AccountID)
+		}
+		accounts[*ao.AccountID] = true
+
+		for name := range ao.Services {
+			if _, ok := release.Services[name]; !ok {
+				return fmt.Errorf("Account %v overrides unknown service %v", *ao.AccountID, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// AccountReleases returns a copy of the release for each account, in order, with its overrides applied
+func (release *Release) AccountReleases() ([]*Release, error) {
+	if err := release.ValidateAccounts(); err != nil {
+		return nil, err
+	}
+
+	releases := []*Release{}
+	for _, ao := range release.Accounts {
+		r, err := release.copyRelease()
+		if err != nil {
+			return nil, err
+		}
+
+		r.Accounts = nil
+		r.AccountStrategy = nil
+		r.AwsAccountID = ao.AccountID
+		applyOverrides(r, ao.Subnets, ao.Image, ao.Services)
+
+		releases = append(releases, r)
+	}
+
+	return releases, nil
+}
+
+// TargetReleases returns a copy of the release for every account and region it is deployed to, in stages
+// The releases in a stage are deployed in parallel, and a stage starts once the previous stage succeeds
+func (release *Release) TargetReleases() ([][]*Release, error) {
+	accounts := []*Release{release}
+	if len(release.Accounts) > 0 {
+		var err error
+		if accounts, err = release.AccountReleases(); err != nil {
+			return nil, err
+		}
+	}
+
+	stages := [][]*Release{}
+	for a, account := range accounts {
+		regions := []*Release{account}
+		if len(account.Regions) > 0 {
+			var err error
+			if regions, err = account.RegionReleases(); err != nil {
+				return nil, err
+			}
+		}
+
+		for r, target := range regions {
+			stage := 0
+			if !release.IsParallelAccounts() {
+				stage = a * len(regions)
+			}
+			if !release.IsParallelRegions() {
+				stage += r
+			}
+
+			for len(stages) <= stage {
+				stages = append(stages, []*Release{})
+			}
+			stages[stage] = append(stages[stage], target)
+		}
+	}
+
+	// Sequential accounts with parallel regions leave stages empty
+	nonEmpty := [][]*Release{}
+	for _, stage := range stages {
+		if len(stage) > 0 {
+			nonEmpty = append(nonEmpty, stage)
+		}
+	}
+
+	return nonEmpty, nil
+}
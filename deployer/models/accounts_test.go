@@ -0,0 +1,113 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockAccounts(r *Release) {
+	r.Accounts = []*AccountOverride{
+		&AccountOverride{AccountID: to.Strp("sandbox")},
+		&AccountOverride{
+			AccountID: to.Strp("staging"),
+			Image:     to.Strp("staging-ami"),
+			Services: map[string]*ServiceOverride{
+				"web": &ServiceOverride{Profile: to.Strp("staging-profile")},
+			},
+		},
+	}
+}
+
+func stageNames(stages [][]*Release) [][]string {
+	names := [][]string{}
+	for _, stage := range stages {
+		sn := []string{}
+		for _, r := range stage {
+			sn = append(sn, *r.AwsAccountID+" "+*r.AwsRegion)
+		}
+		names = append(names, sn)
+	}
+	return names
+}
+
+func Test_Release_AccountReleases(t *testing.T) {
+	r := MockRelease(t)
+	r.SetUserData(to.Strp("#cloud_config"))
+	mockAccounts(r)
+
+	releases, err := r.AccountReleases()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(releases))
+
+	sandbox, staging := releases[0], releases[1]
+
+	assert.Equal(t, "sandbox", *sandbox.AwsAccountID)
+	assert.Equal(t, "ubuntu", *sandbox.Image)
+	assert.Equal(t, "web-profile", *sandbox.Services["web"].Profile)
+
+	assert.Equal(t, "staging", *staging.AwsAccountID)
+	assert.Equal(t, "staging-ami", *staging.Image)
+	assert.Equal(t, "staging-profile", *staging.Services["web"].Profile)
+
+	for _, rr := range releases {
+		assert.Nil(t, rr.Accounts)
+		assert.Equal(t, "bucket", *rr.Bucket)
+		assert.Equal(t, "#cloud_config", *rr.UserData())
+	}
+
+	assert.Equal(t, "000000", *r.AwsAccountID)
+}
+
+func Test_Release_ValidateAccounts(t *testing.T) {
+	r := MockRelease(t)
+	mockAccounts(r)
+	assert.NoError(t, r.ValidateAccounts())
+
+	r.AccountStrategy = to.Strp("Rolling")
+	assert.Error(t, r.ValidateAccounts())
+
+	r = MockRelease(t)
+	mockAccounts(r)
+	r.Accounts[1].AccountID = to.Strp("sandbox")
+	assert.Error(t, r.ValidateAccounts())
+
+	r = MockRelease(t)
+	mockAccounts(r)
+	r.Accounts[1].Services["worker"] = &ServiceOverride{}
+	assert.Error(t, r.ValidateAccounts())
+}
+
+func Test_Release_TargetReleases(t *testing.T) {
+	r := MockRelease(t)
+	mockAccounts(r)
+	mockRegions(r)
+
+	stages, err := r.TargetReleases()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"sandbox us-east-1"}, {"sandbox us-west-2"}, {"staging us-east-1"}, {"staging us-west-2"},
+	}, stageNames(stages))
+
+	r.RegionStrategy = to.Strp("Parallel")
+	stages, err = r.TargetReleases()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"sandbox us-east-1", "sandbox us-west-2"}, {"staging us-east-1", "staging us-west-2"},
+	}, stageNames(stages))
+
+	r.RegionStrategy = nil
+	r.AccountStrategy = to.Strp("Parallel")
+	stages, err = r.TargetReleases()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"sandbox us-east-1", "staging us-east-1"}, {"sandbox us-west-2", "staging us-west-2"},
+	}, stageNames(stages))
+
+	r.RegionStrategy = to.Strp("Parallel")
+	stages, err = r.TargetReleases()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(stages))
+	assert.Equal(t, 4, len(stages[0]))
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/utils/to"
+)
+
+// ApprovalConfig pauses every service after a rollout step until the release is continued
+type ApprovalConfig struct {
+	AfterStep *int64 `json:"after_step,omitempty"` // Starting at 1, services with fewer steps pause after their last step
+}
+
+// ValidateAttributes validates attributes
+func (a *ApprovalConfig) ValidateAttributes() error {
+	if a.AfterStep == nil || *a.AfterStep < 1 {
+		return fmt.Errorf("Approval after_step must be at least 1")
+	}
+
+	return nil
+}
+
+// validateApproval requires the service to have a rollout step to pause after
+func (service *Service) validateApproval() error {
+	if len(service.strategy.steps) == 0 {
+		return fmt.Errorf("Approval requires service %v to have rollout steps, it would launch all instances at once", *service.ServiceName)
+	}
+
+	return nil
+}
+
+// ContinuePath returns the path of the continue marker
+func (release *Release) ContinuePath() *string {
+	s := fmt.Sprintf("%v/continue", *release.ReleaseDir())
+	return &s
+}
+
+// Continue writes the continue marker to S3 to approve the release
+func (release *Release) Continue(s3c aws.S3API) error {
+	return s3.PutStr(s3c, release.Bucket, release.ContinuePath(), to.Strp("Odin client continued deploy"))
+}
+
+// UpdateApproved checks S3 for the continue marker
+func (release *Release) UpdateApproved(s3c aws.S3API) error {
+	if release.Approval == nil || release.approved {
+		return nil
+	}
+
+	_, err := s3.Get(s3c, release.Bucket, release.ContinuePath())
+	switch err.(type) {
+	case nil:
+		release.approved = true
+	case *s3.NotFoundError:
+		// Not continued yet
+	default:
+		return err
+	}
+
+	return nil
+}
+
+// approvalPending is true if the release must be continued before it is healthy
+func (service *Service) approvalPending() bool {
+	return service.release.Approval != nil && !service.release.approved
+}
+
+// pausedBefore is true if the service cannot start the rollout step until approved
+func (service *Service) pausedBefore(step int64) bool {
+	if !service.approvalPending() {
+		return false
+	}
+
+	gate := min(*service.release.Approval.AfterStep, int64(len(service.strategy.steps)))
+	return step >= gate
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Approval_ValidateAttributes(t *testing.T) {
+	assert.Error(t, (&ApprovalConfig{}).ValidateAttributes())
+	assert.Error(t, (&ApprovalConfig{AfterStep: to.Int64p(0)}).ValidateAttributes())
+	assert.NoError(t, (&ApprovalConfig{AfterStep: to.Int64p(1)}).ValidateAttributes())
+}
+
+func Test_Approval_UpdateApproved(t *testing.T) {
+	r := MockRelease(t)
+	r.Approval = &ApprovalConfig{AfterStep: to.Int64p(1)}
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+
+	assert.NoError(t, r.UpdateApproved(awsc.S3))
+	assert.False(t, r.approved)
+
+	assert.NoError(t, r.Continue(awsc.S3))
+	assert.NoError(t, r.UpdateApproved(awsc.S3))
+	assert.True(t, r.approved)
+}
+
+func Test_Approval_PausesRollout(t *testing.T) {
+	r := MockRelease(t)
+	r.Approval = &ApprovalConfig{AfterStep: to.Int64p(1)}
+	r.Services["web"].Autoscaling.Strategy = to.Strp("OneThenAllWithCanary")
+	MockPrepareRelease(r)
+
+	service := r.Services["web"]
+	service.HealthReport = &HealthReport{}
+
+	// The healthy canary does not move on until approved
+	assert.NoError(t, service.advanceRollout(nil, oneGood, time.Now()))
+	assert.EqualValues(t, 0, service.RolloutStep)
+	assert.True(t, service.HealthReport.AwaitingApproval)
+
+	min, dc := service.strategy.CalculateMinDesired(service.RolloutStep)
+	assert.EqualValues(t, 1, min)
+	assert.EqualValues(t, 1, dc)
+
+	r.approved = true
+	service.HealthReport = &HealthReport{}
+	assert.NoError(t, service.advanceRollout(nil, oneGood, time.Now()))
+	assert.EqualValues(t, 1, service.RolloutStep)
+	assert.False(t, service.HealthReport.AwaitingApproval)
+}
+
+func Test_Approval_PausesServicesWithFewerSteps(t *testing.T) {
+	r := MockRelease(t)
+	r.Approval = &ApprovalConfig{AfterStep: to.Int64p(2)}
+	r.Services["web"].Autoscaling.Strategy = to.Strp("OneThenAllWithCanary")
+	MockPrepareRelease(r)
+
+	service := r.Services["web"]
+	service.HealthReport = &HealthReport{}
+
+	// The canary is the only step so the service pauses after it
+	assert.NoError(t, service.advanceRollout(nil, oneGood, time.Now()))
+	assert.EqualValues(t, 0, service.RolloutStep)
+	assert.True(t, service.HealthReport.AwaitingApproval)
+
+	min, dc := service.strategy.CalculateMinDesired(service.RolloutStep)
+	assert.EqualValues(t, 1, min)
+	assert.EqualValues(t, 1, dc)
+}
+
+func Test_Approval_Validate_ServiceWithoutSteps(t *testing.T) {
+	r := MockRelease(t)
+	r.Approval = &ApprovalConfig{AfterStep: to.Int64p(1)}
+	awsc := MockAwsClients(r)
+	r.ReleaseSHA256 = to.SHA256Struct(r)
+	MockPrepareRelease(r)
+
+	// AllAtOnce launches the full capacity before it could pause
+	err := r.Validate(awsc.S3, awsc.KMS)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Approval requires service web to have rollout steps")
+
+	r = MockRelease(t)
+	r.Approval = &ApprovalConfig{AfterStep: to.Int64p(1)}
+	r.Services["web"].Autoscaling.Strategy = to.Strp("OneThenAllWithCanary")
+	awsc = MockAwsClients(r)
+	r.ReleaseSHA256 = to.SHA256Struct(r)
+	MockPrepareRelease(r)
+
+	assert.NoError(t, r.Validate(awsc.S3, awsc.KMS))
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/coinbase/step/utils/is"
 	"github.com/coinbase/step/utils/to"
@@ -9,24 +10,33 @@ import (
 
 // AutoScalingConfig struct
 type AutoScalingConfig struct {
-	MinSize                *int64    `json:"min_size,omitempty"`
-	MaxSize                *int64    `json:"max_size,omitempty"`
-	MaxTerminations        *int64    `json:"max_terms,omitempty"`
-	DefaultCooldown        *int64    `json:"default_cooldown,omitempty"`
-	HealthCheckGracePeriod *int64    `json:"health_check_grace_period,omitempty"`
-	Spread                 *float64  `json:"spread,omitempty"`
-	Policies               []*Policy `json:"policies,omitempty"`
-
-	Strategy *string `json:"strategy,omitempty"`
+	MinSize                *int64      `json:"min_size,omitempty"`
+	MaxSize                *int64      `json:"max_size,omitempty"`
+	MaxTerminations        *int64      `json:"max_terms,omitempty"`
+	DefaultCooldown        *int64      `json:"default_cooldown,omitempty"`
+	HealthCheckGracePeriod *int64      `json:"health_check_grace_period,omitempty"`
+	Spread                 *float64    `json:"spread,omitempty"`
+	Policies               []*Policy   `json:"policies,omitempty"`
+	Schedules              []*Schedule `json:"schedules,omitempty"`
+
+	Strategy          *string        `json:"strategy,omitempty"`
+	CanaryBakeSeconds *int64         `json:"canary_bake_seconds,omitempty"` // How long the canary must stay healthy
+	Rollout           *RolloutConfig `json:"rollout,omitempty"`             // Replaces the strategy
 }
 
 // ValidateAttributes validates attributes
 func (a *AutoScalingConfig) ValidateAttributes() error {
-	if a.Strategy == nil {
-		return fmt.Errorf("Autoscaling Strategy nil")
-	}
+	if a.Rollout != nil {
+		if a.Strategy != nil {
+			return fmt.Errorf("Autoscaling cannot have both a Strategy and a rollout")
+		}
 
-	if !containsStr(STRATEGIES, *a.Strategy) {
+		if err := a.Rollout.ValidateAttributes(); err != nil {
+			return err
+		}
+	} else if a.Strategy == nil {
+		return fmt.Errorf("Autoscaling Strategy nil")
+	} else if !containsStr(STRATEGIES, *a.Strategy) {
 		return fmt.Errorf("Autoscaling Strategy is %s but must be in %s", *a.Strategy, STRATEGIES)
 	}
 
@@ -50,7 +60,19 @@ func (a *AutoScalingConfig) ValidateAttributes() error {
 		return fmt.Errorf("Spread must be between 0 and 1")
 	}
 
+	if a.CanaryBakeSeconds != nil {
+		if to.Strs(a.Strategy) != "OneThenAllWithCanary" {
+			return fmt.Errorf("Autoscaling canary_bake_seconds requires the OneThenAllWithCanary strategy")
+		}
+
+		if *a.CanaryBakeSeconds < 0 {
+			return fmt.Errorf("Autoscaling canary_bake_seconds must be at least 0")
+		}
+	}
+
 	policyNames := []*string{}
+	trackedMetrics := []*string{}
+	tracksCPU, cpuStep := false, false
 
 	for _, p := range a.Policies {
 		if p == nil {
@@ -62,19 +84,113 @@ func (a *AutoScalingConfig) ValidateAttributes() error {
 		}
 
 		policyNames = append(policyNames, p.Name())
+
+		if p.IsTargetTracking() {
+			trackedMetrics = append(trackedMetrics, p.Metric)
+		}
+
+		tracksCPU = tracksCPU || p.TracksCPU()
+		cpuStep = cpuStep || p.IsCPUStep()
 	}
 
 	if !is.UniqueStrp(policyNames) {
 		return fmt.Errorf("Policy Names not Unique")
 	}
 
+	if !is.UniqueStrp(trackedMetrics) {
+		return fmt.Errorf("Policy target_tracking metrics not Unique")
+	}
+
+	// Both would scale on CPU and fight each other
+	if tracksCPU && cpuStep {
+		return fmt.Errorf("Policy target_tracking ASGAverageCPUUtilization cannot be used with cpu_scale_up or cpu_scale_down")
+	}
+
+	scheduleNames := []*string{}
+	for _, s := range a.Schedules {
+		if s == nil {
+			return fmt.Errorf("Schedule nil")
+		}
+
+		if err := s.ValidateAttributes(); err != nil {
+			return err
+		}
+
+		scheduled := a.Scheduled(s)
+		if *scheduled.MinSize > *scheduled.MaxSize {
+			return fmt.Errorf("Schedule(%v): min_size is greater than max_size", *s.Name)
+		}
+
+		scheduleNames = append(scheduleNames, s.Name)
+	}
+
+	if !is.UniqueStrp(scheduleNames) {
+		return fmt.Errorf("Schedule Names not Unique")
+	}
+
 	return nil
 }
 
+// ActiveSchedule returns the schedule that ran most recently before now
+func (a *AutoScalingConfig) ActiveSchedule(now time.Time) *Schedule {
+	var active *Schedule
+	var activeRun *time.Time
+
+	for _, s := range a.Schedules {
+		if s == nil {
+			continue
+		}
+
+		run := s.LastRun(now)
+		if run == nil {
+			continue
+		}
+
+		if activeRun == nil || run.After(*activeRun) {
+			active, activeRun = s, run
+		}
+	}
+
+	return active
+}
+
+// FindSchedule returns the schedule with the name
+func (a *AutoScalingConfig) FindSchedule(name *string) *Schedule {
+	if name == nil {
+		return nil
+	}
+
+	for _, s := range a.Schedules {
+		if s != nil && s.Name != nil && *s.Name == *name {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// Scheduled returns a copy of the config with the sizes the schedule sets
+func (a *AutoScalingConfig) Scheduled(s *Schedule) *AutoScalingConfig {
+	scheduled := *a
+	if s == nil {
+		return &scheduled
+	}
+
+	if s.MinSize != nil {
+		scheduled.MinSize = s.MinSize
+	}
+
+	if s.MaxSize != nil {
+		scheduled.MaxSize = s.MaxSize
+	}
+
+	return &scheduled
+}
+
 // SetDefaults assigns values
 func (a *AutoScalingConfig) SetDefaults(serviceID *string, timeout *int) error {
 
-	if a.Strategy == nil {
+	if a.Strategy == nil && a.Rollout == nil {
 		a.Strategy = to.Strp("AllAtOnce")
 	}
 
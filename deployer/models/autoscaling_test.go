@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/coinbase/step/utils/to"
 	"github.com/stretchr/testify/assert"
@@ -30,6 +31,96 @@ func Test_PolicyNames_Uniq(t *testing.T) {
 	assert.NoError(t, asg.ValidateAttributes())
 }
 
+func Test_Autoscaling_TargetTracking(t *testing.T) {
+	asg := &AutoScalingConfig{
+		Policies: []*Policy{
+			&Policy{Type: to.Strp("target_tracking"), Metric: to.Strp("ASGAverageCPUUtilization"), TargetValue: to.Float64p(50)},
+			&Policy{Type: to.Strp("target_tracking"), Metric: to.Strp("ASGAverageNetworkIn"), TargetValue: to.Float64p(1000), NameVal: to.Strp("network")},
+		},
+	}
+	asg.SetDefaults(to.Strp("service_id"), nil)
+	assert.NoError(t, asg.ValidateAttributes())
+
+	// Only one policy can track a metric
+	asg.Policies[1].Metric = to.Strp("ASGAverageCPUUtilization")
+	assert.Error(t, asg.ValidateAttributes())
+
+	// CPU target tracking and cpu step policies conflict
+	asg.Policies[1] = &Policy{Type: to.Strp("cpu_scale_up")}
+	asg.SetDefaults(to.Strp("service_id"), nil)
+	assert.Error(t, asg.ValidateAttributes())
+}
+
+func Test_Autoscaling_Schedules(t *testing.T) {
+	asg := &AutoScalingConfig{
+		MinSize: to.Int64p(2),
+		MaxSize: to.Int64p(10),
+		Schedules: []*Schedule{
+			&Schedule{Name: to.Strp("peak"), Recurrence: to.Strp("0 8 * * *"), MinSize: to.Int64p(6), MaxSize: to.Int64p(20)},
+			&Schedule{Name: to.Strp("offpeak"), Recurrence: to.Strp("0 20 * * *"), MinSize: to.Int64p(2)},
+		},
+	}
+	asg.SetDefaults(to.Strp("service_id"), nil)
+	assert.NoError(t, asg.ValidateAttributes())
+
+	assert.Equal(t, "peak", *asg.ActiveSchedule(time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)).Name)
+	assert.Equal(t, "offpeak", *asg.ActiveSchedule(time.Date(2020, 6, 1, 7, 0, 0, 0, time.UTC)).Name)
+
+	scheduled := asg.Scheduled(asg.FindSchedule(to.Strp("peak")))
+	assert.Equal(t, int64(6), *scheduled.MinSize)
+	assert.Equal(t, int64(20), *scheduled.MaxSize)
+	assert.Equal(t, int64(2), *asg.MinSize)
+
+	// Scheduled min above the configured max
+	asg.Schedules[1].MinSize = to.Int64p(11)
+	assert.Error(t, asg.ValidateAttributes())
+
+	asg.Schedules[1].MinSize = to.Int64p(2)
+	asg.Schedules[1].Name = to.Strp("peak")
+	assert.Error(t, asg.ValidateAttributes())
+}
+
+func Test_Autoscaling_CanaryBakeSeconds(t *testing.T) {
+	asg := &AutoScalingConfig{CanaryBakeSeconds: to.Int64p(600)}
+	asg.SetDefaults(nil, nil)
+	assert.Error(t, asg.ValidateAttributes())
+
+	asg.Strategy = to.Strp("OneThenAllWithCanary")
+	assert.NoError(t, asg.ValidateAttributes())
+
+	asg.CanaryBakeSeconds = to.Int64p(-1)
+	assert.Error(t, asg.ValidateAttributes())
+}
+
+func Test_Autoscaling_Rollout(t *testing.T) {
+	asg := &AutoScalingConfig{
+		Rollout: &RolloutConfig{
+			Steps: []*RolloutStep{
+				&RolloutStep{Count: to.Int64p(1), WaitSeconds: to.Int64p(300)},
+				&RolloutStep{Percent: to.Float64p(25)},
+			},
+		},
+	}
+	asg.SetDefaults(nil, nil)
+	assert.Nil(t, asg.Strategy)
+	assert.NoError(t, asg.ValidateAttributes())
+
+	asg.Rollout.Steps[1].Count = to.Int64p(2)
+	assert.Error(t, asg.ValidateAttributes())
+
+	asg.Rollout.Steps[1].Count = nil
+	asg.Rollout.Steps[1].Percent = to.Float64p(101)
+	assert.Error(t, asg.ValidateAttributes())
+
+	asg.Rollout.Steps[1].Percent = to.Float64p(25)
+	asg.Strategy = to.Strp("AllAtOnce")
+	assert.Error(t, asg.ValidateAttributes())
+
+	asg.Strategy = nil
+	asg.Rollout.Steps = nil
+	assert.Error(t, asg.ValidateAttributes())
+}
+
 func Test_Autoscaling_HealthCheckGracePeriod(t *testing.T) {
 	asg := &AutoScalingConfig{}
 	assert.Nil(t, asg.HealthCheckGracePeriod)
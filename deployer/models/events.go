@@ -0,0 +1,125 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/errors"
+	"github.com/coinbase/step/utils/is"
+)
+
+// AuditEvent is a line in the releases events.jsonl
+type AuditEvent struct {
+	Time    time.Time   `json:"time"`
+	State   string      `json:"state,omitempty"` // The handler that recorded the event
+	Type    string      `json:"type"`
+	Service *string     `json:"service,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// EventsPath returns the path of the releases audit log
+func (release *Release) EventsPath() *string {
+	s := fmt.Sprintf("%v/events.jsonl", *release.ReleaseDir())
+	return &s
+}
+
+// RecordEvent buffers an event until WriteEvents appends it to the audit log
+func (release *Release) RecordEvent(eventType string, service *string, message string, details interface{}) {
+	release.events = append(release.events, &AuditEvent{
+		Time:    time.Now().UTC(),
+		Type:    eventType,
+		Service: service,
+		Message: message,
+		Details: details,
+	})
+}
+
+// RecordError records a handlers error, halts are recorded as Halted
+func (release *Release) RecordError(err error) {
+	eventType := "Error"
+	switch err.(type) {
+	case *errors.HaltError, *HaltError:
+		eventType = "Halted"
+	}
+
+	release.RecordEvent(eventType, nil, err.Error(), nil)
+}
+
+// WriteEvents appends the buffered events recorded by state to the audit log
+func (release *Release) WriteEvents(s3c aws.S3API, state string) error {
+	if len(release.events) == 0 {
+		return nil
+	}
+
+	// A release that failed validation might not have its paths
+	if is.EmptyStr(release.AwsAccountID) || is.EmptyStr(release.ProjectName) || is.EmptyStr(release.ConfigName) || is.EmptyStr(release.ReleaseID) || is.EmptyStr(release.Bucket) {
+		return fmt.Errorf("Release has no events path")
+	}
+
+	existing, err := s3.GetStr(s3c, release.Bucket, release.EventsPath())
+	switch err.(type) {
+	case nil:
+	case *s3.NotFoundError:
+		existing = nil
+	default:
+		return err
+	}
+
+	lines := []string{}
+	if existing != nil && *existing != "" {
+		lines = append(lines, strings.TrimRight(*existing, "\n"))
+	}
+
+	for _, event := range release.events {
+		event.State = state
+		line, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, string(line))
+	}
+
+	content := strings.Join(lines, "\n") + "\n"
+	if err := s3.PutStr(s3c, release.Bucket, release.EventsPath(), &content); err != nil {
+		return err
+	}
+
+	release.events = nil
+	return nil
+}
+
+// ReadEvents returns the events in the releases audit log
+func (release *Release) ReadEvents(s3c aws.S3API) ([]*AuditEvent, error) {
+	raw, err := s3.GetStr(s3c, release.Bucket, release.EventsPath())
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*AuditEvent{}
+	for _, line := range strings.Split(*raw, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		var event AuditEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+
+	return events, nil
+}
+
+// recordEvent records an event on the services release
+func (service *Service) recordEvent(eventType string, message string, details interface{}) {
+	if service.release == nil {
+		return
+	}
+	service.release.RecordEvent(eventType, service.ServiceName, message, details)
+}
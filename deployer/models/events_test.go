@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/coinbase/odin/aws/asg"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/errors"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Release_WriteEvents_Appends(t *testing.T) {
+	r := MockRelease(t)
+	awsc := MockAwsClients(r)
+
+	// Nothing recorded, nothing written
+	assert.NoError(t, r.WriteEvents(awsc.S3, "Validate"))
+	_, err := r.ReadEvents(awsc.S3)
+	assert.Error(t, err)
+
+	r.RecordEvent("Validated", nil, "", nil)
+	assert.NoError(t, r.WriteEvents(awsc.S3, "Validate"))
+
+	r.RecordEvent("ASGCreated", to.Strp("web"), "web-asg", nil)
+	r.RecordError(&errors.HaltError{Cause: "halt"})
+	r.RecordError(fmt.Errorf("boom"))
+	assert.NoError(t, r.WriteEvents(awsc.S3, "Deploy"))
+
+	raw, err := s3.GetStr(awsc.S3, r.Bucket, r.EventsPath())
+	assert.NoError(t, err)
+	assert.Equal(t, 4, strings.Count(*raw, "\n"))
+
+	events, err := r.ReadEvents(awsc.S3)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(events))
+
+	assert.Equal(t, "Validate", events[0].State)
+	assert.Equal(t, "Validated", events[0].Type)
+
+	assert.Equal(t, "Deploy", events[1].State)
+	assert.Equal(t, "ASGCreated", events[1].Type)
+	assert.Equal(t, "web", *events[1].Service)
+	assert.Equal(t, "web-asg", events[1].Message)
+
+	assert.Equal(t, "Halted", events[2].Type)
+	assert.Equal(t, "Error", events[3].Type)
+	assert.Equal(t, "boom", events[3].Message)
+}
+
+func Test_Release_WriteEvents_NoPath(t *testing.T) {
+	r := &Release{}
+	r.RecordEvent("Error", nil, "bad release", nil)
+	assert.Error(t, r.WriteEvents(mocks.MockAWS().S3, "Validate"))
+}
+
+func Test_Service_SafeSetMinDesiredCapacity_Event(t *testing.T) {
+	r := MockRelease(t)
+	r.SetDefaults()
+	awsc := MockAwsClients(r)
+
+	service := r.Services["web"]
+	service.CreatedASG = to.Strp("web-asg")
+	group := &asg.ASG{MinSize: to.Int64p(2), DesiredCapacity: to.Int64p(2)}
+
+	assert.NoError(t, service.SafeSetMinDesiredCapacity(awsc.ASG, group, 2, 2))
+	assert.NoError(t, service.SafeSetMinDesiredCapacity(awsc.ASG, group, 3, 4))
+	assert.NoError(t, r.WriteEvents(awsc.S3, "CheckHealthy"))
+
+	events, err := r.ReadEvents(awsc.S3)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, "CapacityChanged", events[0].Type)
+	assert.Equal(t, "web-asg", events[0].Message)
+	assert.Equal(t, map[string]interface{}{
+		"previous_min_size":         float64(2),
+		"previous_desired_capacity": float64(2),
+		"min_size":                  float64(3),
+		"desired_capacity":          float64(4),
+	}, events[0].Details)
+}
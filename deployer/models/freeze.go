@@ -0,0 +1,195 @@
+package models
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/utils/is"
+	"github.com/coinbase/step/utils/to"
+)
+
+// FreezeRules are the deploy freezes for all project configs deployed from the bucket
+type FreezeRules struct {
+	Freezes []*FreezeRule `json:"freezes"`
+}
+
+// FreezeRule blocks deploys of matching project configs for a time range or a recurring window
+type FreezeRule struct {
+	Name        *string `json:"name,omitempty"`
+	ProjectName *string `json:"project_name,omitempty"` // Glob of project names e.g. "coinbase/*" or "*"
+	ConfigName  *string `json:"config_name,omitempty"`  // Glob of config names e.g. "production"
+	Reason      *string `json:"reason,omitempty"`
+
+	// A time range
+	Start *time.Time `json:"start,omitempty"`
+	End   *time.Time `json:"end,omitempty"`
+
+	// Or a window starting at each cron time
+	Cron     *string `json:"cron,omitempty"`
+	Duration *string `json:"duration,omitempty"`  // e.g. "12h"
+	TimeZone *string `json:"time_zone,omitempty"` // Default UTC
+}
+
+// FreezeOverride records the freezes a release was deployed through
+type FreezeOverride struct {
+	Justification *string    `json:"justification,omitempty"`
+	Freezes       []string   `json:"freezes,omitempty"` // "<name>: <reason>" of each active freeze
+	Time          *time.Time `json:"time,omitempty"`
+}
+
+// ValidateAttributes validates attributes
+func (f *FreezeRule) ValidateAttributes() error {
+	if is.EmptyStr(f.Name) {
+		return fmt.Errorf("Freeze(?): name nil")
+	}
+
+	if is.EmptyStr(f.ProjectName) || is.EmptyStr(f.ConfigName) {
+		return fmt.Errorf("Freeze(%v): project_name and config_name required, \"*\" matches all", *f.Name)
+	}
+
+	for _, glob := range []*string{f.ProjectName, f.ConfigName} {
+		if _, err := globMatch(*glob, "name"); err != nil {
+			return fmt.Errorf("Freeze(%v): %v %v", *f.Name, *glob, err.Error())
+		}
+	}
+
+	if is.EmptyStr(f.Reason) {
+		return fmt.Errorf("Freeze(%v): reason nil", *f.Name)
+	}
+
+	isRange := f.Start != nil || f.End != nil
+	isCron := f.Cron != nil || f.Duration != nil
+
+	switch {
+	case isRange && isCron:
+		return fmt.Errorf("Freeze(%v): either start and end or cron and duration, not both", *f.Name)
+	case isRange:
+		if f.Start == nil || f.End == nil || !f.End.After(*f.Start) {
+			return fmt.Errorf("Freeze(%v): end must be after start", *f.Name)
+		}
+	case isCron:
+		if _, err := f.schedule().schedule(); err != nil {
+			return fmt.Errorf("Freeze(%v): %v", *f.Name, err.Error())
+		}
+
+		if d, err := f.duration(); err != nil || d <= 0 {
+			return fmt.Errorf("Freeze(%v): duration must be a positive duration e.g. \"12h\"", *f.Name)
+		}
+	default:
+		return fmt.Errorf("Freeze(%v): start and end or cron and duration required", *f.Name)
+	}
+
+	return nil
+}
+
+// Matches returns true if the freeze applies to the project config
+func (f *FreezeRule) Matches(projectName string, configName string) bool {
+	projectOK, err := globMatch(to.Strs(f.ProjectName), projectName)
+	if err != nil || !projectOK {
+		return false
+	}
+
+	configOK, err := globMatch(to.Strs(f.ConfigName), configName)
+	return err == nil && configOK
+}
+
+// globMatch is path.Match except * also matches the / in "org/repo" project names
+func globMatch(pattern string, name string) (bool, error) {
+	return path.Match(strings.Replace(pattern, "/", "\x00", -1), strings.Replace(name, "/", "\x00", -1))
+}
+
+// IsActive returns true if now is within the freezes time range or a window
+func (f *FreezeRule) IsActive(now time.Time) bool {
+	if f.Start != nil && f.End != nil {
+		return !now.Before(*f.Start) && now.Before(*f.End)
+	}
+
+	// The most recent window ends the latest
+	last := f.schedule().LastRun(now)
+	d, err := f.duration()
+	if last == nil || err != nil {
+		return false
+	}
+
+	return now.Before(last.Add(d))
+}
+
+func (f *FreezeRule) schedule() *Schedule {
+	return &Schedule{Name: f.Name, Recurrence: f.Cron, TimeZone: f.TimeZone}
+}
+
+func (f *FreezeRule) duration() (time.Duration, error) {
+	return time.ParseDuration(to.Strs(f.Duration))
+}
+
+// FreezeRulesPath returns the path of the freeze rules, at the root of the bucket as they apply to every project
+func (release *Release) FreezeRulesPath() *string {
+	return to.Strp("freeze_rules.json")
+}
+
+// ActiveFreezes returns the freezes that block deploying the release now
+func (release *Release) ActiveFreezes(s3c aws.S3API, now time.Time) ([]*FreezeRule, error) {
+	var rules FreezeRules
+	err := s3.GetStruct(s3c, release.Bucket, release.FreezeRulesPath(), &rules)
+
+	switch err.(type) {
+	case nil:
+	case *s3.NotFoundError:
+		return nil, nil
+	default:
+		return nil, err
+	}
+
+	active := []*FreezeRule{}
+	for _, rule := range rules.Freezes {
+		if rule == nil {
+			return nil, fmt.Errorf("Freeze(?): nil")
+		}
+
+		// An invalid rule could be hiding a freeze
+		if err := rule.ValidateAttributes(); err != nil {
+			return nil, err
+		}
+
+		if rule.Matches(*release.ProjectName, *release.ConfigName) && rule.IsActive(now) {
+			active = append(active, rule)
+		}
+	}
+
+	return active, nil
+}
+
+// ValidateFreeze rejects the release during an active freeze unless it has an override_freeze justification
+func (release *Release) ValidateFreeze(s3c aws.S3API, now time.Time) error {
+	active, err := release.ActiveFreezes(s3c, now)
+	if err != nil {
+		return fmt.Errorf("Error Getting freeze rules with %v", err.Error())
+	}
+
+	if len(active) == 0 {
+		return nil
+	}
+
+	freezes := []string{}
+	for _, rule := range active {
+		freezes = append(freezes, fmt.Sprintf("%v: %v", *rule.Name, *rule.Reason))
+	}
+
+	if is.EmptyStr(release.OverrideFreeze) {
+		return fmt.Errorf("Deploys are frozen (%v), set override_freeze to deploy anyway", strings.Join(freezes, ", "))
+	}
+
+	release.FreezeOverride = &FreezeOverride{
+		Justification: release.OverrideFreeze,
+		Freezes:       freezes,
+		Time:          to.Timep(now.UTC()),
+	}
+
+	release.RecordEvent("FreezeOverridden", nil, *release.OverrideFreeze, release.FreezeOverride)
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FreezeRule_ValidateAttributes(t *testing.T) {
+	start := time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC)
+	end := start.Add(14 * 24 * time.Hour)
+
+	valid := func() *FreezeRule {
+		return &FreezeRule{Name: to.Strp("holidays"), ProjectName: to.Strp("*"), ConfigName: to.Strp("production"), Reason: to.Strp("Holidays"), Start: &start, End: &end}
+	}
+
+	assert.NoError(t, valid().ValidateAttributes())
+
+	f := valid()
+	f.Start, f.End = nil, nil
+	f.Cron, f.Duration = to.Strp("0 17 * * FRI"), to.Strp("64h")
+	assert.NoError(t, f.ValidateAttributes())
+
+	f.Duration = to.Strp("forever")
+	assert.Error(t, f.ValidateAttributes())
+
+	f.Duration, f.Cron = to.Strp("1h"), to.Strp("not cron")
+	assert.Error(t, f.ValidateAttributes())
+
+	f = valid()
+	f.Cron = to.Strp("0 17 * * FRI")
+	assert.Error(t, f.ValidateAttributes()) // Both a range and a cron
+
+	f = valid()
+	f.End = &start
+	assert.Error(t, f.ValidateAttributes())
+
+	f = valid()
+	f.Start, f.End = nil, nil
+	assert.Error(t, f.ValidateAttributes())
+
+	f = valid()
+	f.Reason = nil
+	assert.Error(t, f.ValidateAttributes())
+
+	f = valid()
+	f.ProjectName = to.Strp("[")
+	assert.Error(t, f.ValidateAttributes())
+
+	f = valid()
+	f.ConfigName = nil
+	assert.Error(t, f.ValidateAttributes())
+}
+
+func Test_FreezeRule_Matches(t *testing.T) {
+	f := &FreezeRule{ProjectName: to.Strp("*"), ConfigName: to.Strp("production")}
+	assert.True(t, f.Matches("payments", "production"))
+	assert.False(t, f.Matches("payments", "staging"))
+
+	f.ProjectName, f.ConfigName = to.Strp("payments"), to.Strp("*")
+	assert.True(t, f.Matches("payments", "staging"))
+	assert.False(t, f.Matches("web", "staging"))
+}
+
+func Test_FreezeRule_Matches_OrgProjectName(t *testing.T) {
+	// * matches the / in "org/repo" project names
+	f := &FreezeRule{ProjectName: to.Strp("*"), ConfigName: to.Strp("production")}
+	assert.True(t, f.Matches("coinbase/deploy-test", "production"))
+	assert.False(t, f.Matches("coinbase/deploy-test", "development"))
+
+	f.ProjectName = to.Strp("coinbase/*")
+	assert.True(t, f.Matches("coinbase/deploy-test", "production"))
+	assert.False(t, f.Matches("other/deploy-test", "production"))
+
+	f.ProjectName = to.Strp("*/deploy-test")
+	assert.True(t, f.Matches("coinbase/deploy-test", "production"))
+
+	f.ProjectName = to.Strp("coinbase/deploy-test")
+	assert.True(t, f.Matches("coinbase/deploy-test", "production"))
+	assert.False(t, f.Matches("coinbase/deploy-test-2", "production"))
+}
+
+func Test_FreezeRule_IsActive(t *testing.T) {
+	start := time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	f := &FreezeRule{Start: &start, End: &end}
+	assert.False(t, f.IsActive(start.Add(-time.Second)))
+	assert.True(t, f.IsActive(start))
+	assert.True(t, f.IsActive(end.Add(-time.Second)))
+	assert.False(t, f.IsActive(end))
+
+	// Friday 17:00 to Monday 09:00
+	f = &FreezeRule{Cron: to.Strp("0 17 * * FRI"), Duration: to.Strp("64h")}
+	friday := time.Date(2026, 10, 16, 17, 0, 0, 0, time.UTC)
+	assert.False(t, f.IsActive(friday.Add(-time.Minute)))
+	assert.True(t, f.IsActive(friday))
+	assert.True(t, f.IsActive(friday.Add(63*time.Hour)))
+	assert.False(t, f.IsActive(friday.Add(64*time.Hour)))
+
+	// In the freezes time zone
+	f.TimeZone = to.Strp("America/New_York")
+	assert.False(t, f.IsActive(friday))
+	assert.True(t, f.IsActive(friday.Add(4*time.Hour)))
+}
+
+func Test_Release_Validate_Freeze(t *testing.T) {
+	r := MockRelease(t)
+	awsc := MockAwsClients(r)
+	r.ReleaseSHA256 = to.SHA256Struct(r)
+	MockPrepareRelease(r)
+
+	// No freeze rules
+	assert.NoError(t, r.Validate(awsc.S3, awsc.KMS))
+
+	awsc.S3.AddGetObject(*r.FreezeRulesPath(), `{"freezes": [
+		{"name": "other", "project_name": "other", "config_name": "*", "reason": "Other", "cron": "* * * * *", "duration": "1h"},
+		{"name": "always", "project_name": "project", "config_name": "*", "reason": "Incident", "cron": "* * * * *", "duration": "1h"}
+	]}`, nil)
+
+	err := r.Validate(awsc.S3, awsc.KMS)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Deploys are frozen (always: Incident)")
+	assert.Nil(t, r.FreezeOverride)
+
+	// A justification overrides the freeze and is recorded
+	now := time.Now()
+	r.OverrideFreeze = to.Strp("Fixing the incident")
+	assert.NoError(t, r.ValidateFreeze(awsc.S3, now))
+	assert.Equal(t, "Fixing the incident", *r.FreezeOverride.Justification)
+	assert.Equal(t, []string{"always: Incident"}, r.FreezeOverride.Freezes)
+	assert.Equal(t, "FreezeOverridden", r.events[len(r.events)-1].Type)
+
+	r.WipeControlledValues()
+	assert.Nil(t, r.FreezeOverride)
+
+	// Invalid rules reject every release
+	awsc.S3.AddGetObject(*r.FreezeRulesPath(), `{"freezes": [{"name": "bad", "project_name": "*", "config_name": "*"}]}`, nil)
+	err = r.ValidateFreeze(awsc.S3, now)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Error Getting freeze rules")
+}
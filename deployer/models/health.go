@@ -0,0 +1,157 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/aws/alb"
+	"github.com/coinbase/odin/aws/elb"
+	"github.com/coinbase/step/utils/to"
+)
+
+// HealthConfig are extra checks for a service to be healthy
+type HealthConfig struct {
+	ErrorRate *ErrorRateConfig `json:"error_rate,omitempty"`
+}
+
+// ErrorRateConfig halts the release if the share of 5xx responses
+// of the services ELBs or target groups is above Threshold for Breaches checks in a row
+type ErrorRateConfig struct {
+	Threshold   *float64 `json:"threshold,omitempty"`    // Between 0 and 1
+	Period      *int64   `json:"period,omitempty"`       // Seconds of metrics to check
+	Breaches    *int64   `json:"breaches,omitempty"`     // Consecutive checks above threshold to halt
+	MinRequests *float64 `json:"min_requests,omitempty"` // Fewer requests are not checked
+}
+
+// SetDefaults assigns default values
+func (h *HealthConfig) SetDefaults() {
+	if h.ErrorRate == nil {
+		return
+	}
+
+	if h.ErrorRate.Period == nil {
+		h.ErrorRate.Period = to.Int64p(60)
+	}
+
+	if h.ErrorRate.Breaches == nil {
+		h.ErrorRate.Breaches = to.Int64p(3)
+	}
+
+	if h.ErrorRate.MinRequests == nil {
+		h.ErrorRate.MinRequests = to.Float64p(0)
+	}
+}
+
+// ValidateAttributes validates attributes
+func (h *HealthConfig) ValidateAttributes() error {
+	er := h.ErrorRate
+	if er == nil {
+		return nil
+	}
+
+	if er.Threshold == nil || *er.Threshold <= 0 || *er.Threshold > 1 {
+		return fmt.Errorf("Health error_rate threshold must be greater than 0 and at most 1")
+	}
+
+	if er.Period == nil || *er.Period < 60 || *er.Period%60 != 0 {
+		return fmt.Errorf("Health error_rate period must be a multiple of 60")
+	}
+
+	if er.Breaches == nil || *er.Breaches < 1 {
+		return fmt.Errorf("Health error_rate breaches must be at least 1")
+	}
+
+	if er.MinRequests == nil || *er.MinRequests < 0 {
+		return fmt.Errorf("Health error_rate min_requests must be at least 0")
+	}
+
+	return nil
+}
+
+// errorRate returns the highest error rate of the services ELBs and target groups, including the traffic shift target group
+// it is nil if none received min_requests
+func (service *Service) errorRate(cwc aws.CWAPI, now time.Time) (*float64, error) {
+	er := service.Health.ErrorRate
+	start := now.Add(-time.Duration(*er.Period) * time.Second)
+
+	var rate *float64
+	addRate := func(errors float64, requests float64) {
+		if requests == 0 || requests < *er.MinRequests {
+			return
+		}
+
+		if r := errors / requests; rate == nil || r > *rate {
+			rate = &r
+		}
+	}
+
+	for _, name := range service.Resources.ELBs {
+		errors, requests, err := elb.ErrorCounts(cwc, name, start, now)
+		if err != nil {
+			return nil, err
+		}
+		addRate(errors, requests)
+	}
+
+	for _, tg := range service.TargetGroups {
+		label := service.Resources.TargetGroupResourceLabels[to.Strs(tg)]
+		if label == nil {
+			return nil, fmt.Errorf("Target group %v has no resource label", to.Strs(tg))
+		}
+
+		errors, requests, err := alb.ErrorCounts(cwc, label, start, now)
+		if err != nil {
+			return nil, err
+		}
+		addRate(errors, requests)
+	}
+
+	// The traffic shift target group the new release is attached to
+	if newTG := service.Resources.TrafficNewTargetGroup; newTG != nil {
+		label := service.Resources.TrafficNewTargetGroupResourceLabel
+		if label == nil {
+			return nil, fmt.Errorf("Target group %v has no resource label", *newTG)
+		}
+
+		errors, requests, err := alb.ErrorCounts(cwc, label, start, now)
+		if err != nil {
+			return nil, err
+		}
+		addRate(errors, requests)
+	}
+
+	return rate, nil
+}
+
+// checkErrorRate records a breach if the error rate is above the threshold
+// and returns a HaltError once there are too many in a row
+func (service *Service) checkErrorRate(cwc aws.CWAPI, now time.Time) error {
+	if service.Health == nil || service.Health.ErrorRate == nil {
+		return nil
+	}
+
+	rate, err := service.errorRate(cwc, now)
+	if err != nil {
+		return err // This might retry
+	}
+
+	if service.HealthReport != nil {
+		service.HealthReport.ErrorRate = rate
+	}
+
+	er := service.Health.ErrorRate
+	if rate == nil || *rate <= *er.Threshold {
+		service.ErrorRateBreaches = 0
+		return nil
+	}
+
+	service.ErrorRateBreaches++
+
+	if service.ErrorRateBreaches >= *er.Breaches {
+		err := fmt.Errorf("Error rate %.4f above %v for %v checks %v", *rate, *er.Threshold, service.ErrorRateBreaches, *service.ServiceName)
+		return &HaltError{err}
+	}
+
+	return nil
+}
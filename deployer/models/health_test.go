@@ -0,0 +1,114 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func errorRateService(t *testing.T) *Service {
+	service := &Service{
+		TargetGroups: []*string{to.Strp("tg")},
+		Health: &HealthConfig{
+			ErrorRate: &ErrorRateConfig{Threshold: to.Float64p(0.05), Breaches: to.Int64p(2), MinRequests: to.Float64p(10)},
+		},
+	}
+	service.SetDefaults(MockMinimalRelease(t), "web")
+
+	service.Resources = &ServiceResourceNames{
+		ELBs: []*string{to.Strp("elb")},
+		TargetGroupResourceLabels: map[string]*string{
+			"tg": to.Strp("app/lb/1234/targetgroup/tg/5678"),
+		},
+	}
+
+	return service
+}
+
+func Test_HealthConfig_Validate(t *testing.T) {
+	h := &HealthConfig{ErrorRate: &ErrorRateConfig{Threshold: to.Float64p(0.05)}}
+	h.SetDefaults()
+	assert.NoError(t, h.ValidateAttributes())
+	assert.Equal(t, int64(60), *h.ErrorRate.Period)
+	assert.Equal(t, int64(3), *h.ErrorRate.Breaches)
+
+	h.ErrorRate.Threshold = to.Float64p(5)
+	assert.Error(t, h.ValidateAttributes())
+
+	h.ErrorRate.Threshold = to.Float64p(0.05)
+	h.ErrorRate.Period = to.Int64p(90)
+	assert.Error(t, h.ValidateAttributes())
+
+	h.ErrorRate.Period = to.Int64p(120)
+	h.ErrorRate.Breaches = to.Int64p(0)
+	assert.Error(t, h.ValidateAttributes())
+}
+
+func Test_Service_CheckErrorRate(t *testing.T) {
+	service := errorRateService(t)
+	cwc := &mocks.CWClient{}
+
+	// No requests is not a breach
+	assert.NoError(t, service.checkErrorRate(cwc, time.Now()))
+	assert.Equal(t, int64(0), service.ErrorRateBreaches)
+
+	// Target group metrics are read with the load balancer
+	input := cwc.GetMetricStatisticsInputs[len(cwc.GetMetricStatisticsInputs)-1]
+	assert.Equal(t, "AWS/ApplicationELB", *input.Namespace)
+	assert.Equal(t, 2, len(input.Dimensions))
+
+	cwc.AddMetricSum("RequestCount", 100)
+	cwc.AddMetricSum("HTTPCode_Target_5XX_Count", 10)
+
+	assert.NoError(t, service.checkErrorRate(cwc, time.Now()))
+	assert.Equal(t, int64(1), service.ErrorRateBreaches)
+
+	// Recovering resets the breaches
+	cwc.AddMetricSum("HTTPCode_Target_5XX_Count", 1)
+	assert.NoError(t, service.checkErrorRate(cwc, time.Now()))
+	assert.Equal(t, int64(0), service.ErrorRateBreaches)
+
+	// Breaches in a row halt
+	cwc.AddMetricSum("HTTPCode_Backend_5XX", 50)
+	assert.NoError(t, service.checkErrorRate(cwc, time.Now()))
+
+	err := service.checkErrorRate(cwc, time.Now())
+	assert.Error(t, err)
+	assert.IsType(t, &HaltError{}, err)
+}
+
+func Test_Service_CheckErrorRate_TrafficNewTargetGroup(t *testing.T) {
+	service := errorRateService(t)
+	service.Resources.TrafficNewTargetGroup = to.Strp("arn:aws:elasticloadbalancing:region:account:targetgroup/green/9012")
+	cwc := &mocks.CWClient{}
+
+	// The new target group has no resource label
+	assert.Error(t, service.checkErrorRate(cwc, time.Now()))
+
+	service.Resources.TrafficNewTargetGroupResourceLabel = to.Strp("app/lb/1234/targetgroup/green/9012")
+	assert.NoError(t, service.checkErrorRate(cwc, time.Now()))
+
+	found := false
+	for _, input := range cwc.GetMetricStatisticsInputs {
+		for _, d := range input.Dimensions {
+			if *d.Value == "targetgroup/green/9012" {
+				found = true
+			}
+		}
+	}
+	assert.True(t, found)
+}
+
+func Test_Service_CheckErrorRate_MinRequests(t *testing.T) {
+	service := errorRateService(t)
+	cwc := &mocks.CWClient{}
+
+	cwc.AddMetricSum("RequestCount", 5)
+	cwc.AddMetricSum("HTTPCode_Backend_5XX", 5)
+
+	assert.NoError(t, service.checkErrorRate(cwc, time.Now()))
+	assert.Equal(t, int64(0), service.ErrorRateBreaches)
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/coinbase/step/utils/is"
+	"github.com/coinbase/step/utils/to"
+)
+
+var SPOT_ALLOCATION_STRATEGIES = []string{
+	"lowest-price",
+	"capacity-optimized",
+}
+
+// InstancesConfig describes the mix of instance types and purchase options for a service
+// With weights the autoscaling sizes are capacity units instead of instance counts
+type InstancesConfig struct {
+	Types []*InstanceTypeConfig `json:"types,omitempty"`
+
+	OnDemandBaseCapacity                *int64  `json:"on_demand_base_capacity,omitempty"`
+	OnDemandPercentageAboveBaseCapacity *int64  `json:"on_demand_percentage_above_base_capacity,omitempty"`
+	SpotAllocationStrategy              *string `json:"spot_allocation_strategy,omitempty"`
+}
+
+// InstanceTypeConfig is an allowed instance type and the capacity units it provides
+type InstanceTypeConfig struct {
+	InstanceType *string `json:"instance_type,omitempty"`
+	Weight       *int64  `json:"weight,omitempty"`
+}
+
+// ValidateAttributes validates attributes
+func (ic *InstancesConfig) ValidateAttributes() error {
+	if len(ic.Types) < 1 {
+		return fmt.Errorf("Instances must include at least one type")
+	}
+
+	instanceTypes := []*string{}
+	for _, t := range ic.Types {
+		if t == nil {
+			return fmt.Errorf("Instances type nil")
+		}
+
+		if is.EmptyStr(t.InstanceType) {
+			return fmt.Errorf("Instances instance_type must be defined")
+		}
+
+		if t.Weight != nil && (*t.Weight < 1 || *t.Weight > 999) {
+			return fmt.Errorf("Instances weight for %v must be between 1 and 999", *t.InstanceType)
+		}
+
+		instanceTypes = append(instanceTypes, t.InstanceType)
+	}
+
+	if !is.UniqueStrp(instanceTypes) {
+		return fmt.Errorf("Instances types must be unique")
+	}
+
+	if ic.OnDemandBaseCapacity != nil && *ic.OnDemandBaseCapacity < 0 {
+		return fmt.Errorf("Instances on_demand_base_capacity must not be negative")
+	}
+
+	if ic.OnDemandPercentageAboveBaseCapacity != nil {
+		p := *ic.OnDemandPercentageAboveBaseCapacity
+		if p < 0 || p > 100 {
+			return fmt.Errorf("Instances on_demand_percentage_above_base_capacity must be between 0 and 100")
+		}
+	}
+
+	if ic.SpotAllocationStrategy != nil && !containsStr(SPOT_ALLOCATION_STRATEGIES, *ic.SpotAllocationStrategy) {
+		return fmt.Errorf("Instances spot_allocation_strategy is %s but must be in %s", *ic.SpotAllocationStrategy, SPOT_ALLOCATION_STRATEGIES)
+	}
+
+	return nil
+}
+
+// DefaultInstanceType is the type the launch template is created with
+func (ic *InstancesConfig) DefaultInstanceType() *string {
+	if len(ic.Types) == 0 || ic.Types[0] == nil {
+		return nil
+	}
+	return ic.Types[0].InstanceType
+}
+
+// TypeWeights returns the weight of each instance type
+func (ic *InstancesConfig) TypeWeights() map[string]int64 {
+	weights := map[string]int64{}
+	if ic == nil {
+		return weights
+	}
+
+	for _, t := range ic.Types {
+		if t == nil || t.InstanceType == nil {
+			continue
+		}
+
+		weight := int64(1)
+		if t.Weight != nil {
+			weight = *t.Weight
+		}
+
+		weights[*t.InstanceType] = weight
+	}
+
+	return weights
+}
+
+// MixedInstancesPolicy returns the ASG policy launching with the template
+func (ic *InstancesConfig) MixedInstancesPolicy(template *autoscaling.LaunchTemplateSpecification, spotMaxPrice *string) *autoscaling.MixedInstancesPolicy {
+	overrides := []*autoscaling.LaunchTemplateOverrides{}
+	for _, t := range ic.Types {
+		override := &autoscaling.LaunchTemplateOverrides{InstanceType: t.InstanceType}
+		if t.Weight != nil {
+			override.WeightedCapacity = to.Strp(fmt.Sprintf("%v", *t.Weight))
+		}
+		overrides = append(overrides, override)
+	}
+
+	return &autoscaling.MixedInstancesPolicy{
+		LaunchTemplate: &autoscaling.LaunchTemplate{
+			LaunchTemplateSpecification: template,
+			Overrides:                   overrides,
+		},
+		InstancesDistribution: &autoscaling.InstancesDistribution{
+			OnDemandBaseCapacity:                ic.OnDemandBaseCapacity,
+			OnDemandPercentageAboveBaseCapacity: ic.OnDemandPercentageAboveBaseCapacity,
+			SpotAllocationStrategy:              ic.SpotAllocationStrategy,
+			SpotMaxPrice:                        spotMaxPrice,
+		},
+	}
+}
+
+// OnDemandCapacity returns how much of the capacity is launched On-Demand
+func (ic *InstancesConfig) OnDemandCapacity(capacity int64) int64 {
+	base := int64(0)
+	if ic.OnDemandBaseCapacity != nil {
+		base = *ic.OnDemandBaseCapacity
+	}
+
+	if capacity <= base {
+		return capacity
+	}
+
+	percent := int64(100)
+	if ic.OnDemandPercentageAboveBaseCapacity != nil {
+		percent = *ic.OnDemandPercentageAboveBaseCapacity
+	}
+
+	// AWS rounds the On-Demand share up
+	return base + int64(math.Ceil(float64((capacity-base)*percent)/100))
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Instances_OnDemandCapacity(t *testing.T) {
+	ic := &InstancesConfig{}
+	assert.Equal(t, int64(10), ic.OnDemandCapacity(10))
+
+	ic.OnDemandBaseCapacity = to.Int64p(2)
+	ic.OnDemandPercentageAboveBaseCapacity = to.Int64p(25)
+	assert.Equal(t, int64(1), ic.OnDemandCapacity(1))
+	assert.Equal(t, int64(4), ic.OnDemandCapacity(10)) // 2 + ceil(8 * 25%)
+
+	ic.OnDemandPercentageAboveBaseCapacity = to.Int64p(0)
+	assert.Equal(t, int64(2), ic.OnDemandCapacity(10))
+}
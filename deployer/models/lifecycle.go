@@ -2,21 +2,34 @@ package models
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/coinbase/odin/aws"
 	"github.com/coinbase/odin/aws/iam"
 	"github.com/coinbase/odin/aws/sns"
+	"github.com/coinbase/odin/aws/sqs"
 	"github.com/coinbase/step/utils/is"
 	"github.com/coinbase/step/utils/to"
 )
 
+// LIFECYCLE_DEFAULT_RESULTS are what happens when a hook times out
+var LIFECYCLE_DEFAULT_RESULTS = []string{
+	"CONTINUE",
+	"ABANDON",
+}
+
 // LifeCycleHook struct
+// Without an SNS or SQS target the hook only sends EventBridge events
 type LifeCycleHook struct {
-	Transistion      *string `json:"transition,omitempty"`
-	SNS              *string `json:"sns,omitempty"`
-	Role             *string `json:"role,omitempty"`
-	HeartbeatTimeout *int64  `json:"heartbeat_timeout,omitempty"`
+	Transistion          *string `json:"transition,omitempty"`
+	SNS                  *string `json:"sns,omitempty"`
+	SQS                  *string `json:"sqs,omitempty"`
+	Role                 *string `json:"role,omitempty"`
+	HeartbeatTimeout     *int64  `json:"heartbeat_timeout,omitempty"`
+	DefaultResult        *string `json:"default_result,omitempty"`
+	NotificationMetadata *string `json:"notification_metadata,omitempty"` // Templated like userdata
 
 	RoleARN               *string `json:"role_arn,omitempty"`
 	NotificationTargetARN *string `json:"notification_target_arn,omitempty"`
@@ -28,8 +41,10 @@ func (lc *LifeCycleHook) ToLifecycleHookSpecification() *autoscaling.LifecycleHo
 	return &autoscaling.LifecycleHookSpecification{
 		LifecycleHookName: lc.Name,
 		HeartbeatTimeout:  lc.HeartbeatTimeout,
+		DefaultResult:     lc.DefaultResult,
 
-		LifecycleTransition: lc.Transistion,
+		LifecycleTransition:  lc.Transistion,
+		NotificationMetadata: lc.NotificationMetadata,
 
 		NotificationTargetARN: lc.NotificationTargetARN,
 		RoleARN:               lc.RoleARN,
@@ -37,16 +52,22 @@ func (lc *LifeCycleHook) ToLifecycleHookSpecification() *autoscaling.LifecycleHo
 }
 
 // FetchResources validates resources exist
-func (lc *LifeCycleHook) FetchResources(iamc aws.IAMAPI, snsc aws.SNSAPI) error {
-	err := iam.RoleExists(iamc, lc.Role)
-	if err != nil {
-		return err
+func (lc *LifeCycleHook) FetchResources(iamc aws.IAMAPI, snsc aws.SNSAPI, sqsc aws.SQSAPI) error {
+	if lc.Role != nil {
+		if err := iam.RoleExists(iamc, lc.Role); err != nil {
+			return err
+		}
 	}
 
-	if lc.SNS != nil {
+	switch lc.targetService() {
+	case "sns":
 		if err := sns.TopicExists(snsc, lc.NotificationTargetARN); err != nil {
 			return fmt.Errorf("SNS topic does not exist %v", err.Error())
 		}
+	case "sqs":
+		if err := sqs.QueueExists(sqsc, lc.NotificationTargetARN); err != nil {
+			return fmt.Errorf("SQS queue does not exist %v", err.Error())
+		}
 	}
 
 	return nil
@@ -63,6 +84,28 @@ func (lc *LifeCycleHook) SetDefaults(region *string, accountID *string, name str
 	if lc.SNS != nil && lc.NotificationTargetARN == nil {
 		lc.NotificationTargetARN = to.Strp(fmt.Sprintf("arn:aws:sns:%v:%v:%v", *region, *accountID, *lc.SNS))
 	}
+
+	if lc.SQS != nil && lc.NotificationTargetARN == nil {
+		if strings.HasPrefix(*lc.SQS, "arn:") {
+			lc.NotificationTargetARN = lc.SQS
+		} else {
+			lc.NotificationTargetARN = to.Strp(fmt.Sprintf("arn:aws:sqs:%v:%v:%v", *region, *accountID, *lc.SQS))
+		}
+	}
+}
+
+// targetService returns the AWS service of the notification target, e.g. "sns" or "sqs"
+func (lc *LifeCycleHook) targetService() string {
+	if lc.NotificationTargetARN == nil {
+		return ""
+	}
+
+	a, err := arn.Parse(*lc.NotificationTargetARN)
+	if err != nil {
+		return ""
+	}
+
+	return a.Service
 }
 
 // ValidateAttributes validates attributes
@@ -72,16 +115,30 @@ func (lc *LifeCycleHook) ValidateAttributes() error {
 		return err
 	}
 
-	if is.EmptyStr(lc.RoleARN) {
-		return fmt.Errorf("Lifecycle RoleARN nil")
+	if *lc.Transistion != "autoscaling:EC2_INSTANCE_LAUNCHING" && *lc.Transistion != "autoscaling:EC2_INSTANCE_TERMINATING" {
+		return fmt.Errorf("Transistion must equal either 'autoscaling:EC2_INSTANCE_LAUNCHING' or 'autoscaling:EC2_INSTANCE_TERMINATING'")
 	}
 
-	if is.EmptyStr(lc.NotificationTargetARN) {
-		return fmt.Errorf("Lifecycle NotificationTargetARN nil")
+	if lc.SNS != nil && lc.SQS != nil {
+		return fmt.Errorf("Lifecycle %v can only have one of sns or sqs", *lc.Name)
 	}
 
-	if *lc.Transistion != "autoscaling:EC2_INSTANCE_LAUNCHING" && *lc.Transistion != "autoscaling:EC2_INSTANCE_TERMINATING" {
-		return fmt.Errorf("Transistion must equal either 'autoscaling:EC2_INSTANCE_LAUNCHING' or 'autoscaling:EC2_INSTANCE_TERMINATING'")
+	if is.EmptyStr(lc.RoleARN) != is.EmptyStr(lc.NotificationTargetARN) {
+		return fmt.Errorf("Lifecycle %v requires both a role and a notification target, or neither to only send EventBridge events", *lc.Name)
+	}
+
+	if !is.EmptyStr(lc.NotificationTargetARN) {
+		if service := lc.targetService(); service != "sns" && service != "sqs" {
+			return fmt.Errorf("Lifecycle %v notification target must be an SNS topic or SQS queue", *lc.Name)
+		}
+	}
+
+	if lc.DefaultResult != nil && !containsStr(LIFECYCLE_DEFAULT_RESULTS, *lc.DefaultResult) {
+		return fmt.Errorf("Lifecycle %v default_result must be in %v", *lc.Name, LIFECYCLE_DEFAULT_RESULTS)
+	}
+
+	if lc.HeartbeatTimeout != nil && (*lc.HeartbeatTimeout < 30 || *lc.HeartbeatTimeout > 7200) {
+		return fmt.Errorf("Lifecycle %v heartbeat_timeout must be between 30 and 7200", *lc.Name)
 	}
 
 	return nil
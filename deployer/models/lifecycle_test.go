@@ -17,3 +17,70 @@ func Test_Lifecycle_Valid(t *testing.T) {
 	lc.SetDefaults(to.Strp("region"), to.Strp("accountID"), "name")
 	assert.NoError(t, lc.ValidateAttributes())
 }
+
+func Test_Lifecycle_SQS(t *testing.T) {
+	lc := &LifeCycleHook{
+		Transistion:   to.Strp("autoscaling:EC2_INSTANCE_TERMINATING"),
+		Role:          to.Strp("role"),
+		SQS:           to.Strp("queue"),
+		DefaultResult: to.Strp("ABANDON"),
+	}
+
+	lc.SetDefaults(to.Strp("region"), to.Strp("accountID"), "name")
+	assert.Equal(t, "arn:aws:sqs:region:accountID:queue", *lc.NotificationTargetARN)
+	assert.NoError(t, lc.ValidateAttributes())
+
+	lc = &LifeCycleHook{
+		Transistion: to.Strp("autoscaling:EC2_INSTANCE_TERMINATING"),
+		Role:        to.Strp("role"),
+		SQS:         to.Strp("arn:aws:sqs:us-west-2:other:queue"),
+	}
+
+	lc.SetDefaults(to.Strp("region"), to.Strp("accountID"), "name")
+	assert.Equal(t, "arn:aws:sqs:us-west-2:other:queue", *lc.NotificationTargetARN)
+	assert.NoError(t, lc.ValidateAttributes())
+}
+
+func Test_Lifecycle_EventBridge(t *testing.T) {
+	lc := &LifeCycleHook{
+		Transistion: to.Strp("autoscaling:EC2_INSTANCE_TERMINATING"),
+	}
+
+	lc.SetDefaults(to.Strp("region"), to.Strp("accountID"), "name")
+	assert.NoError(t, lc.ValidateAttributes())
+
+	lc.Role = to.Strp("role")
+	lc.SetDefaults(to.Strp("region"), to.Strp("accountID"), "name")
+	assert.Error(t, lc.ValidateAttributes())
+}
+
+func Test_Lifecycle_Invalid(t *testing.T) {
+	valid := func() *LifeCycleHook {
+		return &LifeCycleHook{
+			Transistion: to.Strp("autoscaling:EC2_INSTANCE_LAUNCHING"),
+			Role:        to.Strp("role"),
+			SNS:         to.Strp("sns"),
+		}
+	}
+
+	lc := valid()
+	lc.DefaultResult = to.Strp("RETRY")
+	lc.SetDefaults(to.Strp("region"), to.Strp("accountID"), "name")
+	assert.Error(t, lc.ValidateAttributes())
+
+	lc = valid()
+	lc.HeartbeatTimeout = to.Int64p(10)
+	lc.SetDefaults(to.Strp("region"), to.Strp("accountID"), "name")
+	assert.Error(t, lc.ValidateAttributes())
+
+	lc = valid()
+	lc.SQS = to.Strp("queue")
+	lc.SetDefaults(to.Strp("region"), to.Strp("accountID"), "name")
+	assert.Error(t, lc.ValidateAttributes())
+
+	lc = valid()
+	lc.SNS = nil
+	lc.NotificationTargetARN = to.Strp("arn:aws:lambda:region:accountID:function:name")
+	lc.SetDefaults(to.Strp("region"), to.Strp("accountID"), "name")
+	assert.Error(t, lc.ValidateAttributes())
+}
@@ -67,6 +67,15 @@ func AddReleaseS3Objects(awsc *mocks.MockClients, release *Release) {
 	awsc.S3.AddGetObject(*release.UserDataPath(), *release.UserData(), nil)
 	release.UserDataSHA256 = to.Strp(to.SHA256Str(release.UserData()))
 
+	for name, service := range release.Services {
+		if service == nil || service.RawUserData() == nil {
+			continue
+		}
+
+		awsc.S3.AddGetObject(*release.ServiceUserDataPath(name), *service.RawUserData(), nil)
+		service.UserDataSHA256 = to.Strp(to.SHA256Str(service.RawUserData()))
+	}
+
 	raw, _ := json.Marshal(release)
 	awsc.S3.AddGetObject(*release.ReleasePath(), string(raw), nil)
 }
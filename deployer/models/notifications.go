@@ -0,0 +1,187 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/bifrost"
+	"github.com/coinbase/step/utils/to"
+)
+
+// NOTIFICATION_EVENTS are the points in a deploy notifications are sent
+var NOTIFICATION_EVENTS = []string{
+	"ValidatePassed",
+	"LockAcquired",
+	"DeployStarted",
+	"RolloutStep",
+	"Success",
+	"FailureClean",
+	"FailureDirty",
+}
+
+// NOTIFICATION_TOPIC_PREFIX is the topic name prefix the deployer is allowed to publish to
+var NOTIFICATION_TOPIC_PREFIX = "odin-notifications-"
+
+// webhookClient posts the events to webhooks, the timeout keeps a slow webhook from slowing the deploy
+var webhookClient = &http.Client{Timeout: 5 * time.Second}
+
+// NotificationConfig sends an event to SNS topics and webhooks at each point in the deploy
+type NotificationConfig struct {
+	SNSTopics []*string `json:"sns_topics,omitempty"` // Topic ARNs published to by the deployer, named odin-notifications-*
+	Webhooks  []*string `json:"webhooks,omitempty"`   // URLs the event is POSTed to
+}
+
+// NotificationEvent is the JSON sent at each point in the deploy
+type NotificationEvent struct {
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+
+	AwsAccountID *string `json:"aws_account_id,omitempty"`
+	AwsRegion    *string `json:"aws_region,omitempty"`
+	ProjectName  *string `json:"project_name,omitempty"`
+	ConfigName   *string `json:"config_name,omitempty"`
+	ReleaseID    *string `json:"release_id,omitempty"`
+	UUID         *string `json:"uuid,omitempty"`
+
+	Services map[string]*HealthReport `json:"services"`
+
+	Error *bifrost.ReleaseError `json:"error,omitempty"`
+}
+
+// ValidateAttributes validates attributes
+func (n *NotificationConfig) ValidateAttributes() error {
+	for _, topic := range n.SNSTopics {
+		if topic == nil || !strings.HasPrefix(*topic, "arn:aws:sns:") {
+			return fmt.Errorf("Notifications sns_topics must be SNS topic ARNs")
+		}
+
+		// arn:aws:sns:region:account:name
+		parts := strings.Split(*topic, ":")
+		if len(parts) != 6 || !strings.HasPrefix(parts[5], NOTIFICATION_TOPIC_PREFIX) {
+			return fmt.Errorf("Notifications sns_topics names must start with %v", NOTIFICATION_TOPIC_PREFIX)
+		}
+	}
+
+	for _, webhook := range n.Webhooks {
+		if webhook == nil {
+			return fmt.Errorf("Notifications webhooks must be http or https URLs")
+		}
+
+		u, err := url.Parse(*webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("Notifications webhooks must be http or https URLs")
+		}
+	}
+
+	return nil
+}
+
+// NotificationEvent returns the event for the release
+func (release *Release) NotificationEvent(event string) *NotificationEvent {
+	services := map[string]*HealthReport{}
+	for name, service := range release.Services {
+		if service != nil {
+			services[name] = service.HealthReport
+		}
+	}
+
+	return &NotificationEvent{
+		Event:        event,
+		Time:         time.Now().UTC(),
+		AwsAccountID: release.AwsAccountID,
+		AwsRegion:    release.AwsRegion,
+		ProjectName:  release.ProjectName,
+		ConfigName:   release.ConfigName,
+		ReleaseID:    release.ReleaseID,
+		UUID:         release.UUID,
+		Services:     services,
+		Error:        release.Error,
+	}
+}
+
+// Notify sends the event to the releases SNS topics and webhooks
+// Errors are logged and ignored, a notification never fails the deploy
+func (release *Release) Notify(snsc aws.SNSAPI, event string) {
+	if release.Notifications == nil {
+		return
+	}
+
+	for _, err := range release.Notifications.send(snsc, release.NotificationEvent(event)) {
+		fmt.Printf("IGNORED: notification %v %v\n", event, err)
+	}
+}
+
+func (n *NotificationConfig) send(snsc aws.SNSAPI, event *NotificationEvent) []error {
+	// The release may have failed validation
+	if err := n.ValidateAttributes(); err != nil {
+		return []error{err}
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return []error{err}
+	}
+
+	errs := []error{}
+	for _, topic := range n.SNSTopics {
+		_, err := snsc.Publish(&sns.PublishInput{
+			TopicArn: topic,
+			Subject:  to.Strp(fmt.Sprintf("odin %v", event.Event)),
+			Message:  to.Strp(string(body)),
+		})
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("SNS %v %v", *topic, err.Error()))
+		}
+	}
+
+	for _, webhook := range n.Webhooks {
+		if err := postWebhook(*webhook, body); err != nil {
+			errs = append(errs, fmt.Errorf("Webhook %v %v", *webhook, err.Error()))
+		}
+	}
+
+	return errs
+}
+
+func postWebhook(webhook string, body []byte) error {
+	resp, err := webhookClient.Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status %v", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// RolloutSteps returns the current rollout step of each service
+func (release *Release) RolloutSteps() map[string]int64 {
+	steps := map[string]int64{}
+	for name, service := range release.Services {
+		if service != nil {
+			steps[name] = service.RolloutStep
+		}
+	}
+	return steps
+}
+
+// RolloutStepChanged returns true if any service has moved on from the steps in before
+func (release *Release) RolloutStepChanged(before map[string]int64) bool {
+	for name, step := range release.RolloutSteps() {
+		if before[name] != step {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/bifrost"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NotificationConfig_ValidateAttributes(t *testing.T) {
+	n := &NotificationConfig{
+		SNSTopics: []*string{to.Strp("arn:aws:sns:us-east-1:000000:odin-notifications-deploys")},
+		Webhooks:  []*string{to.Strp("https://hooks.example.com/odin")},
+	}
+	assert.NoError(t, n.ValidateAttributes())
+
+	n.SNSTopics = []*string{to.Strp("deploys")}
+	assert.Error(t, n.ValidateAttributes())
+
+	// The deployer can only publish to topics with the prefix
+	n.SNSTopics = []*string{to.Strp("arn:aws:sns:us-east-1:000000:deploys")}
+	assert.Error(t, n.ValidateAttributes())
+
+	n.SNSTopics = nil
+	n.Webhooks = []*string{to.Strp("ftp://hooks.example.com/odin")}
+	assert.Error(t, n.ValidateAttributes())
+
+	n.Webhooks = []*string{to.Strp("hooks.example.com")}
+	assert.Error(t, n.ValidateAttributes())
+}
+
+func Test_Release_Notify(t *testing.T) {
+	var received NotificationEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.NoError(t, json.Unmarshal(body, &received))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+	}))
+	defer server.Close()
+
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+	r.Services["web"].HealthReport = &HealthReport{RolloutStep: to.Int64p(1)}
+	r.Error = &bifrost.ReleaseError{Error: to.Strp("HaltError"), Cause: to.Strp("halted")}
+	r.Notifications = &NotificationConfig{
+		SNSTopics: []*string{to.Strp("arn:aws:sns:us-east-1:000000:odin-notifications-deploys")},
+		Webhooks:  []*string{to.Strp(server.URL)},
+	}
+
+	snsc := &mocks.SNSClient{}
+	r.Notify(snsc, "FailureClean")
+
+	assert.Equal(t, 1, len(snsc.PublishInputs))
+	assert.Equal(t, "arn:aws:sns:us-east-1:000000:odin-notifications-deploys", *snsc.PublishInputs[0].TopicArn)
+
+	var published NotificationEvent
+	assert.NoError(t, json.Unmarshal([]byte(*snsc.PublishInputs[0].Message), &published))
+
+	for _, event := range []NotificationEvent{published, received} {
+		assert.Equal(t, "FailureClean", event.Event)
+		assert.Equal(t, "1", *event.ReleaseID)
+		assert.Equal(t, int64(1), *event.Services["web"].RolloutStep)
+		assert.Equal(t, "halted", *event.Error.Cause)
+	}
+}
+
+func Test_Release_Notify_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+	}))
+	defer server.Close()
+
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+	r.Notifications = &NotificationConfig{
+		SNSTopics: []*string{to.Strp("arn:aws:sns:us-east-1:000000:odin-notifications-deploys")},
+		Webhooks:  []*string{to.Strp(server.URL), to.Strp("http://127.0.0.1:1/closed")},
+	}
+
+	snsc := &mocks.SNSClient{PublishError: assert.AnError}
+	errs := r.Notifications.send(snsc, r.NotificationEvent("Success"))
+	assert.Equal(t, 3, len(errs))
+
+	// Errors are ignored
+	r.Notify(snsc, "Success")
+}
+
+func Test_Release_RolloutStepChanged(t *testing.T) {
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+
+	steps := r.RolloutSteps()
+	assert.False(t, r.RolloutStepChanged(steps))
+
+	r.Services["web"].RolloutStep = 1
+	assert.True(t, r.RolloutStepChanged(steps))
+}
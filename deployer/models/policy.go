@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
@@ -12,6 +13,40 @@ import (
 
 const cpuScaleDown = "cpu_scale_down"
 const cpuScaleUp = "cpu_scale_up"
+const targetTracking = "target_tracking"
+const step = "step"
+
+const albRequestCountPerTarget = "ALBRequestCountPerTarget"
+
+var TARGET_TRACKING_METRICS = []string{
+	"ASGAverageCPUUtilization",
+	"ASGAverageNetworkIn",
+	"ASGAverageNetworkOut",
+	albRequestCountPerTarget,
+}
+
+var STEP_STATISTICS = []string{
+	"SampleCount",
+	"Average",
+	"Sum",
+	"Minimum",
+	"Maximum",
+}
+
+var STEP_COMPARISON_OPERATORS = []string{
+	"GreaterThanOrEqualToThreshold",
+	"GreaterThanThreshold",
+	"LessThanThreshold",
+	"LessThanOrEqualToThreshold",
+}
+
+// StepAdjustment scales by ScalingAdjustment when the metric is between the bounds
+// Bounds are relative to the threshold, nil is unbounded
+type StepAdjustment struct {
+	LowerBound        *float64 `json:"lower_bound,omitempty"`
+	UpperBound        *float64 `json:"upper_bound,omitempty"`
+	ScalingAdjustment *int64   `json:"scaling_adjustment,omitempty"`
+}
 
 // Policy struct
 type Policy struct {
@@ -24,6 +59,20 @@ type Policy struct {
 	PeriodVal            *int64   `json:"period,omitempty"`
 	EvaluationPeriodsVal *int64   `json:"evaluation_periods,omitempty"`
 	CooldownVal          *int64   `json:"cooldown,omitempty"`
+
+	// Target Tracking
+	Metric         *string  `json:"metric,omitempty"`
+	TargetValue    *float64 `json:"target_value,omitempty"`
+	TargetGroup    *string  `json:"target_group,omitempty"` // Only for ALBRequestCountPerTarget
+	DisableScaleIn *bool    `json:"disable_scale_in,omitempty"`
+
+	// Step
+	Namespace          *string           `json:"namespace,omitempty"`
+	MetricName         *string           `json:"metric_name,omitempty"`
+	Dimensions         map[string]string `json:"dimensions,omitempty"` // Default AutoScalingGroupName
+	Statistic          *string           `json:"statistic,omitempty"`
+	ComparisonOperator *string           `json:"comparison_operator,omitempty"`
+	StepAdjustments    []*StepAdjustment `json:"step_adjustments,omitempty"`
 }
 
 func (a *Policy) Name() *string {
@@ -91,10 +140,45 @@ func (a *Policy) Cooldown() *int64 {
 	return to.Int64p(60)
 }
 
+// IsTargetTracking returns true if AWS manages the policies alarms
+func (a *Policy) IsTargetTracking() bool {
+	return a.Type != nil && *a.Type == targetTracking
+}
+
+// IsStep returns true if the policy scales on any CloudWatch metric
+func (a *Policy) IsStep() bool {
+	return a.Type != nil && *a.Type == step
+}
+
+// IsCPUStep returns true for the cpu step policies
+func (a *Policy) IsCPUStep() bool {
+	return a.Type != nil && (*a.Type == cpuScaleUp || *a.Type == cpuScaleDown)
+}
+
+// TracksCPU returns true if the policy scales on the ASGs CPU
+func (a *Policy) TracksCPU() bool {
+	return a.IsTargetTracking() && a.Metric != nil && *a.Metric == "ASGAverageCPUUtilization"
+}
+
+// NeedsResourceLabel returns true if the policy tracks a target group
+func (a *Policy) NeedsResourceLabel() bool {
+	return a.IsTargetTracking() && a.Metric != nil && *a.Metric == albRequestCountPerTarget
+}
+
 // Create attempts to create alarm and policy
-func (a *Policy) Create(asgc aws.ASGAPI, cwc aws.CWAPI, asgName *string) error {
+// resourceLabel identifies the target group for ALBRequestCountPerTarget
+func (a *Policy) Create(asgc aws.ASGAPI, cwc aws.CWAPI, asgName *string, resourceLabel *string) error {
+	if a.IsTargetTracking() {
+		// AWS creates and manages the alarms
+		_, err := a.createTargetTrackingPolicyInput(asgName, resourceLabel).Create(asgc)
+		return err
+	}
 
 	policyInput := a.createPutScalingPolicyInput(asgName)
+	if a.IsStep() {
+		policyInput = a.createStepScalingPolicyInput(asgName)
+	}
+
 	output, err := policyInput.Create(asgc)
 	if err != nil {
 		return err
@@ -116,10 +200,26 @@ func (a *Policy) ValidateAttributes() error {
 		return fmt.Errorf("Policy(?): Type nil")
 	}
 
+	if a.IsTargetTracking() {
+		return a.validateTargetTracking()
+	}
+
+	if a.IsStep() {
+		return a.validateStep()
+	}
+
 	if *a.Type != cpuScaleDown && *a.Type != cpuScaleUp {
 		return fmt.Errorf("Policy(%v): Unsupported Type %v", *a.Name(), *a.Type)
 	}
 
+	if a.Metric != nil || a.TargetValue != nil || a.TargetGroup != nil || a.DisableScaleIn != nil {
+		return fmt.Errorf("Policy(%v): metric, target_value, target_group and disable_scale_in are only for target_tracking", *a.Name())
+	}
+
+	if a.hasStepAttributes() {
+		return fmt.Errorf("Policy(%v): namespace, metric_name, dimensions, statistic, comparison_operator and step_adjustments are only for step", *a.Name())
+	}
+
 	if err := a.createMetricAlarmInput(to.Strp("asgName"), nil).Validate(); err != nil {
 		return fmt.Errorf("Policy(%v): %v", *a.Name(), err.Error())
 	}
@@ -131,6 +231,119 @@ func (a *Policy) ValidateAttributes() error {
 	return nil
 }
 
+func (a *Policy) validateTargetTracking() error {
+	if a.Metric == nil || !containsStr(TARGET_TRACKING_METRICS, *a.Metric) {
+		return fmt.Errorf("Policy(%v): metric is %v but must be in %v", *a.Name(), to.Strs(a.Metric), TARGET_TRACKING_METRICS)
+	}
+
+	if a.TargetValue == nil || *a.TargetValue <= 0 {
+		return fmt.Errorf("Policy(%v): target_value must be greater than 0", *a.Name())
+	}
+
+	if a.TargetGroup != nil && !a.NeedsResourceLabel() {
+		return fmt.Errorf("Policy(%v): target_group is only for %v", *a.Name(), albRequestCountPerTarget)
+	}
+
+	if a.ScalingAdjustmentVal != nil || a.ThresholdVal != nil || a.PeriodVal != nil || a.EvaluationPeriodsVal != nil || a.CooldownVal != nil {
+		return fmt.Errorf("Policy(%v): scaling_adjustment, threshold, period, evaluation_periods and cooldown are not supported by target_tracking", *a.Name())
+	}
+
+	if a.hasStepAttributes() {
+		return fmt.Errorf("Policy(%v): namespace, metric_name, dimensions, statistic, comparison_operator and step_adjustments are only for step", *a.Name())
+	}
+
+	if err := a.createTargetTrackingPolicyInput(to.Strp("asgName"), to.Strp("resourceLabel")).Validate(); err != nil {
+		return fmt.Errorf("Policy(%v): %v", *a.Name(), err.Error())
+	}
+
+	return nil
+}
+
+func (a *Policy) hasStepAttributes() bool {
+	return a.Namespace != nil || a.MetricName != nil || a.Dimensions != nil || a.Statistic != nil || a.ComparisonOperator != nil || a.StepAdjustments != nil
+}
+
+func (a *Policy) validateStep() error {
+	if a.Namespace == nil || a.MetricName == nil {
+		return fmt.Errorf("Policy(%v): namespace and metric_name required", *a.Name())
+	}
+
+	if a.Statistic == nil || !containsStr(STEP_STATISTICS, *a.Statistic) {
+		return fmt.Errorf("Policy(%v): statistic is %v but must be in %v", *a.Name(), to.Strs(a.Statistic), STEP_STATISTICS)
+	}
+
+	if a.ComparisonOperator == nil || !containsStr(STEP_COMPARISON_OPERATORS, *a.ComparisonOperator) {
+		return fmt.Errorf("Policy(%v): comparison_operator is %v but must be in %v", *a.Name(), to.Strs(a.ComparisonOperator), STEP_COMPARISON_OPERATORS)
+	}
+
+	if a.ThresholdVal == nil {
+		return fmt.Errorf("Policy(%v): threshold required", *a.Name())
+	}
+
+	if a.ScalingAdjustmentVal != nil || a.CooldownVal != nil {
+		return fmt.Errorf("Policy(%v): scaling_adjustment and cooldown are not supported by step, use step_adjustments", *a.Name())
+	}
+
+	if a.Metric != nil || a.TargetValue != nil || a.TargetGroup != nil || a.DisableScaleIn != nil {
+		return fmt.Errorf("Policy(%v): metric, target_value, target_group and disable_scale_in are only for target_tracking", *a.Name())
+	}
+
+	if err := a.validateStepAdjustments(); err != nil {
+		return fmt.Errorf("Policy(%v): %v", *a.Name(), err.Error())
+	}
+
+	if err := a.createMetricAlarmInput(to.Strp("asgName"), nil).Validate(); err != nil {
+		return fmt.Errorf("Policy(%v): %v", *a.Name(), err.Error())
+	}
+
+	if err := a.createStepScalingPolicyInput(to.Strp("asgName")).Validate(); err != nil {
+		return fmt.Errorf("Policy(%v): %v", *a.Name(), err.Error())
+	}
+
+	return nil
+}
+
+// validateStepAdjustments checks the bounds cover a single range without gaps or overlaps
+func (a *Policy) validateStepAdjustments() error {
+	if len(a.StepAdjustments) == 0 {
+		return fmt.Errorf("step_adjustments required")
+	}
+
+	steps := []*StepAdjustment{}
+	for _, s := range a.StepAdjustments {
+		if s == nil {
+			return fmt.Errorf("step_adjustments cannot be null")
+		}
+
+		if s.ScalingAdjustment == nil {
+			return fmt.Errorf("step_adjustments scaling_adjustment required")
+		}
+
+		if s.LowerBound != nil && s.UpperBound != nil && *s.LowerBound >= *s.UpperBound {
+			return fmt.Errorf("step_adjustments lower_bound must be less than upper_bound")
+		}
+
+		steps = append(steps, s)
+	}
+
+	// Unbounded lower first
+	sort.Slice(steps, func(i, j int) bool {
+		if steps[i].LowerBound == nil || steps[j].LowerBound == nil {
+			return steps[i].LowerBound == nil && steps[j].LowerBound != nil
+		}
+		return *steps[i].LowerBound < *steps[j].LowerBound
+	})
+
+	for i := 1; i < len(steps); i++ {
+		prev, next := steps[i-1], steps[i]
+		if prev.UpperBound == nil || next.LowerBound == nil || *prev.UpperBound != *next.LowerBound {
+			return fmt.Errorf("step_adjustments must not overlap or have gaps")
+		}
+	}
+
+	return nil
+}
+
 // SetDefaults assigns default values
 func (a *Policy) SetDefaults(serviceID *string) error {
 	a.serviceID = serviceID
@@ -152,6 +365,16 @@ func (a *Policy) createMetricAlarmInput(asgName *string, policyARN *string) *ala
 		&cloudwatch.Dimension{Name: to.Strp("AutoScalingGroupName"), Value: asgName},
 	}
 
+	if a.IsStep() {
+		alarm.MetricName = a.MetricName
+		alarm.Namespace = a.Namespace
+		alarm.Statistic = a.Statistic
+		alarm.ComparisonOperator = a.ComparisonOperator
+		if len(a.Dimensions) > 0 {
+			alarm.Dimensions = a.dimensions()
+		}
+	}
+
 	if policyARN != nil {
 		alarm.AlarmActions = []*string{policyARN}
 	}
@@ -165,9 +388,46 @@ func (a *Policy) createMetricAlarmInput(asgName *string, policyARN *string) *ala
 
 	alarm.SetAlarmDescription()
 
+	if a.IsStep() {
+		alarm.AddStepAdjustmentsDescription(a.stepAdjustments())
+	}
+
 	return alarm
 }
 
+// dimensions are sorted by name so the alarm is the same every deploy
+func (a *Policy) dimensions() []*cloudwatch.Dimension {
+	names := []string{}
+	for name := range a.Dimensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	dims := []*cloudwatch.Dimension{}
+	for _, name := range names {
+		dims = append(dims, &cloudwatch.Dimension{Name: to.Strp(name), Value: to.Strp(a.Dimensions[name])})
+	}
+
+	return dims
+}
+
+func (a *Policy) stepAdjustments() []*autoscaling.StepAdjustment {
+	steps := []*autoscaling.StepAdjustment{}
+	for _, s := range a.StepAdjustments {
+		if s == nil {
+			continue
+		}
+
+		steps = append(steps, &autoscaling.StepAdjustment{
+			MetricIntervalLowerBound: s.LowerBound,
+			MetricIntervalUpperBound: s.UpperBound,
+			ScalingAdjustment:        s.ScalingAdjustment,
+		})
+	}
+
+	return steps
+}
+
 func (a *Policy) createPutScalingPolicyInput(asgName *string) *alarms.PolicyInput {
 	return &alarms.PolicyInput{&autoscaling.PutScalingPolicyInput{
 		AutoScalingGroupName: asgName,
@@ -177,3 +437,37 @@ func (a *Policy) createPutScalingPolicyInput(asgName *string) *alarms.PolicyInpu
 		Cooldown:             a.Cooldown(),
 	}}
 }
+
+func (a *Policy) createStepScalingPolicyInput(asgName *string) *alarms.PolicyInput {
+	aggregation := "Average"
+	if a.Statistic != nil && (*a.Statistic == "Minimum" || *a.Statistic == "Maximum") {
+		aggregation = *a.Statistic
+	}
+
+	return &alarms.PolicyInput{&autoscaling.PutScalingPolicyInput{
+		AutoScalingGroupName:  asgName,
+		PolicyName:            a.Name(),
+		PolicyType:            to.Strp("StepScaling"),
+		AdjustmentType:        to.Strp("ChangeInCapacity"),
+		MetricAggregationType: to.Strp(aggregation),
+		StepAdjustments:       a.stepAdjustments(),
+	}}
+}
+
+func (a *Policy) createTargetTrackingPolicyInput(asgName *string, resourceLabel *string) *alarms.PolicyInput {
+	metric := &autoscaling.PredefinedMetricSpecification{PredefinedMetricType: a.Metric}
+	if a.NeedsResourceLabel() {
+		metric.ResourceLabel = resourceLabel
+	}
+
+	return &alarms.PolicyInput{&autoscaling.PutScalingPolicyInput{
+		AutoScalingGroupName: asgName,
+		PolicyName:           a.Name(),
+		PolicyType:           to.Strp("TargetTrackingScaling"),
+		TargetTrackingConfiguration: &autoscaling.TargetTrackingConfiguration{
+			PredefinedMetricSpecification: metric,
+			TargetValue:                   a.TargetValue,
+			DisableScaleIn:                a.DisableScaleIn,
+		},
+	}}
+}
@@ -29,3 +29,130 @@ func Test_Policy_Name_Prefix(t *testing.T) {
 	pol.NameVal = to.Strp("boom")
 	assert.Equal(t, *pol.Name(), "service_id-cpu_scale_down-boom")
 }
+
+func Test_Policy_TargetTracking_Valid(t *testing.T) {
+	pol := &Policy{
+		Type:        to.Strp("target_tracking"),
+		Metric:      to.Strp("ASGAverageCPUUtilization"),
+		TargetValue: to.Float64p(50),
+	}
+
+	pol.SetDefaults(to.Strp("service_id"))
+	assert.NoError(t, pol.ValidateAttributes())
+
+	input := pol.createTargetTrackingPolicyInput(to.Strp("asg"), to.Strp("label"))
+	assert.Equal(t, "TargetTrackingScaling", *input.PolicyType)
+	assert.Nil(t, input.TargetTrackingConfiguration.PredefinedMetricSpecification.ResourceLabel)
+
+	pol.Metric = to.Strp("ALBRequestCountPerTarget")
+	input = pol.createTargetTrackingPolicyInput(to.Strp("asg"), to.Strp("label"))
+	assert.Equal(t, "label", *input.TargetTrackingConfiguration.PredefinedMetricSpecification.ResourceLabel)
+}
+
+func Test_Policy_TargetTracking_Invalid(t *testing.T) {
+	valid := func() *Policy {
+		pol := &Policy{
+			Type:        to.Strp("target_tracking"),
+			Metric:      to.Strp("ASGAverageNetworkIn"),
+			TargetValue: to.Float64p(1000),
+		}
+		pol.SetDefaults(to.Strp("service_id"))
+		return pol
+	}
+
+	pol := valid()
+	pol.Metric = to.Strp("Unknown")
+	assert.Error(t, pol.ValidateAttributes())
+
+	pol = valid()
+	pol.TargetValue = nil
+	assert.Error(t, pol.ValidateAttributes())
+
+	pol = valid()
+	pol.ThresholdVal = to.Float64p(10)
+	assert.Error(t, pol.ValidateAttributes())
+
+	pol = valid()
+	pol.TargetGroup = to.Strp("tg")
+	assert.Error(t, pol.ValidateAttributes())
+
+	pol = &Policy{Type: to.Strp("cpu_scale_up"), Metric: to.Strp("ASGAverageNetworkIn")}
+	pol.SetDefaults(to.Strp("service_id"))
+	assert.Error(t, pol.ValidateAttributes())
+}
+
+func stepPolicy() *Policy {
+	pol := &Policy{
+		Type:               to.Strp("step"),
+		Namespace:          to.Strp("AWS/SQS"),
+		MetricName:         to.Strp("ApproximateNumberOfMessagesVisible"),
+		Dimensions:         map[string]string{"QueueName": "jobs"},
+		Statistic:          to.Strp("Sum"),
+		ComparisonOperator: to.Strp("GreaterThanOrEqualToThreshold"),
+		ThresholdVal:       to.Float64p(100),
+		StepAdjustments: []*StepAdjustment{
+			&StepAdjustment{LowerBound: to.Float64p(100), ScalingAdjustment: to.Int64p(4)},
+			&StepAdjustment{LowerBound: to.Float64p(0), UpperBound: to.Float64p(100), ScalingAdjustment: to.Int64p(1)},
+		},
+	}
+	pol.SetDefaults(to.Strp("service_id"))
+	return pol
+}
+
+func Test_Policy_Step_Valid(t *testing.T) {
+	pol := stepPolicy()
+	assert.NoError(t, pol.ValidateAttributes())
+
+	alarm := pol.createMetricAlarmInput(to.Strp("asg"), to.Strp("arn"))
+	assert.Equal(t, "AWS/SQS", *alarm.Namespace)
+	assert.Equal(t, "QueueName", *alarm.Dimensions[0].Name)
+	assert.Equal(t, "jobs", *alarm.Dimensions[0].Value)
+	assert.Contains(t, *alarm.AlarmDescription, "adjust by +4 from threshold+100 to threshold+inf")
+
+	input := pol.createStepScalingPolicyInput(to.Strp("asg"))
+	assert.Equal(t, "StepScaling", *input.PolicyType)
+	assert.Equal(t, "Average", *input.MetricAggregationType)
+	assert.Equal(t, 2, len(input.StepAdjustments))
+
+	// Without dimensions the alarm is on the ASG
+	pol.Dimensions = nil
+	alarm = pol.createMetricAlarmInput(to.Strp("asg"), to.Strp("arn"))
+	assert.Equal(t, "AutoScalingGroupName", *alarm.Dimensions[0].Name)
+}
+
+func Test_Policy_Step_Invalid(t *testing.T) {
+	pol := stepPolicy()
+	pol.Statistic = to.Strp("p99")
+	assert.Error(t, pol.ValidateAttributes())
+
+	pol = stepPolicy()
+	pol.ComparisonOperator = to.Strp("Equal")
+	assert.Error(t, pol.ValidateAttributes())
+
+	pol = stepPolicy()
+	pol.ThresholdVal = nil
+	assert.Error(t, pol.ValidateAttributes())
+
+	pol = stepPolicy()
+	pol.StepAdjustments = nil
+	assert.Error(t, pol.ValidateAttributes())
+
+	// Gap between 50 and 100
+	pol = stepPolicy()
+	pol.StepAdjustments[1].UpperBound = to.Float64p(50)
+	assert.Error(t, pol.ValidateAttributes())
+
+	// Two unbounded steps overlap
+	pol = stepPolicy()
+	pol.StepAdjustments[1].LowerBound = nil
+	pol.StepAdjustments[1].UpperBound = nil
+	assert.Error(t, pol.ValidateAttributes())
+
+	pol = stepPolicy()
+	pol.CooldownVal = to.Int64p(60)
+	assert.Error(t, pol.ValidateAttributes())
+
+	pol = &Policy{Type: to.Strp("cpu_scale_up"), MetricName: to.Strp("Other")}
+	pol.SetDefaults(to.Strp("service_id"))
+	assert.Error(t, pol.ValidateAttributes())
+}
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/coinbase/step/utils/is"
+)
+
+// RegionOverride deploys the release to a region, replacing the values that differ between regions
+type RegionOverride struct {
+	Region  *string   `json:"region,omitempty"`
+	Bucket  *string   `json:"bucket,omitempty"` // The bucket of the regions deployer, default the releases
+	Subnets []*string `json:"subnets,omitempty"`
+	Image   *string   `json:"ami,omitempty"`
+
+	Services map[string]*ServiceOverride `json:"services,omitempty"`
+}
+
+// ServiceOverride replaces a services resources in a region or account
+type ServiceOverride struct {
+	ELBs           []*string           `json:"elbs,omitempty"`
+	Profile        *string             `json:"profile,omitempty"`
+	TargetGroups   []*string           `json:"target_groups,omitempty"`
+	SecurityGroups []*string           `json:"security_groups,omitempty"`
+	Image          *string             `json:"ami,omitempty"`
+	ServiceSubnets []*string           `json:"subnets,omitempty"`
+	TrafficShift   *TrafficShiftConfig `json:"traffic_shift,omitempty"`
+}
+
+// IsParallelRegions returns true if all regions are deployed at once
+func (release *Release) IsParallelRegions() bool {
+	return release.RegionStrategy != nil && *release.RegionStrategy == "Parallel"
+}
+
+// ValidateRegions validates the regions the client deploys the release to
+func (release *Release) ValidateRegions() error {
+	if err := validateStrategy("region_strategy", release.RegionStrategy); err != nil {
+		return err
+	}
+
+	regions := map[string]bool{}
+	buckets := map[string]bool{}
+	for _, ro := range release.Regions {
+		if ro == nil || is.EmptyStr(ro.Region) {
+			return fmt.Errorf("Regions must each have a region")
+		}
+
+		if regions[*ro.Region] {
+			return fmt.Errorf("Region %v is defined more than once", *ro.Region)
+		}
+		regions[*ro.Region] = true
+
+		// The release and lock paths only include the account, so regions cannot share a bucket
+		bucket := ro.bucket(release)
+		if bucket == nil || buckets[*bucket] {
+			return fmt.Errorf("Region %v must have its own bucket", *ro.Region)
+		}
+		buckets[*bucket] = true
+
+		for name := range ro.Services {
+			if _, ok := release.Services[name]; !ok {
+				return fmt.Errorf("Region %v overrides unknown service %v", *ro.Region, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateStrategy checks a fan out strategy is "Sequential" or "Parallel"
+func validateStrategy(name string, strategy *string) error {
+	if strategy == nil {
+		return nil
+	}
+
+	switch *strategy {
+	case "Sequential", "Parallel":
+		return nil
+	}
+
+	return fmt.Errorf("%v must be either 'Sequential', 'Parallel'", name)
+}
+
+func (ro *RegionOverride) bucket(release *Release) *string {
+	if !is.EmptyStr(ro.Bucket) {
+		return ro.Bucket
+	}
+	return release.Bucket
+}
+
+// RegionReleases returns a copy of the release for each region, in order, with its overrides applied
+func (release *Release) RegionReleases() ([]*Release, error) {
+	if err := release.ValidateRegions(); err != nil {
+		return nil, err
+	}
+
+	releases := []*Release{}
+	for _, ro := range release.Regions {
+		r, err := release.copyRelease()
+		if err != nil {
+			return nil, err
+		}
+
+		r.Regions = nil
+		r.RegionStrategy = nil
+		ro.apply(r)
+
+		releases = append(releases, r)
+	}
+
+	return releases, nil
+}
+
+// copyRelease deep copies the release including its userdata
+func (release *Release) copyRelease() (*Release, error) {
+	raw, err := json.Marshal(release)
+	if err != nil {
+		return nil, err
+	}
+
+	var r Release
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return nil, err
+	}
+
+	r.SetUserData(release.UserData())
+	for name, service := range r.Services {
+		if service != nil && release.Services[name] != nil {
+			service.SetUserData(release.Services[name].RawUserData())
+		}
+	}
+
+	return &r, nil
+}
+
+func (ro *RegionOverride) apply(r *Release) {
+	r.AwsRegion = ro.Region
+	r.Bucket = ro.bucket(r)
+	applyOverrides(r, ro.Subnets, ro.Image, ro.Services)
+}
+
+// applyOverrides replaces the releases subnets and AMI and the services resources
+func applyOverrides(r *Release, subnets []*string, image *string, services map[string]*ServiceOverride) {
+	if len(subnets) > 0 {
+		r.Subnets = subnets
+	}
+
+	if image != nil {
+		r.Image = image
+	}
+
+	for name, so := range services {
+		service := r.Services[name]
+		if so == nil || service == nil {
+			continue
+		}
+
+		if len(so.ELBs) > 0 {
+			service.ELBs = so.ELBs
+		}
+
+		if so.Profile != nil {
+			service.Profile = so.Profile
+		}
+
+		if len(so.TargetGroups) > 0 {
+			service.TargetGroups = so.TargetGroups
+		}
+
+		if len(so.SecurityGroups) > 0 {
+			service.SecurityGroups = so.SecurityGroups
+		}
+
+		if so.Image != nil {
+			service.Image = so.Image
+		}
+
+		if len(so.ServiceSubnets) > 0 {
+			service.ServiceSubnets = so.ServiceSubnets
+		}
+
+		if so.TrafficShift != nil {
+			service.TrafficShift = so.TrafficShift
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockRegions(r *Release) {
+	r.Regions = []*RegionOverride{
+		&RegionOverride{Region: to.Strp("us-east-1")},
+		&RegionOverride{
+			Region:  to.Strp("us-west-2"),
+			Bucket:  to.Strp("bucket-west"),
+			Subnets: []*string{to.Strp("west-subnet")},
+			Image:   to.Strp("west-ami"),
+			Services: map[string]*ServiceOverride{
+				"web": &ServiceOverride{
+					ELBs:           []*string{to.Strp("west-elb")},
+					SecurityGroups: []*string{to.Strp("west-sg")},
+				},
+			},
+		},
+	}
+}
+
+func Test_Release_RegionReleases(t *testing.T) {
+	r := MockRelease(t)
+	r.SetUserData(to.Strp("#cloud_config"))
+	mockRegions(r)
+
+	releases, err := r.RegionReleases()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(releases))
+
+	east, west := releases[0], releases[1]
+
+	assert.Equal(t, "us-east-1", *east.AwsRegion)
+	assert.Equal(t, "bucket", *east.Bucket)
+	assert.Equal(t, "ubuntu", *east.Image)
+	assert.Equal(t, "web-elb", *east.Services["web"].ELBs[0])
+
+	assert.Equal(t, "us-west-2", *west.AwsRegion)
+	assert.Equal(t, "bucket-west", *west.Bucket)
+	assert.Equal(t, "west-subnet", *west.Subnets[0])
+	assert.Equal(t, "west-ami", *west.Image)
+	assert.Equal(t, "west-elb", *west.Services["web"].ELBs[0])
+	assert.Equal(t, "west-sg", *west.Services["web"].SecurityGroups[0])
+	assert.Equal(t, "web-elb-target", *west.Services["web"].TargetGroups[0])
+
+	for _, rr := range releases {
+		assert.Nil(t, rr.Regions)
+		assert.Equal(t, "#cloud_config", *rr.UserData())
+		assert.Equal(t, *r.ReleaseID, *rr.ReleaseID)
+	}
+
+	// The original is unchanged
+	assert.Equal(t, "web-elb", *r.Services["web"].ELBs[0])
+	assert.Equal(t, "ubuntu", *r.Image)
+}
+
+func Test_Release_ValidateRegions(t *testing.T) {
+	r := MockRelease(t)
+	mockRegions(r)
+	assert.NoError(t, r.ValidateRegions())
+
+	r.RegionStrategy = to.Strp("Parallel")
+	assert.NoError(t, r.ValidateRegions())
+	assert.True(t, r.IsParallelRegions())
+
+	r.RegionStrategy = to.Strp("AllAtOnce")
+	assert.Error(t, r.ValidateRegions())
+
+	r = MockRelease(t)
+	mockRegions(r)
+	r.Regions[1].Bucket = nil
+	assert.Error(t, r.ValidateRegions()) // Regions share a bucket
+
+	r = MockRelease(t)
+	mockRegions(r)
+	r.Regions[1].Region = to.Strp("us-east-1")
+	assert.Error(t, r.ValidateRegions())
+
+	r = MockRelease(t)
+	mockRegions(r)
+	r.Regions[1].Services["worker"] = &ServiceOverride{}
+	assert.Error(t, r.ValidateRegions())
+}
+
+func Test_Release_Validate_Regions(t *testing.T) {
+	r := MockRelease(t)
+	mockRegions(r)
+	awsc := MockAwsClients(r)
+	r.ReleaseSHA256 = to.SHA256Struct(r)
+
+	MockPrepareRelease(r)
+
+	err := r.Validate(awsc.S3, awsc.KMS)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "regions and accounts must be deployed with odin deploy")
+}
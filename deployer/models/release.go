@@ -2,6 +2,11 @@ package models
 
 import (
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/kms"
+	aws_s3 "github.com/aws/aws-sdk-go/service/s3"
 
 	"github.com/coinbase/odin/aws"
 	"github.com/coinbase/step/aws/s3"
@@ -22,6 +27,10 @@ type Release struct {
 
 	userdata       *string // Not serialized
 	UserDataSHA256 *string `json:"user_data_sha256,omitempty"`
+	UserDataKMSKey *string `json:"user_data_kms_key,omitempty"`
+
+	// Signature over the release and userdata SHA256, checked against the project configs signing keys
+	Signature *ReleaseSignature `json:"signature,omitempty"`
 
 	// LifeCycleHooks
 	LifeCycleHooks map[string]*LifeCycleHook `json:"lifecycle,omitempty"`
@@ -38,6 +47,36 @@ type Release struct {
 	DetachStrategy *string `json:"detach_strategy,omitempty"`
 
 	WaitForDetach *int `json:"wait_for_detach,omitempty"`
+
+	// Approval pauses the rollout until "odin continue"
+	Approval *ApprovalConfig `json:"approval,omitempty"`
+	approved bool            // Not serialized, the continue marker is checked every time
+
+	// RetainPrevious keeps the previous ASGs for a fast rollback
+	RetainPrevious *RetainConfig `json:"retain_previous,omitempty"`
+
+	// OverrideFreeze is the justification for deploying during a freeze
+	OverrideFreeze *string         `json:"override_freeze,omitempty"`
+	FreezeOverride *FreezeOverride `json:"freeze_override,omitempty"` // Set by the deployer
+
+	// Notifications sends an event at each point in the deploy
+	Notifications *NotificationConfig `json:"notifications,omitempty"`
+
+	// Regions the client deploys a copy of the release to, one execution each
+	Regions []*RegionOverride `json:"regions,omitempty"`
+
+	// RegionStrategy can be "Sequential"(default) | "Parallel"
+	RegionStrategy *string `json:"region_strategy,omitempty"`
+
+	// Accounts the client deploys a copy of the release to, one execution each
+	Accounts []*AccountOverride `json:"accounts,omitempty"`
+
+	// AccountStrategy can be "Sequential"(default) | "Parallel"
+	AccountStrategy *string `json:"account_strategy,omitempty"`
+
+	events []*AuditEvent // Not serialized, appended to events.jsonl after each state
+
+	signatureRequired bool // Not serialized, set by the deployer
 }
 
 //////////
@@ -50,10 +89,27 @@ func (release *Release) UserDataPath() *string {
 	return &s
 }
 
+// ServiceUserDataPath returns the path of a services own userdata
+func (release *Release) ServiceUserDataPath(serviceName string) *string {
+	s := fmt.Sprintf("%v/%v.userdata", *release.ReleaseDir(), serviceName)
+	return &s
+}
+
+// KMSKeysPath returns the path of the KMS key allowlists, at the root of the bucket so deployers cannot write it
+func (release *Release) KMSKeysPath() *string {
+	return to.Strp("user_data_kms_keys.json")
+}
+
 //////////
 // Setters
 //////////
 
+// WipeControlledValues wipes the values the deployer sets
+func (release *Release) WipeControlledValues() {
+	release.Release.WipeControlledValues()
+	release.FreezeOverride = nil
+}
+
 // SetDefaultsWithUserData sets the default values including userdata fetched from S3
 func (release *Release) SetDefaultsWithUserData(s3c aws.S3API) error {
 	release.SetDefaults()
@@ -63,8 +119,17 @@ func (release *Release) SetDefaultsWithUserData(s3c aws.S3API) error {
 	}
 
 	for _, service := range release.Services {
-		if service != nil {
+		if service == nil {
+			continue
+		}
+
+		if !service.OverridesUserData() {
 			service.SetUserData(release.UserData())
+			continue
+		}
+
+		if err := service.DownloadUserData(s3c); err != nil {
+			return err
 		}
 	}
 
@@ -107,6 +172,10 @@ func (release *Release) SetDefaults() {
 		release.DetachStrategy = to.Strp("Detach")
 	}
 
+	if release.RetainPrevious != nil {
+		release.RetainPrevious.SetDefaults()
+	}
+
 	for name, lc := range release.LifeCycleHooks {
 		if lc != nil {
 			lc.SetDefaults(release.AwsRegion, release.AwsAccountID, name)
@@ -125,7 +194,7 @@ func (release *Release) SetDefaults() {
 //////////
 
 // Validate returns
-func (release *Release) Validate(s3c aws.S3API) error {
+func (release *Release) Validate(s3c aws.S3API, kmsc aws.KMSAPI) error {
 	if err := release.Release.Validate(s3c, &Release{}); err != nil {
 		return err
 	}
@@ -155,14 +224,62 @@ func (release *Release) Validate(s3c aws.S3API) error {
 		return fmt.Errorf("%v %v", release.ErrorPrefix(), "DetachStrategy must be either 'Detach', 'SkipDetach', 'SkipDetachCheck'")
 	}
 
-	if release.Image == nil {
-		return fmt.Errorf("%v %v", release.ErrorPrefix(), "AMI image must be provided")
+	// The client sends each region and account its own release
+	if len(release.Regions) > 0 || len(release.Accounts) > 0 {
+		return fmt.Errorf("%v %v", release.ErrorPrefix(), "regions and accounts must be deployed with odin deploy")
+	}
+
+	if release.Approval != nil {
+		if err := release.Approval.ValidateAttributes(); err != nil {
+			return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
+		}
+
+		for _, service := range release.Services {
+			if service == nil {
+				continue
+			}
+			if err := service.validateApproval(); err != nil {
+				return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
+			}
+		}
+	}
+
+	if release.Notifications != nil {
+		if err := release.Notifications.ValidateAttributes(); err != nil {
+			return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
+		}
+	}
+
+	if release.RetainPrevious != nil {
+		if err := release.RetainPrevious.ValidateAttributes(); err != nil {
+			return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
+		}
+
+		// Retained ASGs must not be serving traffic
+		if release.IsSkipDetachStep() {
+			return fmt.Errorf("%v %v", release.ErrorPrefix(), "retain_previous cannot be used with SkipDetach")
+		}
+	}
+
+	for _, service := range release.Services {
+		// Every service must have an image, either its own or the releases
+		if service != nil && service.AMI() == nil {
+			return fmt.Errorf("%v %v", release.ErrorPrefix(), "AMI image must be provided")
+		}
 	}
 
 	if err := release.ValidateUserDataSHA(s3c); err != nil {
 		return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
 	}
 
+	if err := release.ValidateUserDataKMSKey(s3c, kmsc); err != nil {
+		return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
+	}
+
+	if err := release.ValidateFreeze(s3c, time.Now()); err != nil {
+		return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
+	}
+
 	if err := release.ValidateServices(); err != nil {
 		return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
 	}
@@ -187,9 +304,144 @@ func (release *Release) ValidateUserDataSHA(s3c aws.S3API) error {
 		return fmt.Errorf("UserData SHA incorrect expected %v, got %v", userdataSha, *release.UserDataSHA256)
 	}
 
+	// Services with their own userdata each have their own SHA
+	for _, service := range release.Services {
+		if service == nil {
+			continue
+		}
+
+		if err := service.ValidateUserDataSHA(s3c); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// KMSKeyAllowlists are the KMS keys allowed to encrypt each project configs userdata
+type KMSKeyAllowlists struct {
+	Allowlists []*KMSKeyAllowlist `json:"allowlists"`
+}
+
+// KMSKeyAllowlist is the list of KMS keys allowed to encrypt a project configs userdata
+type KMSKeyAllowlist struct {
+	ProjectName *string  `json:"project_name,omitempty"`
+	ConfigName  *string  `json:"config_name,omitempty"` // Every config of the project if nil
+	KMSKeyIDs   []string `json:"kms_key_ids"`
+}
+
+// ValidateUserDataKMSKey validates the userdata was encrypted with an allowed KMS key
+func (release *Release) ValidateUserDataKMSKey(s3c aws.S3API, kmsc aws.KMSAPI) error {
+	allowed, err := release.allowedKMSKeys(s3c)
+	if err != nil {
+		return fmt.Errorf("Error Getting KMS key allowlist with %v", err.Error())
+	}
+
+	releaseKey, err := release.userDataKMSKeyID(kmsc)
+	if err != nil {
+		return fmt.Errorf("Error Describing user_data_kms_key with %v", err.Error())
+	}
+
+	paths := []*string{release.UserDataPath()}
+	for name, service := range release.Services {
+		if service != nil && service.OverridesUserData() {
+			paths = append(paths, release.ServiceUserDataPath(name))
+		}
+	}
+
+	for _, path := range paths {
+		out, err := s3c.HeadObject(&aws_s3.HeadObjectInput{Bucket: release.Bucket, Key: path})
+		if err != nil {
+			return fmt.Errorf("Error Getting UserData encryption with %v", err.Error())
+		}
+
+		if to.Strs(out.ServerSideEncryption) != "aws:kms" {
+			return fmt.Errorf("UserData %v must be KMS encrypted", *path)
+		}
+
+		if releaseKey != nil {
+			if !kmsKeyMatches(*releaseKey, out.SSEKMSKeyId) {
+				return fmt.Errorf("UserData %v encrypted with %v not user_data_kms_key %v", *path, to.Strs(out.SSEKMSKeyId), *release.UserDataKMSKey)
+			}
+		}
+
+		if allowed == nil {
+			continue
+		}
+
+		ok := false
+		for _, key := range allowed {
+			if kmsKeyMatches(key, out.SSEKMSKeyId) {
+				ok = true
+			}
+		}
+
+		if !ok {
+			return fmt.Errorf("UserData %v encrypted with KMS key %v which is not allowed", *path, to.Strs(out.SSEKMSKeyId))
+		}
+	}
+
+	return nil
+}
+
+// allowedKMSKeys returns the configs allowlist, else the projects, else nil if neither exist
+func (release *Release) allowedKMSKeys(s3c aws.S3API) ([]string, error) {
+	var allowlists KMSKeyAllowlists
+	err := s3.GetStruct(s3c, release.Bucket, release.KMSKeysPath(), &allowlists)
+
+	switch err.(type) {
+	case nil:
+	case *s3.NotFoundError:
+		return nil, nil
+	default:
+		return nil, err
+	}
+
+	var projectKeys []string
+	for _, allowlist := range allowlists.Allowlists {
+		if allowlist == nil || to.Strs(allowlist.ProjectName) != *release.ProjectName {
+			continue
+		}
+
+		if allowlist.ConfigName == nil {
+			projectKeys = allowlist.KMSKeyIDs
+		} else if *allowlist.ConfigName == *release.ConfigName {
+			return allowlist.KMSKeyIDs, nil
+		}
+	}
+
+	return projectKeys, nil
+}
+
+// userDataKMSKeyID returns the releases user_data_kms_key with any alias resolved to its key ARN
+// S3 returns the key ARN, so an alias would otherwise match any key
+func (release *Release) userDataKMSKeyID(kmsc aws.KMSAPI) (*string, error) {
+	key := release.UserDataKMSKey
+	if is.EmptyStr(key) || !(strings.HasPrefix(*key, "alias/") || strings.Contains(*key, ":alias/")) {
+		return key, nil
+	}
+
+	out, err := kmsc.DescribeKey(&kms.DescribeKeyInput{KeyId: key})
+	if err != nil {
+		return nil, err
+	}
+
+	if out.KeyMetadata == nil || is.EmptyStr(out.KeyMetadata.Arn) {
+		return nil, fmt.Errorf("KMS key %v not found", *key)
+	}
+
+	return out.KeyMetadata.Arn, nil
+}
+
+// kmsKeyMatches returns true if the key ID is the allowed key or its ARN ends with the allowed ID
+func kmsKeyMatches(allowed string, keyID *string) bool {
+	if keyID == nil {
+		return false
+	}
+
+	return *keyID == allowed || strings.HasSuffix(*keyID, fmt.Sprintf("/%v", allowed))
+}
+
 // UserData returns user data
 func (release *Release) UserData() *string {
 	return release.userdata
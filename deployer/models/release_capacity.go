@@ -0,0 +1,177 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/aws/quota"
+	"github.com/coinbase/step/utils/to"
+)
+
+//////////
+// Validate Capacity
+//////////
+
+// ValidateCapacity checks the account can launch the release before any ASG is created
+func (release *Release) ValidateCapacity(asgc aws.ASGAPI, ec2c aws.EC2API, cwc aws.CWAPI, sqc aws.ServiceQuotasAPI, resources *ReleaseResources) error {
+	if err := release.validateASGLimit(asgc); err != nil {
+		return err
+	}
+
+	for name, service := range release.Services {
+		sr := resources.ServiceResources[name]
+		if sr == nil {
+			return fmt.Errorf("%v ServiceResources nil for %v", release.ErrorPrefix(), name)
+		}
+
+		if err := service.validateInstanceTypeOfferings(ec2c, sr); err != nil {
+			return err
+		}
+	}
+
+	return release.validateVCPUQuotas(ec2c, cwc, sqc)
+}
+
+// validateASGLimit checks a new ASG can be created for each service
+// Odin uses launch templates, so the launch configuration limit does not apply
+func (release *Release) validateASGLimit(asgc aws.ASGAPI) error {
+	remaining, err := quota.RemainingASGs(asgc)
+	if err != nil {
+		return err
+	}
+
+	if remaining < int64(len(release.Services)) {
+		return fmt.Errorf("%v Auto Scaling group limit reached, %v remaining but %v required", release.ErrorPrefix(), remaining, len(release.Services))
+	}
+
+	return nil
+}
+
+// validateInstanceTypeOfferings checks every instance type is offered in every subnets availability zone
+func (service *Service) validateInstanceTypeOfferings(ec2c aws.EC2API, sr *ServiceResources) error {
+	azs := []*string{}
+	for _, s := range sr.Subnets {
+		if s != nil && s.AvailabilityZone != nil {
+			azs = append(azs, s.AvailabilityZone)
+		}
+	}
+
+	if len(azs) == 0 {
+		return nil
+	}
+
+	instanceTypes := service.instanceTypes()
+	offered, err := quota.OfferedInstanceTypes(ec2c, instanceTypes, azs)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sr.Subnets {
+		if s == nil || s.AvailabilityZone == nil {
+			continue
+		}
+
+		for _, it := range instanceTypes {
+			if !containsStr(offered[*s.AvailabilityZone], *it) {
+				return fmt.Errorf("%v instance type %v is not offered in %v of subnet %v", service.errorPrefix(), *it, *s.AvailabilityZone, to.Strs(s.SubnetID))
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateVCPUQuotas checks the On-Demand vCPUs launched fit in the remaining quotas
+func (release *Release) validateVCPUQuotas(ec2c aws.EC2API, cwc aws.CWAPI, sqc aws.ServiceQuotasAPI) error {
+	instanceTypes := []*string{}
+	for _, service := range release.Services {
+		for _, it := range service.instanceTypes() {
+			if !containsStr(to.StrSlice(instanceTypes), *it) {
+				instanceTypes = append(instanceTypes, it)
+			}
+		}
+	}
+
+	if len(instanceTypes) == 0 {
+		return nil
+	}
+
+	vcpus, err := quota.InstanceTypeVCPUs(ec2c, instanceTypes)
+	if err != nil {
+		return err
+	}
+
+	required := map[quota.VCPUQuota]int64{}
+	for _, service := range release.Services {
+		q, n := service.onDemandVCPUs(vcpus)
+		required[q] += n
+	}
+
+	// Deterministic order for the error messages
+	quotas := []quota.VCPUQuota{}
+	for q := range required {
+		quotas = append(quotas, q)
+	}
+	sort.Slice(quotas, func(i, j int) bool { return quotas[i].QuotaCode < quotas[j].QuotaCode })
+
+	for _, q := range quotas {
+		if required[q] == 0 {
+			continue
+		}
+
+		remaining, err := q.RemainingVCPUs(sqc, cwc, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if required[q] > remaining {
+			return fmt.Errorf("%v %v vCPU quota %v reached, %v remaining but %v required", release.ErrorPrefix(), q.Class, q.QuotaCode, remaining, required[q])
+		}
+	}
+
+	return nil
+}
+
+// instanceTypes returns all the instance types the service can launch
+func (service *Service) instanceTypes() []*string {
+	if service.Instances == nil {
+		return []*string{service.InstanceType}
+	}
+
+	types := []*string{}
+	for _, t := range service.Instances.Types {
+		if t != nil && t.InstanceType != nil {
+			types = append(types, t.InstanceType)
+		}
+	}
+
+	return types
+}
+
+// onDemandVCPUs returns the On-Demand vCPUs the service launches and the quota they count towards
+// With mixed instances it assumes the type with the most vCPUs per unit of capacity
+func (service *Service) onDemandVCPUs(vcpus map[string]int64) (quota.VCPUQuota, int64) {
+	capacity := service.strategy.TargetCapacity()
+
+	if service.Instances == nil {
+		q := quota.VCPUQuotaFor(to.Strs(service.InstanceType))
+		if service.SpotPrice != nil {
+			return q, 0 // All Spot
+		}
+		return q, capacity * vcpus[to.Strs(service.InstanceType)]
+	}
+
+	weights := service.Instances.TypeWeights()
+	largest, perUnit := "", float64(0)
+	for it, weight := range weights {
+		if r := float64(vcpus[it]) / float64(weight); r > perUnit || (r == perUnit && it < largest) {
+			largest, perUnit = it, r
+		}
+	}
+
+	onDemand := service.Instances.OnDemandCapacity(capacity)
+	return quota.VCPUQuotaFor(largest), int64(math.Ceil(float64(onDemand) * perUnit))
+}
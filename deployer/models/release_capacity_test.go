@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Service_OnDemandVCPUs(t *testing.T) {
+	service := &Service{
+		InstanceType: to.Strp("c5.large"),
+		Autoscaling:  &AutoScalingConfig{MinSize: to.Int64p(4), MaxSize: to.Int64p(4), Spread: to.Float64p(0)},
+	}
+	service.SetDefaults(MockMinimalRelease(t), "web")
+
+	vcpus := map[string]int64{"c5.large": 2, "c5.xlarge": 4, "p3.2xlarge": 8}
+
+	q, n := service.onDemandVCPUs(vcpus)
+	assert.Equal(t, "Standard/OnDemand", q.Class)
+	assert.Equal(t, int64(8), n)
+
+	// Spot does not count towards the On-Demand quota
+	service.SpotPrice = to.Strp("0.1")
+	_, n = service.onDemandVCPUs(vcpus)
+	assert.Equal(t, int64(0), n)
+
+	// Mixed instances assume the most vCPUs per unit of the On-Demand capacity
+	service.Instances = &InstancesConfig{
+		Types: []*InstanceTypeConfig{
+			&InstanceTypeConfig{InstanceType: to.Strp("c5.large")},
+			&InstanceTypeConfig{InstanceType: to.Strp("c5.xlarge"), Weight: to.Int64p(2)},
+			&InstanceTypeConfig{InstanceType: to.Strp("p3.2xlarge"), Weight: to.Int64p(8)},
+		},
+		OnDemandPercentageAboveBaseCapacity: to.Int64p(50),
+	}
+	q, n = service.onDemandVCPUs(vcpus)
+	assert.Equal(t, "Standard/OnDemand", q.Class)
+	assert.Equal(t, int64(4), n) // 2 On-Demand units of 2 vCPUs
+}
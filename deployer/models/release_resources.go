@@ -2,10 +2,14 @@ package models
 
 import (
 	"fmt"
+	"strings"
+	"time"
+
 	"github.com/coinbase/odin/aws"
 	"github.com/coinbase/odin/aws/ami"
 	"github.com/coinbase/odin/aws/asg"
 	"github.com/coinbase/odin/aws/subnet"
+	"github.com/coinbase/step/utils/to"
 )
 
 type ReleaseResources struct {
@@ -20,7 +24,7 @@ type ReleaseResources struct {
 
 // FetchResources checks the existence of all Resources references in this release
 // and returns a struct of the resources
-func (release *Release) FetchResources(asgc aws.ASGAPI, ec2 aws.EC2API, elbc aws.ELBAPI, albc aws.ALBAPI, iamc aws.IAMAPI, snsc aws.SNSAPI) (*ReleaseResources, error) {
+func (release *Release) FetchResources(asgc aws.ASGAPI, ec2 aws.EC2API, elbc aws.ELBAPI, albc aws.ALBAPI, iamc aws.IAMAPI, snsc aws.SNSAPI, sqsc aws.SQSAPI) (*ReleaseResources, error) {
 	resources := ReleaseResources{
 		ServiceResources: map[string]*ServiceResources{},
 	}
@@ -42,31 +46,41 @@ func (release *Release) FetchResources(asgc aws.ASGAPI, ec2 aws.EC2API, elbc aws
 
 	resources.PreviousASGs = prevASGs
 
-	// Fetch Subnets
-	subnets, err := subnet.Find(ec2, release.Subnets)
-	if err != nil {
-		return nil, err
-	}
-
-	// Fetch Image
-	im, err := ami.Find(ec2, release.Image)
-	if err != nil {
-		return nil, err
-	}
-
 	// LifeCycleHooks
 	for _, lc := range release.LifeCycleHooks {
-		if err := lc.FetchResources(iamc, snsc); err != nil {
+		if err := lc.FetchResources(iamc, snsc, sqsc); err != nil {
 			return nil, err
 		}
 	}
 
+	for _, service := range release.Services {
+		for _, lc := range service.LifeCycle {
+			if lc == nil {
+				continue
+			}
+
+			if err := lc.FetchResources(iamc, snsc, sqsc); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Rolling back to a retained release reuses its ASGs
+	retainedASGs, err := release.retainedForRollback(asgc, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	for _, prevASG := range resources.PreviousASGs {
 		// This grabs the first previous ASGs release ID
 		resources.PreviousReleaseID = prevASG.ReleaseID()
 		break
 	}
 
+	// Services can override the Subnets and Image, so each is only fetched once
+	subnetsCache := map[string][]*subnet.Subnet{}
+	imageCache := map[string]*ami.Image{}
+
 	slowStartDuration := 0
 	for name, service := range release.Services {
 		sr, err := service.FetchResources(ec2, elbc, albc, iamc)
@@ -74,6 +88,24 @@ func (release *Release) FetchResources(asgc aws.ASGAPI, ec2 aws.EC2API, elbc aws
 			return nil, err
 		}
 
+		subnetsKey := strings.Join(to.StrSlice(service.Subnets()), ",")
+		if _, ok := subnetsCache[subnetsKey]; !ok {
+			subnets, err := subnet.Find(ec2, service.Subnets())
+			if err != nil {
+				return nil, err
+			}
+			subnetsCache[subnetsKey] = subnets
+		}
+
+		imageKey := to.Strs(service.AMI())
+		if _, ok := imageCache[imageKey]; !ok {
+			im, err := ami.Find(ec2, service.AMI())
+			if err != nil {
+				return nil, err
+			}
+			imageCache[imageKey] = im
+		}
+
 		for _, tg := range sr.TargetGroups {
 			if tg.TargetGroupArn == nil {
 				continue
@@ -83,9 +115,10 @@ func (release *Release) FetchResources(asgc aws.ASGAPI, ec2 aws.EC2API, elbc aws
 			}
 		}
 
-		sr.Subnets = subnets
-		sr.Image = im
+		sr.Subnets = subnetsCache[subnetsKey]
+		sr.Image = imageCache[imageKey]
 		sr.PrevASG = resources.PreviousASGs[name]
+		sr.RetainedASG = retainedASGs[name]
 
 		resources.ServiceResources[name] = sr
 	}
@@ -121,7 +154,12 @@ func (release *Release) UpdateWithResources(resources *ReleaseResources) {
 			continue // Skip
 		}
 		if sr.PrevASG != nil {
-			service.PreviousDesiredCapacity = sr.PrevASG.DesiredCapacity
+			service.PreviousDesiredCapacity = service.previousCapacity(sr.PrevASG)
+		}
+
+		// A deploy during a scheduled peak should start at the peaks capacity
+		if schedule := service.Autoscaling.ActiveSchedule(time.Now()); schedule != nil {
+			service.ActiveSchedule = schedule.Name
 		}
 
 		service.Resources = sr.ToServiceResourceNames()
@@ -133,9 +171,9 @@ func (release *Release) UpdateWithResources(resources *ReleaseResources) {
 //////////
 
 // CreateResources returns
-func (release *Release) CreateResources(asgc aws.ASGAPI, cwc aws.CWAPI) error {
+func (release *Release) CreateResources(asgc aws.ASGAPI, ec2c aws.EC2API, cwc aws.CWAPI) error {
 	for _, service := range release.Services {
-		err := service.CreateResources(asgc, cwc)
+		err := service.CreateResources(asgc, ec2c, cwc)
 		if err != nil {
 			return err
 		}
@@ -150,12 +188,12 @@ func (release *Release) CreateResources(asgc aws.ASGAPI, cwc aws.CWAPI) error {
 
 // UpdateHealthy will try set the Healthy attribute
 // First Error is a Halting Error, Second Error is a Retry Error
-func (release *Release) UpdateHealthy(asgc aws.ASGAPI, elbc aws.ELBAPI, albc aws.ALBAPI) error {
+func (release *Release) UpdateHealthy(asgc aws.ASGAPI, elbc aws.ELBAPI, albc aws.ALBAPI, cwc aws.CWAPI) error {
 	healthy := true
 
 	for _, service := range release.Services {
 
-		if err := service.UpdateHealthy(asgc, elbc, albc); err != nil {
+		if err := service.UpdateHealthy(asgc, elbc, albc, cwc); err != nil {
 			return err
 		}
 
@@ -209,7 +247,7 @@ func (release *Release) DetachForSuccess(asgc aws.ASGAPI) error {
 }
 
 // SuccessfulTearDown returns
-func (release *Release) SuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI) error {
+func (release *Release) SuccessfulTearDown(asgc aws.ASGAPI, ec2c aws.EC2API, cwc aws.CWAPI) error {
 	// Tear down all resources in NOT in this release
 	asgs, err := asg.ForProjectConfigNOTReleaseID(asgc, release.ProjectName, release.ConfigName, release.ReleaseID)
 
@@ -224,11 +262,22 @@ func (release *Release) SuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI) error
 		}
 	}
 
-	// Delete all Previous Resources
+	// Delete all Previous Resources, except the ones retained for rollback
+	now := time.Now()
 	for _, asg := range asgs {
-		if err := asg.Teardown(asgc, cwc); err != nil {
+		retained, err := release.retainPrevious(asgc, asg, now)
+		if err != nil {
 			return err
 		}
+
+		if retained {
+			continue
+		}
+
+		if err := asg.Teardown(asgc, ec2c, cwc); err != nil {
+			return err
+		}
+		release.RecordEvent("ASGDeleted", asg.ServiceName(), *asg.AutoScalingGroupName, nil)
 	}
 
 	return nil
@@ -293,7 +342,7 @@ func (release *Release) DetachForFailure(asgc aws.ASGAPI) error {
 }
 
 // UnsuccessfulTearDown deletes the services we were trying to create because :(
-func (release *Release) UnsuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI) error {
+func (release *Release) UnsuccessfulTearDown(asgc aws.ASGAPI, ec2c aws.EC2API, cwc aws.CWAPI) error {
 	// Tear down all resources in this release
 	asgs, err := asg.ForProjectConfigReleaseID(asgc, release.ProjectName, release.ConfigName, release.ReleaseID)
 	if err != nil {
@@ -308,12 +357,14 @@ func (release *Release) UnsuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI) err
 
 	// Delete all Resources for this release
 	for _, asg := range asgs {
-		if err := asg.Teardown(asgc, cwc); err != nil {
+		if err := asg.Teardown(asgc, ec2c, cwc); err != nil {
 			return err
 		}
+		release.RecordEvent("ASGDeleted", asg.ServiceName(), *asg.AutoScalingGroupName, nil)
 	}
 
-	return nil
+	// Retained ASGs are otherwise only deleted by a successful release
+	return release.tearDownExpiredRetained(asgc, ec2c, cwc, time.Now())
 }
 
 // Errors
@@ -14,12 +14,31 @@ func Test_Release_FetchResources_Works(t *testing.T) {
 
 	awsc := MockAwsClients(r)
 
-	resources, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS)
+	resources, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS)
 	assert.NoError(t, err)
 
 	assert.Equal(t, 1, len(resources.ServiceResources))
 }
 
+func Test_Release_FetchResources_ServiceOverrides(t *testing.T) {
+	r := MockRelease(t)
+	r.Image = nil
+	r.Subnets = nil
+	r.Services["web"].Image = to.Strp("ubuntu")
+	r.Services["web"].ServiceSubnets = []*string{to.Strp("private-subnet")}
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+
+	resources, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS)
+	assert.NoError(t, err)
+	assert.NoError(t, r.ValidateResources(resources))
+
+	sr := resources.ServiceResources["web"]
+	assert.Equal(t, "ami-123456", *sr.Image.ImageID)
+	assert.Equal(t, 1, len(sr.Subnets))
+}
+
 func Test_Release_ValidateResources_Works(t *testing.T) {
 	// func (release *Release) ValidateResources(resources map[string]*ServiceResources) error {
 	r := MockRelease(t)
@@ -27,7 +46,7 @@ func Test_Release_ValidateResources_Works(t *testing.T) {
 
 	awsc := MockAwsClients(r)
 
-	sm, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS)
+	sm, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS)
 	assert.NoError(t, err)
 
 	assert.NoError(t, r.ValidateResources(sm))
@@ -40,7 +59,7 @@ func Test_Release_UpdateWithResources_Works(t *testing.T) {
 
 	awsc := MockAwsClients(r)
 
-	sm, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS)
+	sm, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS)
 	assert.NoError(t, err)
 
 	r.UpdateWithResources(sm)
@@ -50,46 +69,46 @@ func Test_Release_FetchResources_Stores_WaitForDetach(t *testing.T) {
 	r := MockRelease(t)
 	MockPrepareRelease(r)
 	awsc := MockAwsClients(r)
-	r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS)
+	r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS)
 	assert.Equal(t, 42, *r.WaitForDetach)
 }
 
 func Test_Release_CreateResources_Works(t *testing.T) {
-	// func (release *Release) CreateResources(asgc aws.ASGAPI, cwc aws.CWAPI) error {
+	// func (release *Release) CreateResources(asgc aws.ASGAPI, ec2c aws.EC2API, cwc aws.CWAPI) error {
 	r := MockRelease(t)
 	MockPrepareRelease(r)
 
 	awsc := MockAwsClients(r)
-	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.CW))
+	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.EC2, awsc.CW))
 }
 
 func Test_Release_UpdateHealthy_Works(t *testing.T) {
-	// func (release *Release) UpdateHealthy(asgc aws.ASGAPI, elbc aws.ELBAPI, albc aws.ALBAPI) error {
+	// func (release *Release) UpdateHealthy(asgc aws.ASGAPI, elbc aws.ELBAPI, albc aws.ALBAPI, cwc aws.CWAPI) error {
 	r := MockRelease(t)
 	MockPrepareRelease(r)
 
 	awsc := MockAwsClients(r)
 
-	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.CW))
-	assert.NoError(t, r.UpdateHealthy(awsc.ASG, awsc.ELB, awsc.ALB))
+	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.EC2, awsc.CW))
+	assert.NoError(t, r.UpdateHealthy(awsc.ASG, awsc.ELB, awsc.ALB, awsc.CW))
 }
 
 func Test_Release_SuccessfulTearDown_Works(t *testing.T) {
-	// func (release *Release) SuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI) error {
+	// func (release *Release) SuccessfulTearDown(asgc aws.ASGAPI, ec2c aws.EC2API, cwc aws.CWAPI) error {
 	r := MockRelease(t)
 	MockPrepareRelease(r)
 
 	awsc := MockAwsClients(r)
-	assert.NoError(t, r.SuccessfulTearDown(awsc.ASG, awsc.CW))
+	assert.NoError(t, r.SuccessfulTearDown(awsc.ASG, awsc.EC2, awsc.CW))
 }
 
 func Test_Release_UnsuccessfulTearDown_Works(t *testing.T) {
-	// func (release *Release) UnsuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI) error {
+	// func (release *Release) UnsuccessfulTearDown(asgc aws.ASGAPI, ec2c aws.EC2API, cwc aws.CWAPI) error {
 	r := MockRelease(t)
 	MockPrepareRelease(r)
 
 	awsc := MockAwsClients(r)
-	assert.NoError(t, r.UnsuccessfulTearDown(awsc.ASG, awsc.CW))
+	assert.NoError(t, r.UnsuccessfulTearDown(awsc.ASG, awsc.EC2, awsc.CW))
 }
 
 func Test_Release_ResetDesiredCapacity_Works(t *testing.T) {
@@ -116,3 +135,25 @@ func Test_Release_ResetDesiredCapacity_Works(t *testing.T) {
 	assert.Equal(t, int64(6), *awsc.ASG.UpdateAutoScalingGroupLastInput.DesiredCapacity)
 
 }
+
+func Test_Release_FetchResources_LifeCycleSQS(t *testing.T) {
+	r := MockRelease(t)
+	r.Services["web"].LifeCycle = map[string]*LifeCycleHook{
+		"WorkerHook": &LifeCycleHook{
+			Transistion: to.Strp("autoscaling:EC2_INSTANCE_TERMINATING"),
+			Role:        to.Strp("sns_role"),
+			SQS:         to.Strp("worker-queue"),
+		},
+	}
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+
+	_, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS)
+	assert.NoError(t, err)
+
+	awsc.SQS.MissingQueues = []string{"worker-queue"}
+
+	_, err = r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS)
+	assert.Error(t, err)
+}
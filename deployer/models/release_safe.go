@@ -22,11 +22,26 @@ import (
 // 5. EBS information
 // 6. AssociatePublicIpAddress
 func (release *Release) ValidateSafeRelease(s3c aws.S3API, resources *ReleaseResources) error {
-	if len(resources.PreviousASGs) == 0 {
+	previousRelease, err := release.PreviousRelease(s3c, resources)
+	if err != nil {
+		return err
+	}
+
+	if previousRelease == nil {
 		// If there are no currently deployed ASGs then we can ignore this check
 		return nil
 	}
 
+	// return an error for valid services
+	return release.validateSafeRelease(previousRelease)
+}
+
+// PreviousRelease returns the currently deployed release, or nil if nothing is deployed
+func (release *Release) PreviousRelease(s3c aws.S3API, resources *ReleaseResources) (*Release, error) {
+	if len(resources.PreviousASGs) == 0 {
+		return nil, nil
+	}
+
 	// Scaffold Previous Release
 	previousRelease := Release{
 		Release: bifrost.Release{
@@ -51,9 +66,9 @@ func (release *Release) ValidateSafeRelease(s3c aws.S3API, resources *ReleaseRes
 		switch err.(type) {
 		case *s3.NotFoundError:
 			// No lock to release
-			return fmt.Errorf("SafeRelease Error: Cannot find previous release s3://%v/%v", *previousRelease.Bucket, *previousRelease.ReleasePath())
+			return nil, fmt.Errorf("SafeRelease Error: Cannot find previous release s3://%v/%v", *previousRelease.Bucket, *previousRelease.ReleasePath())
 		default:
-			return err // All other errors return
+			return nil, err // All other errors return
 		}
 	}
 
@@ -61,8 +76,7 @@ func (release *Release) ValidateSafeRelease(s3c aws.S3API, resources *ReleaseRes
 	previousRelease.Release.SetDefaults(release.AwsRegion, release.AwsAccountID, "coinbase-odin-")
 	previousRelease.SetDefaults()
 
-	// return an error for valid services
-	return release.validateSafeRelease(&previousRelease)
+	return &previousRelease, nil
 }
 
 type SafeReleaseError struct {
@@ -75,6 +89,7 @@ type SafeReleaseError struct {
 }
 
 type SafeReleaseServiceError struct {
+	Subnets                  error
 	SecurityGroups           error
 	Profile                  error
 	ELBs                     error
@@ -100,6 +115,7 @@ func (sre *SafeReleaseError) Error() string {
 	errstr = appendError(errstr, sre.AllServices)
 	errstr = appendError(errstr, sre.MissingService)
 	for _, srse := range sre.Services {
+		errstr = appendError(errstr, srse.Subnets)
 		errstr = appendError(errstr, srse.SecurityGroups)
 		errstr = appendError(errstr, srse.Profile)
 		errstr = appendError(errstr, srse.ELBs)
@@ -129,6 +145,15 @@ func appendError(errstr string, err error) string {
 }
 
 func (release *Release) validateSafeRelease(previousRelease *Release) error {
+	if sre := release.Diff(previousRelease); sre != nil {
+		return sre
+	}
+
+	return nil
+}
+
+// Diff returns the differences with the previous release, or nil if there are none
+func (release *Release) Diff(previousRelease *Release) *SafeReleaseError {
 	sre := &SafeReleaseError{
 		Services: map[string]*SafeReleaseServiceError{},
 	}
@@ -173,6 +198,11 @@ func validateSafeServices(sre *SafeReleaseError, services map[string]*Service, p
 
 func validateSafeService(serviceName string, service *Service, prevService *Service) *SafeReleaseServiceError {
 	srse := &SafeReleaseServiceError{}
+	// 1. Subnets (services can override the releases subnets)
+	if res := safeUnorderedStrList(service.ServiceSubnets, prevService.ServiceSubnets); res != nil {
+		srse.Subnets = fmt.Errorf("SafeRelease Error(%v): Subnets different %v", serviceName, *res)
+	}
+
 	// 2. Security Groups or Profile
 
 	if res := safeUnorderedStrList(service.SecurityGroups, prevService.SecurityGroups); res != nil {
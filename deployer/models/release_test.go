@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/coinbase/step/utils/to"
@@ -14,7 +15,97 @@ func Test_Release_Validate_Works(t *testing.T) {
 
 	MockPrepareRelease(r)
 
-	assert.NoError(t, r.Validate(awsc.S3))
+	assert.NoError(t, r.Validate(awsc.S3, awsc.KMS))
+}
+
+func Test_Release_Validate_ServiceUserData(t *testing.T) {
+	r := MockRelease(t)
+	r.Services["web"].SetUserData(to.Strp("#service_config"))
+	awsc := MockAwsClients(r)
+	r.ReleaseSHA256 = to.SHA256Struct(r)
+
+	MockPrepareRelease(r)
+	assert.NoError(t, r.Validate(awsc.S3, awsc.KMS))
+
+	// The service gets its own userdata not the releases
+	assert.NoError(t, r.SetDefaultsWithUserData(awsc.S3))
+	assert.Equal(t, "#service_config", *r.Services["web"].UserData())
+
+	r.Services["web"].UserDataSHA256 = to.Strp("bad")
+	assert.Error(t, r.Validate(awsc.S3, awsc.KMS))
+}
+
+func Test_Release_Validate_AMI(t *testing.T) {
+	r := MockRelease(t)
+	r.Image = nil
+	awsc := MockAwsClients(r)
+	r.ReleaseSHA256 = to.SHA256Struct(r)
+
+	MockPrepareRelease(r)
+	assert.Error(t, r.Validate(awsc.S3, awsc.KMS))
+
+	r.Services["web"].Image = to.Strp("ubuntu")
+	assert.NoError(t, r.Validate(awsc.S3, awsc.KMS))
+}
+
+func Test_Release_Validate_UserDataKMSKey(t *testing.T) {
+	r := MockRelease(t)
+	awsc := MockAwsClients(r)
+	r.ReleaseSHA256 = to.SHA256Struct(r)
+	MockPrepareRelease(r)
+
+	keyARN := "arn:aws:kms:us-east-1:000000000000:key/1234"
+	awsc.S3.AddKMSKeyID(*r.UserDataPath(), keyARN)
+
+	// No allowlist allows any KMS key
+	assert.NoError(t, r.Validate(awsc.S3, awsc.KMS))
+
+	// The project allowlist
+	awsc.S3.AddGetObject(*r.KMSKeysPath(), `{"allowlists": [
+		{"project_name": "other", "kms_key_ids": ["1234"]},
+		{"project_name": "project", "kms_key_ids": ["other"]}
+	]}`, nil)
+	assert.Error(t, r.Validate(awsc.S3, awsc.KMS))
+
+	// The config allowlist overrides the projects, and matches by key ID
+	awsc.S3.AddGetObject(*r.KMSKeysPath(), `{"allowlists": [
+		{"project_name": "project", "config_name": "config", "kms_key_ids": ["1234"]},
+		{"project_name": "project", "kms_key_ids": ["other"]}
+	]}`, nil)
+	assert.NoError(t, r.Validate(awsc.S3, awsc.KMS))
+
+	// The object must be encrypted with the releases key
+	r.UserDataKMSKey = to.Strp("arn:aws:kms:us-east-1:000000000000:key/5678")
+	assert.Error(t, r.Validate(awsc.S3, awsc.KMS))
+
+	// Aliases are resolved to their key
+	r.UserDataKMSKey = to.Strp("alias/odin")
+	err := r.Validate(awsc.S3, awsc.KMS)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Error Describing user_data_kms_key")
+
+	awsc.KMS.AddAlias("alias/odin", "arn:aws:kms:us-east-1:000000000000:key/5678")
+	assert.Error(t, r.Validate(awsc.S3, awsc.KMS))
+
+	awsc.KMS.AddAlias("alias/odin", keyARN)
+	assert.NoError(t, r.Validate(awsc.S3, awsc.KMS))
+}
+
+func Test_Release_Validate_UserDataKMSKey_Planted(t *testing.T) {
+	r := MockRelease(t)
+	awsc := MockAwsClients(r)
+	r.ReleaseSHA256 = to.SHA256Struct(r)
+	MockPrepareRelease(r)
+
+	awsc.S3.AddKMSKeyID(*r.UserDataPath(), "arn:aws:kms:us-east-1:000000000000:key/1234")
+	awsc.S3.AddGetObject(*r.KMSKeysPath(), `{"allowlists": [{"project_name": "project", "kms_key_ids": ["other"]}]}`, nil)
+
+	// An allowlist uploaded to the deployer writable release path is ignored
+	awsc.S3.AddGetObject(fmt.Sprintf("%v/user_data_kms_keys.json", *r.RootDir()), `{"allowlists": [{"project_name": "project", "kms_key_ids": ["1234"]}]}`, nil)
+
+	err := r.Validate(awsc.S3, awsc.KMS)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "which is not allowed")
 }
 
 func Test_Release_ValidateServices_Works(t *testing.T) {
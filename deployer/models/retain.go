@@ -0,0 +1,137 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/aws/asg"
+	"github.com/coinbase/step/utils/to"
+)
+
+// RetainConfig keeps the previous releases ASGs detached and scaled down after a successful release
+// so a rollback to that release can reuse them instead of launching new instances
+type RetainConfig struct {
+	Capacity         *int64 `json:"capacity,omitempty"`          // Instances kept running, default 0
+	RetentionSeconds *int64 `json:"retention_seconds,omitempty"` // How long they can be rolled back to, default 1 day
+}
+
+// SetDefaults assigns default values
+func (r *RetainConfig) SetDefaults() {
+	if r.Capacity == nil {
+		r.Capacity = to.Int64p(0)
+	}
+
+	if r.RetentionSeconds == nil {
+		r.RetentionSeconds = to.Int64p(86400)
+	}
+}
+
+// ValidateAttributes validates attributes
+func (r *RetainConfig) ValidateAttributes() error {
+	if r.Capacity == nil || *r.Capacity < 0 {
+		return fmt.Errorf("Retain previous capacity must be at least 0")
+	}
+
+	if r.RetentionSeconds == nil || *r.RetentionSeconds < 60 {
+		return fmt.Errorf("Retain previous retention_seconds must be at least 60")
+	}
+
+	return nil
+}
+
+// RollbackReleaseID returns the release being rolled back to, set by "odin rollback"
+func (release *Release) RollbackReleaseID() *string {
+	id, ok := release.Metadata["rollback_release_id"]
+	if !ok || id == "" {
+		return nil
+	}
+	return &id
+}
+
+// retainedForRollback returns the retained ASGs of the release being rolled back to
+func (release *Release) retainedForRollback(asgc aws.ASGAPI, now time.Time) (map[string]*asg.ASG, error) {
+	rollbackID := release.RollbackReleaseID()
+	if rollbackID == nil {
+		return map[string]*asg.ASG{}, nil
+	}
+
+	retained, err := asg.RetainedForProjectConfigServiceMap(asgc, release.ProjectName, release.ConfigName, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, group := range retained {
+		if group.ReleaseID() == nil || *group.ReleaseID() != *rollbackID {
+			delete(retained, name)
+		}
+	}
+
+	return retained, nil
+}
+
+// retainPrevious retains the previous ASG unless it was already retained by another release
+// It returns false if the ASG should be torn down
+func (release *Release) retainPrevious(asgc aws.ASGAPI, group *asg.ASG, now time.Time) (bool, error) {
+	if group.IsRetained() {
+		// Retained by this release in an earlier attempt, else by an earlier release
+		by := group.RetainedBy()
+		return by != nil && *by == *release.ReleaseID, nil
+	}
+
+	if release.RetainPrevious == nil {
+		return false, nil
+	}
+
+	until := now.Add(time.Duration(*release.RetainPrevious.RetentionSeconds) * time.Second)
+	if err := group.Retain(asgc, release.ReleaseID, *release.RetainPrevious.Capacity, until); err != nil {
+		return false, err
+	}
+	release.RecordEvent("ASGRetained", group.ServiceName(), *group.AutoScalingGroupName, nil)
+
+	return true, nil
+}
+
+// tearDownExpiredRetained deletes the retained ASGs that can no longer be rolled back to
+func (release *Release) tearDownExpiredRetained(asgc aws.ASGAPI, ec2c aws.EC2API, cwc aws.CWAPI, now time.Time) error {
+	asgs, err := asg.ForProjectConfigNOTReleaseID(asgc, release.ProjectName, release.ConfigName, release.ReleaseID)
+	if err != nil {
+		return err
+	}
+
+	for _, group := range asgs {
+		if !group.IsRetained() || group.IsRollbackCandidate(now) {
+			continue
+		}
+
+		if err := release.validSuccessASG(group); err != nil {
+			return err
+		}
+
+		if err := group.Teardown(asgc, ec2c, cwc); err != nil {
+			return err
+		}
+		release.RecordEvent("ASGDeleted", group.ServiceName(), *group.AutoScalingGroupName, nil)
+	}
+
+	return nil
+}
+
+// restoreRetainedASG reuses the retained ASG found for the service instead of creating one
+func (service *Service) restoreRetainedASG(asgc aws.ASGAPI, now time.Time) (*asg.ASG, error) {
+	retained, err := service.release.retainedForRollback(asgc, now)
+	if err != nil {
+		return nil, err
+	}
+
+	group := retained[*service.ServiceName]
+	if group == nil || *group.ServiceID() != *service.Resources.RetainedASG {
+		return nil, fmt.Errorf("Retained ASG %v is no longer a rollback candidate", *service.Resources.RetainedASG)
+	}
+
+	if err := group.Restore(asgc, service.ReleaseID(), service.ReleaseUUID(), service.Resources.ELBs, service.Resources.TargetGroups); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func addRetainedASG(awsc *mocks.MockClients, releaseID string, until time.Time) string {
+	name := "project-config-web-" + releaseID
+	group := mocks.MakeMockASG(name, "project", "config", "web", releaseID)
+	group.LoadBalancerNames = nil
+	group.TargetGroupARNs = nil
+	group.Tags = append(group.Tags,
+		&autoscaling.TagDescription{Key: to.Strp("RetainUntil"), Value: to.Strp(until.Format(time.RFC3339))},
+		&autoscaling.TagDescription{Key: to.Strp("RetainedBy"), Value: to.Strp("old-release")},
+		&autoscaling.TagDescription{Key: to.Strp("RetainedMinSize"), Value: to.Strp("1")},
+		&autoscaling.TagDescription{Key: to.Strp("RetainedMaxSize"), Value: to.Strp("1")},
+		&autoscaling.TagDescription{Key: to.Strp("RetainedDesiredCapacity"), Value: to.Strp("1")},
+	)
+	awsc.ASG.AddASG(group)
+	return name
+}
+
+func deletedASGs(awsc *mocks.MockClients) []string {
+	names := []string{}
+	for _, input := range awsc.ASG.DeleteAutoScalingGroupInputs {
+		names = append(names, *input.AutoScalingGroupName)
+	}
+	return names
+}
+
+func Test_RetainConfig_ValidateAttributes(t *testing.T) {
+	r := &RetainConfig{}
+	r.SetDefaults()
+	assert.NoError(t, r.ValidateAttributes())
+	assert.EqualValues(t, 0, *r.Capacity)
+	assert.EqualValues(t, 86400, *r.RetentionSeconds)
+
+	assert.Error(t, (&RetainConfig{Capacity: to.Int64p(-1), RetentionSeconds: to.Int64p(60)}).ValidateAttributes())
+	assert.Error(t, (&RetainConfig{Capacity: to.Int64p(0), RetentionSeconds: to.Int64p(10)}).ValidateAttributes())
+}
+
+func Test_Release_RetainPrevious_SkipDetach(t *testing.T) {
+	r := MockRelease(t)
+	r.RetainPrevious = &RetainConfig{}
+	r.DetachStrategy = to.Strp("SkipDetach")
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+	assert.Error(t, r.Validate(awsc.S3, awsc.KMS))
+}
+
+func Test_Release_SuccessfulTearDown_RetainsPrevious(t *testing.T) {
+	r := MockRelease(t)
+	r.RetainPrevious = &RetainConfig{Capacity: to.Int64p(1), RetentionSeconds: to.Int64p(600)}
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+	older := addRetainedASG(awsc, "older-release", time.Now().Add(time.Hour))
+
+	assert.NoError(t, r.SuccessfulTearDown(awsc.ASG, awsc.EC2, awsc.CW))
+
+	// The previous ASG is retained, the one it retained is deleted
+	assert.Equal(t, []string{older}, deletedASGs(awsc))
+	assert.Equal(t, "project-config-web-old-release", *awsc.ASG.UpdateAutoScalingGroupLastInput.AutoScalingGroupName)
+	assert.EqualValues(t, 1, *awsc.ASG.UpdateAutoScalingGroupLastInput.DesiredCapacity)
+}
+
+func Test_Release_SuccessfulTearDown_WithoutRetain(t *testing.T) {
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+	older := addRetainedASG(awsc, "older-release", time.Now().Add(time.Hour))
+
+	assert.NoError(t, r.SuccessfulTearDown(awsc.ASG, awsc.EC2, awsc.CW))
+	assert.Equal(t, []string{"project-config-web-old-release", older}, deletedASGs(awsc))
+}
+
+func Test_Release_UnsuccessfulTearDown_DeletesExpiredRetained(t *testing.T) {
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+	expired := addRetainedASG(awsc, "expired-release", time.Now().Add(-time.Minute))
+	addRetainedASG(awsc, "retained-release", time.Now().Add(time.Hour))
+
+	assert.NoError(t, r.UnsuccessfulTearDown(awsc.ASG, awsc.EC2, awsc.CW))
+	assert.Equal(t, []string{expired}, deletedASGs(awsc))
+}
+
+func Test_Release_Rollback_RestoresRetained(t *testing.T) {
+	r := MockRelease(t)
+	r.Metadata = map[string]string{"rollback_release_id": "retained-release"}
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+	retained := addRetainedASG(awsc, "retained-release", time.Now().Add(time.Hour))
+
+	resources, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS)
+	assert.NoError(t, err)
+	assert.NoError(t, r.ValidateResources(resources))
+
+	sr := resources.ServiceResources["web"]
+	assert.Equal(t, retained, *sr.RetainedASG.ServiceID())
+	assert.Equal(t, "project-config-web-old-release", *sr.PrevASG.ServiceID())
+
+	r.UpdateWithResources(resources)
+	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.EC2, awsc.CW))
+
+	service := r.Services["web"]
+	assert.Equal(t, retained, *service.CreatedASG)
+	assert.Equal(t, []string{"web-elb"}, to.StrSlice(awsc.ASG.AttachLoadBalancersInputs[0].LoadBalancerNames))
+	assert.Equal(t, []string{"web-elb-target"}, to.StrSlice(awsc.ASG.AttachLoadBalancerTargetGroupsInputs[0].TargetGroupARNs))
+}
+
+func Test_Release_Rollback_IgnoresExpiredRetained(t *testing.T) {
+	r := MockRelease(t)
+	r.Metadata = map[string]string{"rollback_release_id": "retained-release"}
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+	addRetainedASG(awsc, "retained-release", time.Now().Add(-time.Minute))
+
+	resources, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS)
+	assert.NoError(t, err)
+	assert.Nil(t, resources.ServiceResources["web"].RetainedASG)
+}
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/coinbase/step/utils/to"
+)
+
+// RolloutConfig is an ordered list of steps to launch a service in
+// After the last step the service is scaled to its target capacity
+type RolloutConfig struct {
+	Steps []*RolloutStep `json:"steps,omitempty"`
+}
+
+// RolloutStep is a launched capacity to reach before the next step
+// Without wait_seconds the next step starts once the capacity is launched,
+// with it the capacity must also stay healthy for wait_seconds
+type RolloutStep struct {
+	Count       *int64   `json:"count,omitempty"`
+	Percent     *float64 `json:"percent,omitempty"` // Of the target capacity
+	WaitSeconds *int64   `json:"wait_seconds,omitempty"`
+	MaxTerms    *int64   `json:"max_terms,omitempty"` // Overrides the autoscaling max_terms during the step
+}
+
+// ValidateAttributes validates attributes
+func (r *RolloutConfig) ValidateAttributes() error {
+	if len(r.Steps) == 0 {
+		return fmt.Errorf("Rollout steps empty")
+	}
+
+	for i, step := range r.Steps {
+		if step == nil {
+			return fmt.Errorf("Rollout step %v nil", i)
+		}
+
+		if err := step.ValidateAttributes(); err != nil {
+			return fmt.Errorf("Rollout step %v %v", i, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// ValidateAttributes validates attributes
+func (step *RolloutStep) ValidateAttributes() error {
+	if (step.Count == nil) == (step.Percent == nil) {
+		return fmt.Errorf("requires one of count or percent")
+	}
+
+	if step.Count != nil && *step.Count < 1 {
+		return fmt.Errorf("count must be at least 1")
+	}
+
+	if step.Percent != nil && (*step.Percent <= 0 || *step.Percent > 100) {
+		return fmt.Errorf("percent must be greater than 0 and at most 100")
+	}
+
+	if step.WaitSeconds != nil && *step.WaitSeconds < 0 {
+		return fmt.Errorf("wait_seconds must be at least 0")
+	}
+
+	if step.MaxTerms != nil && *step.MaxTerms < 0 {
+		return fmt.Errorf("max_terms must be at least 0")
+	}
+
+	return nil
+}
+
+// capacity returns the steps capacity of the target capacity, at least 1
+func (step *RolloutStep) capacity(targetCapacity int64) int64 {
+	capacity := int64(0)
+	if step.Count != nil {
+		capacity = *step.Count
+	} else {
+		capacity = int64(float64(targetCapacity) * *step.Percent / 100)
+	}
+
+	return max(1, min(capacity, targetCapacity))
+}
+
+// wait returns how long the step must be healthy, nil if it need not be
+func (step *RolloutStep) wait() *time.Duration {
+	if step.WaitSeconds == nil {
+		return nil
+	}
+
+	wait := time.Duration(*step.WaitSeconds) * time.Second
+	return &wait
+}
+
+//////////
+// Presets
+//////////
+
+// rolloutPreset returns the rollout steps of a named strategy
+func rolloutPreset(a *AutoScalingConfig, targetCapacity int64) []*RolloutStep {
+	switch *a.Strategy {
+	case "OneThenAllWithCanary":
+		// The canary must be healthy for canary_bake_seconds and cannot terminate
+		wait := int64(0)
+		if a.CanaryBakeSeconds != nil {
+			wait = *a.CanaryBakeSeconds
+		}
+		return []*RolloutStep{&RolloutStep{Count: to.Int64p(1), WaitSeconds: to.Int64p(wait), MaxTerms: to.Int64p(0)}}
+	case "25PercentStepRolloutNoCanary":
+		return percentSteps(4)
+	case "10PercentStepRolloutNoCanary":
+		return percentSteps(10)
+	case "10AtATimeNoCanary":
+		return countSteps(10, targetCapacity)
+	case "20AtATimeNoCanary":
+		return countSteps(20, targetCapacity)
+	}
+
+	// "AllAtOnce" has no steps
+	return []*RolloutStep{}
+}
+
+// percentSteps divides the rollout into n equal steps
+func percentSteps(n int) []*RolloutStep {
+	steps := []*RolloutStep{}
+	for i := 1; i < n; i++ {
+		steps = append(steps, &RolloutStep{Percent: to.Float64p(float64(i*100) / float64(n))})
+	}
+	return steps
+}
+
+// countSteps adds increment instances each step
+func countSteps(increment int64, targetCapacity int64) []*RolloutStep {
+	steps := []*RolloutStep{}
+	for c := increment; c < targetCapacity; c += increment {
+		steps = append(steps, &RolloutStep{Count: to.Int64p(c)})
+	}
+	return steps
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
+	"github.com/robfig/cron/v3"
+)
+
+// How far back to look for the last run of a schedule
+var SCHEDULE_LOOKBACKS = []time.Duration{
+	time.Hour,
+	24 * time.Hour,
+	8 * 24 * time.Hour,
+	32 * 24 * time.Hour,
+	367 * 24 * time.Hour,
+}
+
+// Schedule is a scheduled scaling action created on the services ASG
+type Schedule struct {
+	Name            *string `json:"name,omitempty"`
+	Recurrence      *string `json:"recurrence,omitempty"` // Cron expression
+	TimeZone        *string `json:"time_zone,omitempty"`  // Default UTC
+	MinSize         *int64  `json:"min_size,omitempty"`
+	MaxSize         *int64  `json:"max_size,omitempty"`
+	DesiredCapacity *int64  `json:"desired_capacity,omitempty"`
+}
+
+// ValidateAttributes validates attributes
+func (s *Schedule) ValidateAttributes() error {
+	if s.Name == nil {
+		return fmt.Errorf("Schedule(?): name nil")
+	}
+
+	if _, err := s.schedule(); err != nil {
+		return fmt.Errorf("Schedule(%v): %v", *s.Name, err.Error())
+	}
+
+	if s.MinSize == nil && s.MaxSize == nil && s.DesiredCapacity == nil {
+		return fmt.Errorf("Schedule(%v): one of min_size, max_size or desired_capacity required", *s.Name)
+	}
+
+	if s.MinSize != nil && s.MaxSize != nil && *s.MinSize > *s.MaxSize {
+		return fmt.Errorf("Schedule(%v): min_size is greater than max_size", *s.Name)
+	}
+
+	if err := s.createInput(to.Strp("asgName")).Validate(); err != nil {
+		return fmt.Errorf("Schedule(%v): %v", *s.Name, err.Error())
+	}
+
+	return nil
+}
+
+func (s *Schedule) location() (*time.Location, error) {
+	if s.TimeZone == nil {
+		return time.UTC, nil
+	}
+
+	return time.LoadLocation(*s.TimeZone)
+}
+
+func (s *Schedule) schedule() (cron.Schedule, error) {
+	if s.Recurrence == nil {
+		return nil, fmt.Errorf("recurrence nil")
+	}
+
+	loc, err := s.location()
+	if err != nil {
+		return nil, fmt.Errorf("time_zone %v", err.Error())
+	}
+
+	sched, err := cron.ParseStandard(*s.Recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("recurrence %v", err.Error())
+	}
+
+	if spec, ok := sched.(*cron.SpecSchedule); ok {
+		spec.Location = loc
+	}
+
+	return sched, nil
+}
+
+// LastRun returns the last time the schedule ran before now, nil if it has not run in the last year
+func (s *Schedule) LastRun(now time.Time) *time.Time {
+	sched, err := s.schedule()
+	if err != nil {
+		return nil
+	}
+
+	for _, lookback := range SCHEDULE_LOOKBACKS {
+		var last *time.Time
+		for t := sched.Next(now.Add(-lookback)); !t.IsZero() && !t.After(now); t = sched.Next(t) {
+			run := t
+			last = &run
+		}
+
+		if last != nil {
+			return last
+		}
+	}
+
+	return nil
+}
+
+// Create creates the scheduled action on the ASG
+func (s *Schedule) Create(asgc aws.ASGAPI, asgName *string) error {
+	_, err := asgc.PutScheduledUpdateGroupAction(s.createInput(asgName))
+	return err
+}
+
+func (s *Schedule) createInput(asgName *string) *autoscaling.PutScheduledUpdateGroupActionInput {
+	return &autoscaling.PutScheduledUpdateGroupActionInput{
+		AutoScalingGroupName: asgName,
+		ScheduledActionName:  s.Name,
+		Recurrence:           s.Recurrence,
+		TimeZone:             s.TimeZone,
+		MinSize:              s.MinSize,
+		MaxSize:              s.MaxSize,
+		DesiredCapacity:      s.DesiredCapacity,
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Schedule_Valid(t *testing.T) {
+	s := &Schedule{
+		Name:       to.Strp("peak"),
+		Recurrence: to.Strp("0 8 * * MON-FRI"),
+		TimeZone:   to.Strp("America/New_York"),
+		MinSize:    to.Int64p(10),
+	}
+	assert.NoError(t, s.ValidateAttributes())
+
+	s.TimeZone = to.Strp("Nowhere/Special")
+	assert.Error(t, s.ValidateAttributes())
+
+	s.TimeZone = nil
+	s.Recurrence = to.Strp("every day")
+	assert.Error(t, s.ValidateAttributes())
+
+	s.Recurrence = to.Strp("0 8 * * *")
+	s.MinSize = nil
+	assert.Error(t, s.ValidateAttributes())
+
+	s.MinSize = to.Int64p(10)
+	s.MaxSize = to.Int64p(5)
+	assert.Error(t, s.ValidateAttributes())
+}
+
+func Test_Schedule_LastRun(t *testing.T) {
+	s := &Schedule{
+		Name:       to.Strp("peak"),
+		Recurrence: to.Strp("0 8 * * MON-FRI"),
+		TimeZone:   to.Strp("America/New_York"),
+	}
+
+	ny, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	// Tuesday 7am in New York, the last run was Monday 8am
+	now := time.Date(2020, 6, 2, 7, 0, 0, 0, ny).UTC()
+	assert.Equal(t, time.Date(2020, 6, 1, 8, 0, 0, 0, ny).Unix(), s.LastRun(now).Unix())
+
+	// Monday 9am ran an hour ago
+	now = time.Date(2020, 6, 1, 9, 0, 0, 0, ny)
+	assert.Equal(t, time.Date(2020, 6, 1, 8, 0, 0, 0, ny).Unix(), s.LastRun(now).Unix())
+
+	// Yearly schedules are found
+	s.Recurrence = to.Strp("0 0 1 1 *")
+	assert.Equal(t, time.Date(2020, 1, 1, 0, 0, 0, 0, ny).Unix(), s.LastRun(now).Unix())
+}
@@ -6,15 +6,16 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/service/autoscaling"
-	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/coinbase/odin/aws"
 	"github.com/coinbase/odin/aws/alb"
 	"github.com/coinbase/odin/aws/asg"
 	"github.com/coinbase/odin/aws/elb"
 	"github.com/coinbase/odin/aws/iam"
-	"github.com/coinbase/odin/aws/lc"
+	"github.com/coinbase/odin/aws/lt"
 	"github.com/coinbase/odin/aws/pg"
 	"github.com/coinbase/odin/aws/sg"
+	"github.com/coinbase/step/aws/s3"
 	"github.com/coinbase/step/utils/is"
 	"github.com/coinbase/step/utils/to"
 )
@@ -25,13 +26,32 @@ import (
 type HealthReport struct {
 	TargetHealthy  *int64   `json:"target_healthy,omitempty"`  // Number of instances aimed to to Launch
 	TargetLaunched *int64   `json:"target_launched,omitempty"` // Number of instances aimed to to Launch
-	Healthy        *int     `json:"healthy,omitempty"`         // Number of instances that are healthy
-	Launching      *int     `json:"launching,omitempty"`       // Number of instances that have been created
+	Healthy        *int     `json:"healthy,omitempty"`         // Number of instances that are healthy (weighted capacity)
+	Launching      *int     `json:"launching,omitempty"`       // Number of instances that have been created (weighted capacity)
 	Terminating    *int     `json:"terminating,omitempty"`     // Number of instances that are Terminating
 	TerminatingIDs []string `json:"terminating_ids,omitempty"` // Instance IDs that are Terminating
 
 	DesiredCapacity *int64 `json:"desired_capacity,omitempty"` // The current desired capacity goal
 	MinSize         *int64 `json:"min_size,omitempty"`         // The current min size
+
+	ErrorRate *float64 `json:"error_rate,omitempty"` // Highest share of 5xx responses from the ELBs and target groups
+
+	RolloutStep   *int64 `json:"rollout_step,omitempty"`   // The current rollout step, nil once complete
+	RolloutSteps  *int64 `json:"rollout_steps,omitempty"`  // Number of rollout steps
+	WaitRemaining *int64 `json:"wait_remaining,omitempty"` // Seconds the rollout step must stay healthy
+
+	AwaitingApproval bool `json:"awaiting_approval,omitempty"` // Paused until "odin continue"
+
+	TrafficPercent *int64 `json:"traffic_percent,omitempty"` // Percent of the listener rules traffic sent to the new target group
+}
+
+// CapacityReport is the capacity the strategy chooses for a service
+type CapacityReport struct {
+	MinSize         int64 `json:"min_size"`
+	MaxSize         int64 `json:"max_size"`
+	DesiredCapacity int64 `json:"desired_capacity"` // The final desired capacity
+	TargetLaunched  int64 `json:"target_launched"`  // Number of instances aimed to to Launch
+	TargetHealthy   int64 `json:"target_healthy"`   // Number of instances needed to be Healthy
 }
 
 // TYPES
@@ -51,10 +71,23 @@ type Service struct {
 	SecurityGroups []*string          `json:"security_groups,omitempty"`
 	Tags           map[string]*string `json:"tags,omitempty"`
 
+	// Override the release AMI, Subnets and UserData
+	Image          *string   `json:"ami,omitempty"`
+	ServiceSubnets []*string `json:"subnets,omitempty"`
+	UserDataSHA256 *string   `json:"user_data_sha256,omitempty"`
+
 	// Create Resources
 	InstanceType *string            `json:"instance_type,omitempty"`
 	Autoscaling  *AutoScalingConfig `json:"autoscaling,omitempty"`
 	SpotPrice    *string            `json:"spot_price,omitempty"`
+	Instances    *InstancesConfig   `json:"instances,omitempty"`
+	Health       *HealthConfig      `json:"health,omitempty"`
+
+	// Gradually move a listener rules traffic to this release
+	TrafficShift *TrafficShiftConfig `json:"traffic_shift,omitempty"`
+
+	// Added to or replacing the releases hooks, a null hook removes it
+	LifeCycle map[string]*LifeCycleHook `json:"lifecycle,omitempty"`
 
 	// Strategy contains all the information about how to scale
 	strategy *Strategy
@@ -81,10 +114,15 @@ type Service struct {
 	// Created Resources
 	CreatedASG              *string `json:"created_asg,omitempty"`
 	PreviousDesiredCapacity *int64  `json:"previous_desired_capacity,omitempty"`
+	ActiveSchedule          *string `json:"active_schedule,omitempty"`
 
 	// What is Healthy
-	HealthReport *HealthReport `json:"healthy_report,omitempty"`
-	Healthy      bool
+	HealthReport      *HealthReport `json:"healthy_report,omitempty"`
+	Healthy           bool
+	ErrorRateBreaches int64      `json:"error_rate_breaches,omitempty"`
+	RolloutStep       int64      `json:"rollout_step,omitempty"`
+	StepHealthyAt     *time.Time `json:"step_healthy_at,omitempty"` // When the rollout step became healthy
+	TrafficStep       int64      `json:"traffic_step,omitempty"`    // Number of traffic shift weights applied
 }
 
 //////////
@@ -131,13 +169,39 @@ func (service *Service) ServiceID() *string {
 	return to.Strp(fmt.Sprintf("%v-%v-%v-%v", *service.ProjectName(), *service.ConfigName(), tf, *service.ServiceName))
 }
 
-// Subnets returns subnets
+// Subnets returns the services subnets, or the releases if not overridden
 func (service *Service) Subnets() []*string {
+	if len(service.ServiceSubnets) > 0 {
+		return service.ServiceSubnets
+	}
 	return service.release.Subnets
 }
 
+// AMI returns the services image, or the releases if not overridden
+func (service *Service) AMI() *string {
+	if service.Image != nil {
+		return service.Image
+	}
+	return service.release.Image
+}
+
+// OverridesUserData is true if the service has its own userdata
+func (service *Service) OverridesUserData() bool {
+	return service.UserDataSHA256 != nil
+}
+
+// UserDataPath returns the path of the services own userdata
+func (service *Service) UserDataPath() *string {
+	return service.release.ServiceUserDataPath(*service.ServiceName)
+}
+
 // UserData will take the releases template and override
 func (service *Service) UserData() *string {
+	return service.template(service.userdata)
+}
+
+// template replaces the template values, e.g. {{RELEASE_ID}}, in str
+func (service *Service) template(str *string) *string {
 	templateARGs := []string{}
 	templateARGs = append(templateARGs, "{{RELEASE_ID}}", to.Strs(service.ReleaseID()))
 	templateARGs = append(templateARGs, "{{PROJECT_NAME}}", to.Strs(service.ProjectName()))
@@ -153,7 +217,7 @@ func (service *Service) UserData() *string {
 
 	replacer := strings.NewReplacer(templateARGs...)
 
-	return to.Strp(replacer.Replace(to.Strs(service.userdata)))
+	return to.Strp(replacer.Replace(to.Strs(str)))
 }
 
 // SetUserData sets the userdata
@@ -161,9 +225,57 @@ func (service *Service) SetUserData(userdata *string) {
 	service.userdata = userdata
 }
 
-// LifeCycleHooks returns
+// RawUserData returns the userdata before the template values are replaced
+func (service *Service) RawUserData() *string {
+	return service.userdata
+}
+
+// DownloadUserData fetches and populates the services own userdata from S3
+func (service *Service) DownloadUserData(s3c aws.S3API) error {
+	userdataBytes, err := s3.Get(s3c, service.release.Bucket, service.UserDataPath())
+
+	if err != nil {
+		return err
+	}
+
+	service.SetUserData(to.Strp(string(*userdataBytes)))
+	return nil
+}
+
+// ValidateUserDataSHA validates the services own userdata has the correct SHA
+func (service *Service) ValidateUserDataSHA(s3c aws.S3API) error {
+	if !service.OverridesUserData() {
+		return nil
+	}
+
+	if err := service.DownloadUserData(s3c); err != nil {
+		return fmt.Errorf("%v Error Getting UserData with %v", service.errorPrefix(), err.Error())
+	}
+
+	userdataSha := to.SHA256Str(service.RawUserData())
+	if userdataSha != *service.UserDataSHA256 {
+		return fmt.Errorf("%v UserData SHA incorrect expected %v, got %v", service.errorPrefix(), userdataSha, *service.UserDataSHA256)
+	}
+
+	return nil
+}
+
+// LifeCycleHooks returns the releases hooks merged with the services
 func (service *Service) LifeCycleHooks() map[string]*LifeCycleHook {
-	return service.release.LifeCycleHooks
+	lcs := map[string]*LifeCycleHook{}
+	for name, lc := range service.release.LifeCycleHooks {
+		lcs[name] = lc
+	}
+
+	for name, lc := range service.LifeCycle {
+		if lc == nil {
+			delete(lcs, name)
+			continue
+		}
+		lcs[name] = lc
+	}
+
+	return lcs
 }
 
 // SubnetIds returns
@@ -175,7 +287,11 @@ func (service *Service) SubnetIds() *string {
 func (service *Service) LifeCycleHookSpecs() []*autoscaling.LifecycleHookSpecification {
 	lcs := []*autoscaling.LifecycleHookSpecification{}
 	for _, lc := range service.LifeCycleHooks() {
-		lcs = append(lcs, lc.ToLifecycleHookSpecification())
+		spec := lc.ToLifecycleHookSpecification()
+		if lc.NotificationMetadata != nil {
+			spec.NotificationMetadata = service.template(lc.NotificationMetadata)
+		}
+		lcs = append(lcs, spec)
 	}
 	return lcs
 }
@@ -187,6 +303,17 @@ func (service *Service) errorPrefix() string {
 	return fmt.Sprintf("Service(%v) Error:", *service.ServiceName)
 }
 
+// Capacity returns the capacity the strategy chooses for the service
+func (service *Service) Capacity() *CapacityReport {
+	return &CapacityReport{
+		MinSize:         service.strategy.minSize,
+		MaxSize:         service.strategy.maxSize,
+		DesiredCapacity: service.strategy.DesiredCapacity(),
+		TargetLaunched:  service.strategy.TargetCapacity(),
+		TargetHealthy:   service.strategy.TargetHealthy(),
+	}
+}
+
 //////////
 // Setters
 //////////
@@ -210,7 +337,40 @@ func (service *Service) SetDefaults(release *Release, serviceName string) {
 
 	service.Autoscaling.SetDefaults(service.ServiceID(), service.release.Timeout)
 
-	service.strategy = NewStrategy(service.Autoscaling, service.PreviousDesiredCapacity)
+	if service.Health != nil {
+		service.Health.SetDefaults()
+	}
+
+	if service.TrafficShift != nil {
+		service.TrafficShift.SetDefaults()
+	}
+
+	for name, lc := range service.LifeCycle {
+		if lc != nil {
+			lc.SetDefaults(release.AwsRegion, release.AwsAccountID, name)
+		}
+	}
+
+	service.strategy = NewStrategy(service.scheduledAutoscaling(), service.scheduledPreviousCapacity())
+}
+
+// scheduledAutoscaling returns the autoscaling sizes with the active schedule applied
+func (service *Service) scheduledAutoscaling() *AutoScalingConfig {
+	return service.Autoscaling.Scheduled(service.Autoscaling.FindSchedule(service.ActiveSchedule))
+}
+
+// scheduledPreviousCapacity is at least the active schedules desired capacity
+func (service *Service) scheduledPreviousCapacity() *int64 {
+	schedule := service.Autoscaling.FindSchedule(service.ActiveSchedule)
+	if schedule == nil || schedule.DesiredCapacity == nil {
+		return service.PreviousDesiredCapacity
+	}
+
+	if service.PreviousDesiredCapacity != nil && *service.PreviousDesiredCapacity > *schedule.DesiredCapacity {
+		return service.PreviousDesiredCapacity
+	}
+
+	return schedule.DesiredCapacity
 }
 
 // setHealthy sets the health state from the instances
@@ -218,13 +378,17 @@ func (service *Service) setHealthy(group *asg.ASG, instances aws.Instances) {
 	healthy := instances.HealthyIDs()
 	terming := instances.TerminatingIDs()
 
+	// With a mixed instances policy an instance can count for more than one unit of capacity
+	weights := group.Weights()
+	healthyCapacity := weights.Capacity(healthy)
+
 	service.HealthReport = &HealthReport{
 		TargetHealthy:  to.Int64p(service.strategy.TargetHealthy()),
 		TargetLaunched: to.Int64p(service.strategy.TargetCapacity()),
-		Healthy:        to.Intp(len(healthy)),
+		Healthy:        to.Intp(int(healthyCapacity)),
 		Terminating:    to.Intp(len(terming)),
 		TerminatingIDs: terming,
-		Launching:      to.Intp(len(instances)),
+		Launching:      to.Intp(int(weights.Capacity(instances.InstanceIDs()))),
 
 		DesiredCapacity: group.DesiredCapacity,
 		MinSize:         group.MinSize,
@@ -232,7 +396,21 @@ func (service *Service) setHealthy(group *asg.ASG, instances aws.Instances) {
 
 	// The Service is Healthy if
 	// the number of instances that are healthy is greater than or equal to the target
-	service.Healthy = int64(len(healthy)) >= service.strategy.TargetHealthy()
+	service.Healthy = healthyCapacity >= service.strategy.TargetHealthy()
+}
+
+// previousCapacity returns the capacity of the previous ASG in units of this services weights
+func (service *Service) previousCapacity(prevASG *asg.ASG) *int64 {
+	if service.Instances == nil && !prevASG.IsWeighted() {
+		return prevASG.DesiredCapacity
+	}
+
+	capacity := prevASG.WeightedCapacity(service.Instances.TypeWeights())
+	if capacity == 0 {
+		return prevASG.DesiredCapacity
+	}
+
+	return to.Int64p(capacity)
 }
 
 //////////
@@ -245,6 +423,12 @@ func (service *Service) Validate() error {
 		return fmt.Errorf("%v %v", service.errorPrefix(), err.Error())
 	}
 
+	for name, lc := range service.LifeCycle {
+		if _, ok := service.release.LifeCycleHooks[name]; lc == nil && !ok {
+			return fmt.Errorf("%v LifeCycle %v is nil and not in the release", service.errorPrefix(), name)
+		}
+	}
+
 	for name, lc := range service.LifeCycleHooks() {
 		if lc == nil {
 			return fmt.Errorf("LifeCycle %v is nil", name)
@@ -261,7 +445,7 @@ func (service *Service) Validate() error {
 		return fmt.Errorf("%v %v", service.errorPrefix(), err.Error())
 	}
 
-	if err := service.createLaunchConfigurationInput().Validate(); err != nil {
+	if err := service.createLaunchTemplateInput().Validate(); err != nil {
 		return fmt.Errorf("%v %v", service.errorPrefix(), err.Error())
 	}
 
@@ -274,7 +458,11 @@ func (service *Service) ValidateAttributes() error {
 		return fmt.Errorf("ServiceName must be defined")
 	}
 
-	if is.EmptyStr(service.InstanceType) {
+	if service.Instances != nil {
+		if err := service.Instances.ValidateAttributes(); err != nil {
+			return err
+		}
+	} else if is.EmptyStr(service.InstanceType) {
 		return fmt.Errorf("InstanceType must be defined")
 	}
 
@@ -282,10 +470,30 @@ func (service *Service) ValidateAttributes() error {
 		return fmt.Errorf("Autoscaling must be defined")
 	}
 
+	if !is.UniqueStrp(service.ServiceSubnets) {
+		return fmt.Errorf("Subnets must be unique")
+	}
+
 	if err := service.Autoscaling.ValidateAttributes(); err != nil {
 		return err
 	}
 
+	for _, policy := range service.Autoscaling.Policies {
+		if policy.NeedsResourceLabel() && service.policyTargetGroup(policy) == nil {
+			return fmt.Errorf("Policy(%v): target_group must be one of the services target groups", *policy.Name())
+		}
+	}
+
+	if service.Health != nil {
+		if err := service.Health.ValidateAttributes(); err != nil {
+			return err
+		}
+
+		if service.Health.ErrorRate != nil && len(service.ELBs) == 0 && len(service.TargetGroups) == 0 {
+			return fmt.Errorf("Health error_rate requires an ELB or target group")
+		}
+	}
+
 	// Must have security groups
 	if len(service.SecurityGroups) < 1 {
 		return fmt.Errorf("Security Groups must be included")
@@ -305,6 +513,18 @@ func (service *Service) ValidateAttributes() error {
 		return fmt.Errorf("Non Unique TargetGroups")
 	}
 
+	if service.TrafficShift != nil {
+		if err := service.TrafficShift.ValidateAttributes(); err != nil {
+			return err
+		}
+
+		for _, tg := range service.TrafficShift.TargetGroups {
+			if containsStr(to.StrSlice(service.TargetGroups), *tg) {
+				return fmt.Errorf("Traffic shift target group %v cannot also be in target_groups", *tg)
+			}
+		}
+	}
+
 	if err := service.validatePlacementGroupAttributes(); err != nil {
 		return err
 	}
@@ -368,6 +588,11 @@ func (service *Service) FetchResources(ec2 aws.EC2API, elbc aws.ELBAPI, albc aws
 		return nil, err
 	}
 
+	trafficTargetGroups, trafficRule, err := service.fetchTrafficShift(albc)
+	if err != nil {
+		return nil, err
+	}
+
 	if service.PlacementGroupName != nil {
 		if err := pg.FindOrCreatePartitionGroup(
 			ec2,
@@ -394,6 +619,9 @@ func (service *Service) FetchResources(ec2 aws.EC2API, elbc aws.ELBAPI, albc aws
 		ELBs:           elbs,
 		TargetGroups:   targetGroups,
 		Profile:        iamProfile,
+
+		TrafficTargetGroups: trafficTargetGroups,
+		TrafficRule:         trafficRule,
 	}, nil
 }
 
@@ -401,10 +629,13 @@ func (service *Service) FetchResources(ec2 aws.EC2API, elbc aws.ELBAPI, albc aws
 // Create Resources
 //////////
 
-// CreateResources creates the ASG and Launch configuration for the service
-func (service *Service) CreateResources(asgc aws.ASGAPI, cwc aws.CWAPI) error {
+// CreateResources creates the ASG and Launch template for the service
+func (service *Service) CreateResources(asgc aws.ASGAPI, ec2c aws.EC2API, cwc aws.CWAPI) error {
+	if service.Resources.RetainedASG != nil {
+		return service.restoreResources(asgc)
+	}
 
-	err := service.createLaunchConfiguration(asgc)
+	err := service.createLaunchTemplate(ec2c)
 	if err != nil {
 		return err
 	}
@@ -416,11 +647,18 @@ func (service *Service) CreateResources(asgc aws.ASGAPI, cwc aws.CWAPI) error {
 	}
 
 	service.CreatedASG = createdASG.AutoScalingGroupName
+	service.recordEvent("ASGCreated", *service.CreatedASG, nil)
 
 	if err := service.createAutoScalingPolicies(asgc, cwc); err != nil {
 		return err
 	}
 
+	for _, schedule := range service.Autoscaling.Schedules {
+		if err := schedule.Create(asgc, service.ServiceID()); err != nil {
+			return err
+		}
+	}
+
 	service.setHealthy(createdASG, aws.Instances{})
 
 	if err := service.createMetricsCollection(asgc); err != nil {
@@ -430,18 +668,44 @@ func (service *Service) CreateResources(asgc aws.ASGAPI, cwc aws.CWAPI) error {
 	return nil
 }
 
+// restoreResources reuses the retained ASG, which still has its launch template, policies and hooks
+func (service *Service) restoreResources(asgc aws.ASGAPI) error {
+	restoredASG, err := service.restoreRetainedASG(asgc, time.Now())
+	if err != nil {
+		return err
+	}
+
+	service.CreatedASG = restoredASG.AutoScalingGroupName
+	service.recordEvent("ASGRestored", *service.CreatedASG, nil)
+
+	// Scheduled actions were removed when it was retained
+	for _, schedule := range service.Autoscaling.Schedules {
+		if err := schedule.Create(asgc, service.CreatedASG); err != nil {
+			return err
+		}
+	}
+
+	service.setHealthy(restoredASG, aws.Instances{})
+
+	return nil
+}
+
 func (service *Service) createInput() *asg.Input {
 	input := &asg.Input{&autoscaling.CreateAutoScalingGroupInput{}}
 
 	input.AutoScalingGroupName = service.ServiceID()
-	input.LaunchConfigurationName = service.ServiceID()
+	if service.Instances != nil {
+		input.MixedInstancesPolicy = service.Instances.MixedInstancesPolicy(lt.Specification(service.ServiceID()), service.SpotPrice)
+	} else {
+		input.LaunchTemplate = lt.Specification(service.ServiceID())
+	}
 
 	// Adjusted by strategy
 	input.MinSize = service.strategy.InitialMinSize()
 	input.DesiredCapacity = service.strategy.InitialDesiredCapacity()
 
-	// Unchanging values from AutoScalingConfig
-	input.MaxSize = service.Autoscaling.MaxSize
+	// Unchanging values from AutoScalingConfig, with any active schedule
+	input.MaxSize = to.Int64p(service.strategy.maxSize)
 	input.DefaultCooldown = service.Autoscaling.DefaultCooldown
 	input.HealthCheckGracePeriod = service.Autoscaling.HealthCheckGracePeriod
 
@@ -473,7 +737,7 @@ func (service *Service) createInput() *asg.Input {
 
 func (service *Service) createAutoScalingPolicies(asgc aws.ASGAPI, cwc aws.CWAPI) error {
 	for _, policy := range service.Autoscaling.Policies {
-		if err := policy.Create(asgc, cwc, service.ServiceID()); err != nil {
+		if err := policy.Create(asgc, cwc, service.ServiceID(), service.policyResourceLabel(policy)); err != nil {
 			return err
 		}
 	}
@@ -481,6 +745,34 @@ func (service *Service) createAutoScalingPolicies(asgc aws.ASGAPI, cwc aws.CWAPI
 	return nil
 }
 
+// policyTargetGroup returns the target group a policy tracks, by default the services only target group
+func (service *Service) policyTargetGroup(policy *Policy) *string {
+	if policy.TargetGroup == nil {
+		if len(service.TargetGroups) == 1 {
+			return service.TargetGroups[0]
+		}
+		return nil
+	}
+
+	for _, tg := range service.TargetGroups {
+		if tg != nil && *tg == *policy.TargetGroup {
+			return tg
+		}
+	}
+
+	return nil
+}
+
+// policyResourceLabel returns the resolved resource label of the policies target group
+func (service *Service) policyResourceLabel(policy *Policy) *string {
+	tg := service.policyTargetGroup(policy)
+	if !policy.NeedsResourceLabel() || tg == nil || service.Resources == nil {
+		return nil
+	}
+
+	return service.Resources.TargetGroupResourceLabels[*tg]
+}
+
 func (service *Service) createASG(asgc aws.ASGAPI) (*asg.ASG, error) {
 	input := service.createInput()
 
@@ -491,36 +783,42 @@ func (service *Service) createASG(asgc aws.ASGAPI) (*asg.ASG, error) {
 	return input.ToASG(), nil
 }
 
-func (service *Service) createLaunchConfigurationInput() *lc.LaunchConfigInput {
-	input := &lc.LaunchConfigInput{&autoscaling.CreateLaunchConfigurationInput{}}
-	input.SetDefaults()
+func (service *Service) createLaunchTemplateInput() *lt.LaunchTemplateInput {
+	input := &lt.LaunchTemplateInput{&ec2.CreateLaunchTemplateInput{}}
 
-	input.LaunchConfigurationName = service.ServiceID()
+	input.LaunchTemplateName = service.ServiceID()
 
-	if service.Resources != nil {
-		input.ImageId = service.Resources.Image
-		input.SecurityGroups = service.Resources.SecurityGroups
-		input.IamInstanceProfile = service.Resources.Profile
+	data := input.Data()
+	data.InstanceType = service.InstanceType
+	if data.InstanceType == nil && service.Instances != nil {
+		data.InstanceType = service.Instances.DefaultInstanceType()
 	}
-	input.InstanceType = service.InstanceType
-
-	input.AssociatePublicIpAddress = service.AssociatePublicIpAddress
+	data.UserData = to.Base64p(service.UserData())
 
-	input.UserData = to.Base64p(service.UserData())
+	if service.Resources != nil {
+		data.ImageId = service.Resources.Image
+		input.SetNetwork(service.Resources.SecurityGroups, service.AssociatePublicIpAddress)
+		input.SetIamInstanceProfile(service.Resources.Profile)
+	}
 
 	input.AddBlockDevice(service.EBSVolumeSize, service.EBSVolumeType, service.EBSDeviceName)
 
-	input.SpotPrice = service.SpotPrice
+	// A mixed instances policy sets the spot price itself
+	if service.Instances == nil {
+		input.SetSpotPrice(service.SpotPrice)
+	}
+
+	input.SetPlacementTenancy(service.PlacementTenancy)
 
-	input.PlacementTenancy = service.PlacementTenancy
+	input.SetDefaults()
 
 	return input
 }
 
-func (service *Service) createLaunchConfiguration(asgc autoscalingiface.AutoScalingAPI) error {
-	input := service.createLaunchConfigurationInput()
+func (service *Service) createLaunchTemplate(ec2c aws.EC2API) error {
+	input := service.createLaunchTemplateInput()
 
-	if err := input.Create(asgc); err != nil {
+	if err := input.Create(ec2c); err != nil {
 		return err
 	}
 
@@ -560,14 +858,14 @@ func (he *HaltError) Error() string {
 
 // UpdateHealthy updates the health status of the service
 // This might cause a Halt Error which will force the release to stop
-func (service *Service) UpdateHealthy(asgc aws.ASGAPI, elbc aws.ELBAPI, albc aws.ALBAPI) error {
+func (service *Service) UpdateHealthy(asgc aws.ASGAPI, elbc aws.ELBAPI, albc aws.ALBAPI, cwc aws.CWAPI) error {
 	all, group, err := asg.GetInstances(asgc, service.CreatedASG)
 	if err != nil {
 		return err // This might retry
 	}
 
 	// Early exit and Halt if there are instances Terminating
-	if service.strategy.ReachedMaxTerminations(all) {
+	if service.strategy.ReachedMaxTerminations(all, service.RolloutStep) {
 		err := fmt.Errorf("Found terming instances %v, %v", *service.ServiceName, strings.Join(all.TerminatingIDs(), ","))
 		return &HaltError{err} // This will immediately stop deploying
 	}
@@ -595,8 +893,29 @@ func (service *Service) UpdateHealthy(asgc aws.ASGAPI, elbc aws.ELBAPI, albc aws
 	// Set the Healthy Value
 	service.setHealthy(group, all) // TODO: maybe use the new min and dc
 
+	// Halt if serving too many errors for too long
+	if err := service.checkErrorRate(cwc, time.Now()); err != nil {
+		return err
+	}
+
+	// Not Healthy while serving too many errors
+	service.Healthy = service.Healthy && service.ErrorRateBreaches == 0
+
+	// Move to the next rollout step once the current step is launched, or healthy for its wait
+	if err := service.advanceRollout(group.Weights(), all, time.Now()); err != nil {
+		return err
+	}
+
+	// Not Healthy until the rollout is complete and approved
+	service.Healthy = service.Healthy && service.strategy.RolloutComplete(service.RolloutStep) && !service.approvalPending()
+
+	// Shift the next weight of traffic once healthy, not Healthy until it is all shifted
+	if err := service.shiftTraffic(albc); err != nil {
+		return err
+	}
+
 	// Use the strategy to calculate the new values of min_size and desired_capacity
-	min, dc := service.strategy.CalculateMinDesired(all)
+	min, dc := service.strategy.CalculateMinDesired(service.RolloutStep)
 
 	if err := service.SafeSetMinDesiredCapacity(asgc, group, min, dc); err != nil {
 		return fmt.Errorf("Setting Min and Desired Capacity Error for %v: %v", *service.ServiceName, err.Error())
@@ -605,6 +924,72 @@ func (service *Service) UpdateHealthy(asgc aws.ASGAPI, elbc aws.ELBAPI, albc aws
 	return nil
 }
 
+// advanceRollout moves to the next rollout step when the current step is done
+// It returns a HaltError if the step stops being healthy while waiting
+func (service *Service) advanceRollout(weights aws.Weights, instances aws.Instances, now time.Time) error {
+	step := service.strategy.Step(service.RolloutStep)
+	if step == nil {
+		// Without rollout steps the service waits for approval once launched
+		if service.HealthReport != nil {
+			service.HealthReport.AwaitingApproval = service.approvalPending()
+		}
+		return nil
+	}
+
+	if service.HealthReport != nil {
+		service.HealthReport.RolloutStep = to.Int64p(service.RolloutStep)
+		service.HealthReport.RolloutSteps = to.Int64p(int64(len(service.strategy.steps)))
+	}
+
+	capacity := service.strategy.StepCapacity(service.RolloutStep)
+
+	if step.wait() == nil {
+		if weights.Capacity(instances.InstanceIDs()) >= capacity {
+			service.nextRolloutStep()
+		}
+		return nil
+	}
+
+	if weights.Capacity(instances.HealthyIDs()) < capacity {
+		if service.StepHealthyAt != nil && service.strategy.WaitRemaining(service.RolloutStep, service.StepHealthyAt, now) > 0 {
+			err := fmt.Errorf("Rollout step %v unhealthy while waiting %v", service.RolloutStep, *service.ServiceName)
+			return &HaltError{err}
+		}
+
+		// Not healthy yet, or the wait was over so start it again
+		service.StepHealthyAt = nil
+		return nil
+	}
+
+	if service.StepHealthyAt == nil {
+		service.StepHealthyAt = &now
+	}
+
+	remaining := service.strategy.WaitRemaining(service.RolloutStep, service.StepHealthyAt, now)
+	if remaining > 0 {
+		if service.HealthReport != nil {
+			service.HealthReport.WaitRemaining = to.Int64p(int64(remaining.Round(time.Second) / time.Second))
+		}
+		return nil
+	}
+
+	service.nextRolloutStep()
+	return nil
+}
+
+// nextRolloutStep moves to the next rollout step unless it needs approval
+func (service *Service) nextRolloutStep() {
+	if service.pausedBefore(service.RolloutStep + 1) {
+		if service.HealthReport != nil {
+			service.HealthReport.AwaitingApproval = true
+		}
+		return
+	}
+
+	service.RolloutStep++
+	service.StepHealthyAt = nil
+}
+
 //////////
 // Update Resources
 //////////
@@ -628,7 +1013,18 @@ func (service *Service) SafeSetMinDesiredCapacity(asgc aws.ASGAPI, group *asg.AS
 		return nil
 	}
 
-	return service.SetMinDesiredCapacity(asgc, to.Int64p(minSize), to.Int64p(desiredCapacity))
+	if err := service.SetMinDesiredCapacity(asgc, to.Int64p(minSize), to.Int64p(desiredCapacity)); err != nil {
+		return err
+	}
+
+	service.recordEvent("CapacityChanged", to.Strs(service.CreatedASG), map[string]int64{
+		"previous_min_size":         *group.MinSize,
+		"previous_desired_capacity": *group.DesiredCapacity,
+		"min_size":                  minSize,
+		"desired_capacity":          desiredCapacity,
+	})
+
+	return nil
 }
 
 func (service *Service) SetMinDesiredCapacity(asgc aws.ASGAPI, minSize, desiredCapacity *int64) error {
@@ -645,7 +1041,7 @@ func (service *Service) SetMinDesiredCapacity(asgc aws.ASGAPI, minSize, desiredC
 func (service *Service) ResetDesiredCapacity(asgc aws.ASGAPI) error {
 	return service.SetMinDesiredCapacity(
 		asgc,
-		service.Autoscaling.MinSize,
+		to.Int64p(service.strategy.minSize),
 		to.Int64p(service.strategy.DesiredCapacity()),
 	)
 }
@@ -36,10 +36,14 @@ type ServiceResources struct {
 	Image          *ami.Image
 	Profile        *iam.Profile
 	PrevASG        *asg.ASG
+	RetainedASG    *asg.ASG
 	SecurityGroups []*sg.SecurityGroup
 	ELBs           []*elb.LoadBalancer
 	TargetGroups   []*alb.TargetGroup
 	Subnets        []*subnet.Subnet
+
+	TrafficTargetGroups []*alb.TargetGroup
+	TrafficRule         *alb.Rule
 }
 
 // ServiceResourceNames struct
@@ -47,10 +51,22 @@ type ServiceResourceNames struct {
 	Image          *string   `json:"image,omitempty"`
 	Profile        *string   `json:"profile_arn,omitempty"`
 	PrevASG        *string   `json:"prev_asg_arn,omitempty"`
+	RetainedASG    *string   `json:"retained_asg,omitempty"` // Restored instead of creating an ASG
 	SecurityGroups []*string `json:"security_groups,omitempty"`
 	ELBs           []*string `json:"elbs,omitempty"`
 	TargetGroups   []*string `json:"target_group_arns,omitempty"`
 	Subnets        []*string `json:"subnets,omitempty"`
+
+	// Target group name to its ALBRequestCountPerTarget resource label
+	TargetGroupResourceLabels map[string]*string `json:"target_group_resource_labels,omitempty"`
+
+	// The traffic shift rule, and the target group with traffic and the one the release attaches to
+	TrafficRule            *string `json:"traffic_rule_arn,omitempty"`
+	TrafficLiveTargetGroup *string `json:"traffic_live_target_group_arn,omitempty"`
+	TrafficNewTargetGroup  *string `json:"traffic_new_target_group_arn,omitempty"`
+
+	// The new target groups resource label, its error rate is checked before each weight
+	TrafficNewTargetGroupResourceLabel *string `json:"traffic_new_target_group_resource_label,omitempty"`
 }
 
 // ToServiceResourceNames returns
@@ -70,6 +86,11 @@ func (sr *ServiceResources) ToServiceResourceNames() *ServiceResourceNames {
 		prevASG = sr.PrevASG.AutoScalingGroupName
 	}
 
+	var retainedASG *string
+	if sr.RetainedASG != nil {
+		retainedASG = sr.RetainedASG.AutoScalingGroupName
+	}
+
 	sgs := []*string{}
 	for _, sg := range sr.SecurityGroups {
 		if sg == nil || is.EmptyStr(sg.GroupID) {
@@ -89,12 +110,35 @@ func (sr *ServiceResources) ToServiceResourceNames() *ServiceResourceNames {
 	}
 
 	tgs := []*string{}
+	labels := map[string]*string{}
 	for _, tg := range sr.TargetGroups {
 		if tg == nil || is.EmptyStr(tg.TargetGroupArn) {
 			continue
 		}
 
 		tgs = append(tgs, tg.TargetGroupArn)
+
+		if label := tg.ResourceLabel(); label != nil && tg.TargetGroupName != nil {
+			labels[*tg.TargetGroupName] = label
+		}
+	}
+
+	var trafficRule *string
+	if sr.TrafficRule != nil {
+		trafficRule = sr.TrafficRule.RuleArn
+	}
+
+	// The new ASG is attached to the traffic shift target group without traffic
+	liveTG, newTG := sr.trafficTargetGroups()
+	var newTGLabel *string
+	if newTG != nil {
+		tgs = append(tgs, newTG)
+
+		for _, tg := range sr.TrafficTargetGroups {
+			if to.Strs(tg.TargetGroupArn) == *newTG {
+				newTGLabel = tg.ResourceLabel()
+			}
+		}
 	}
 
 	subnets := []*string{}
@@ -110,10 +154,19 @@ func (sr *ServiceResources) ToServiceResourceNames() *ServiceResourceNames {
 		Image:          im,
 		Profile:        profile,
 		PrevASG:        prevASG,
+		RetainedASG:    retainedASG,
 		SecurityGroups: sgs,
 		ELBs:           elbs,
 		TargetGroups:   tgs,
 		Subnets:        subnets,
+
+		TargetGroupResourceLabels: labels,
+
+		TrafficRule:            trafficRule,
+		TrafficLiveTargetGroup: liveTG,
+		TrafficNewTargetGroup:  newTG,
+
+		TrafficNewTargetGroupResourceLabel: newTGLabel,
 	}
 }
 
@@ -137,6 +190,10 @@ func (sr *ServiceResources) Validate(service *Service) error {
 		return err
 	}
 
+	if err := ValidatePrevASG(service, sr.RetainedASG); err != nil {
+		return err
+	}
+
 	for _, r := range sr.Subnets {
 		if err := ValidateSubnet(service, r); err != nil {
 			return err
@@ -161,6 +218,32 @@ func (sr *ServiceResources) Validate(service *Service) error {
 		}
 	}
 
+	if err := sr.validateTrafficShift(service); err != nil {
+		return err
+	}
+
+	// ALBRequestCountPerTarget needs the target groups load balancer
+	names := sr.ToServiceResourceNames()
+	for _, policy := range service.Autoscaling.Policies {
+		if !policy.NeedsResourceLabel() {
+			continue
+		}
+
+		tg := service.policyTargetGroup(policy)
+		if tg == nil || names.TargetGroupResourceLabels[*tg] == nil {
+			return fmt.Errorf("Policy(%v): target group %v must be attached to exactly one load balancer", *policy.Name(), to.Strs(tg))
+		}
+	}
+
+	// Target group error rates are read with the load balancer
+	if service.Health != nil && service.Health.ErrorRate != nil {
+		for _, tg := range service.TargetGroups {
+			if tg == nil || names.TargetGroupResourceLabels[*tg] == nil {
+				return fmt.Errorf("Health error_rate target group %v must be attached to exactly one load balancer", to.Strs(tg))
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -3,7 +3,9 @@ package models
 import (
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/coinbase/odin/aws"
 	"github.com/coinbase/odin/aws/asg"
 	"github.com/coinbase/odin/aws/mocks"
 	"github.com/coinbase/step/utils/to"
@@ -32,6 +34,195 @@ func Test_Service_CreateInput_HealthCheckGracePeriod(t *testing.T) {
 	assert.Equal(t, *input.HealthCheckGracePeriod, int64(10))
 }
 
+func Test_Service_CreateInput_LaunchTemplate(t *testing.T) {
+	release := MockMinimalRelease(t)
+
+	service := Service{}
+	service.SetDefaults(release, "web")
+
+	input := service.createInput()
+	assert.Nil(t, input.LaunchConfigurationName)
+	assert.Equal(t, *service.ServiceID(), *input.LaunchTemplate.LaunchTemplateName)
+
+	ltInput := service.createLaunchTemplateInput()
+	assert.Equal(t, *service.ServiceID(), *ltInput.LaunchTemplateName)
+	assert.Equal(t, "t2.nano", *ltInput.LaunchTemplateData.InstanceType)
+}
+
+func Test_Service_CreateInput_MixedInstances(t *testing.T) {
+	release := MockMinimalRelease(t)
+
+	service := Service{
+		SpotPrice: to.Strp("0.1"),
+		Instances: &InstancesConfig{
+			Types: []*InstanceTypeConfig{
+				&InstanceTypeConfig{InstanceType: to.Strp("c5.large")},
+				&InstanceTypeConfig{InstanceType: to.Strp("c5.xlarge"), Weight: to.Int64p(2)},
+			},
+			OnDemandBaseCapacity:                to.Int64p(1),
+			OnDemandPercentageAboveBaseCapacity: to.Int64p(50),
+			SpotAllocationStrategy:              to.Strp("capacity-optimized"),
+		},
+	}
+	service.SetDefaults(release, "web")
+	assert.NoError(t, service.Instances.ValidateAttributes())
+
+	input := service.createInput()
+	assert.Nil(t, input.LaunchTemplate)
+
+	mip := input.MixedInstancesPolicy
+	assert.Equal(t, *service.ServiceID(), *mip.LaunchTemplate.LaunchTemplateSpecification.LaunchTemplateName)
+	assert.Equal(t, 2, len(mip.LaunchTemplate.Overrides))
+	assert.Nil(t, mip.LaunchTemplate.Overrides[0].WeightedCapacity)
+	assert.Equal(t, "2", *mip.LaunchTemplate.Overrides[1].WeightedCapacity)
+	assert.Equal(t, "0.1", *mip.InstancesDistribution.SpotMaxPrice)
+
+	// The spot price is set by the policy not the template
+	ltInput := service.createLaunchTemplateInput()
+	assert.Equal(t, "c5.large", *ltInput.LaunchTemplateData.InstanceType)
+	assert.Nil(t, ltInput.LaunchTemplateData.InstanceMarketOptions)
+}
+
+func Test_Service_MixedInstances_Validation(t *testing.T) {
+	instances := &InstancesConfig{}
+	assert.Error(t, instances.ValidateAttributes())
+
+	instances.Types = []*InstanceTypeConfig{&InstanceTypeConfig{InstanceType: to.Strp("c5.large")}}
+	assert.NoError(t, instances.ValidateAttributes())
+
+	instances.Types[0].Weight = to.Int64p(0)
+	assert.Error(t, instances.ValidateAttributes())
+	instances.Types[0].Weight = nil
+
+	instances.Types = append(instances.Types, &InstanceTypeConfig{InstanceType: to.Strp("c5.large")})
+	assert.Error(t, instances.ValidateAttributes())
+	instances.Types = instances.Types[:1]
+
+	instances.OnDemandPercentageAboveBaseCapacity = to.Int64p(101)
+	assert.Error(t, instances.ValidateAttributes())
+	instances.OnDemandPercentageAboveBaseCapacity = nil
+
+	instances.SpotAllocationStrategy = to.Strp("cheapest")
+	assert.Error(t, instances.ValidateAttributes())
+}
+
+func Test_Service_SetHealthy_Weighted(t *testing.T) {
+	// 2 healthy instances of weight 3 and 1 unhealthy
+	asgc := &mocks.ASGClient{}
+	group := mocks.MakeMockASG("name", "project", "config", "web", "release")
+	group.Instances = mocks.MakeMockASGInstances(2, 1, 0)
+	for _, i := range group.Instances {
+		i.WeightedCapacity = to.Strp("3")
+	}
+	asgc.AddASG(group)
+
+	instances, found, err := asg.GetInstances(asgc, to.Strp("name"))
+	assert.NoError(t, err)
+
+	service := &Service{
+		Autoscaling: &AutoScalingConfig{
+			MinSize: to.Int64p(6),
+			MaxSize: to.Int64p(9),
+		},
+	}
+	service.SetDefaults(&Release{}, "web")
+
+	service.setHealthy(found, instances)
+	assert.Equal(t, 6, *service.HealthReport.Healthy)
+	assert.Equal(t, 9, *service.HealthReport.Launching)
+	assert.True(t, service.Healthy)
+}
+
+func Test_Service_AdvanceRollout_CanaryBake(t *testing.T) {
+	service := &Service{
+		Autoscaling: &AutoScalingConfig{
+			MinSize:           to.Int64p(5),
+			MaxSize:           to.Int64p(10),
+			Strategy:          to.Strp("OneThenAllWithCanary"),
+			CanaryBakeSeconds: to.Int64p(600),
+		},
+		HealthReport: &HealthReport{},
+	}
+	service.SetDefaults(&Release{}, "web")
+
+	now := time.Now()
+
+	// Not yet healthy, so not baking
+	assert.NoError(t, service.advanceRollout(nil, oneUnHealthy, now))
+	assert.Nil(t, service.StepHealthyAt)
+	assert.EqualValues(t, 0, service.RolloutStep)
+
+	// Healthy starts baking
+	assert.NoError(t, service.advanceRollout(nil, oneGood, now))
+	assert.Equal(t, now, *service.StepHealthyAt)
+	assert.EqualValues(t, 600, *service.HealthReport.WaitRemaining)
+	assert.EqualValues(t, 0, service.RolloutStep)
+
+	assert.NoError(t, service.advanceRollout(nil, oneGood, now.Add(4*time.Minute)))
+	assert.EqualValues(t, 360, *service.HealthReport.WaitRemaining)
+
+	// Unhealthy while baking halts
+	err := service.advanceRollout(nil, oneUnHealthy, now.Add(5*time.Minute))
+	assert.IsType(t, &HaltError{}, err)
+
+	// Baked
+	assert.NoError(t, service.advanceRollout(nil, oneGood, now.Add(10*time.Minute)))
+	assert.EqualValues(t, 1, service.RolloutStep)
+	assert.Nil(t, service.StepHealthyAt)
+	assert.True(t, service.strategy.RolloutComplete(service.RolloutStep))
+}
+
+func Test_Service_AdvanceRollout_Launched(t *testing.T) {
+	service := &Service{
+		Autoscaling: &AutoScalingConfig{
+			MinSize: to.Int64p(4),
+			MaxSize: to.Int64p(4),
+			Rollout: &RolloutConfig{
+				Steps: []*RolloutStep{&RolloutStep{Count: to.Int64p(2)}},
+			},
+		},
+	}
+	service.SetDefaults(&Release{}, "web")
+
+	// Steps without a wait move on once launched, healthy or not
+	assert.NoError(t, service.advanceRollout(nil, oneUnHealthy, time.Now()))
+	assert.EqualValues(t, 0, service.RolloutStep)
+
+	assert.NoError(t, service.advanceRollout(nil, twoLaunching, time.Now()))
+	assert.EqualValues(t, 1, service.RolloutStep)
+
+	// Weighted capacity counts
+	service.RolloutStep = 0
+	assert.NoError(t, service.advanceRollout(aws.Weights{"one": 2}, oneUnHealthy, time.Now()))
+	assert.EqualValues(t, 1, service.RolloutStep)
+}
+
+func Test_Service_PreviousCapacity_Weighted(t *testing.T) {
+	asgc := &mocks.ASGClient{}
+	group := mocks.MakeMockASG("name", "project", "config", "web", "release")
+	group.DesiredCapacity = to.Int64p(2)
+	group.Instances = mocks.MakeMockASGInstances(2, 0, 0)
+	for _, i := range group.Instances {
+		i.InstanceType = to.Strp("c5.xlarge")
+	}
+	asgc.AddASG(group)
+
+	_, prev, err := asg.GetInstances(asgc, to.Strp("name"))
+	assert.NoError(t, err)
+
+	// Without weights the desired capacity is used
+	service := &Service{}
+	assert.EqualValues(t, 2, *service.previousCapacity(prev))
+
+	// Converted to capacity units with the new weights
+	service.Instances = &InstancesConfig{
+		Types: []*InstanceTypeConfig{
+			&InstanceTypeConfig{InstanceType: to.Strp("c5.xlarge"), Weight: to.Int64p(4)},
+		},
+	}
+	assert.EqualValues(t, 8, *service.previousCapacity(prev))
+}
+
 func Test_Service_PlacementgroupValidation(t *testing.T) {
 	// bad strat
 	service := Service{
@@ -129,3 +320,66 @@ func Test_Service_SafeSetMinDesiredCapacity_Works(t *testing.T) {
 	assert.Equal(t, int64(3), *awsc.ASG.UpdateAutoScalingGroupLastInput.DesiredCapacity)
 	assert.Equal(t, int64(2), *awsc.ASG.UpdateAutoScalingGroupLastInput.MinSize)
 }
+
+func Test_Service_ActiveSchedule_Capacity(t *testing.T) {
+	service := &Service{
+		Autoscaling: &AutoScalingConfig{
+			MinSize: to.Int64p(2),
+			MaxSize: to.Int64p(10),
+			Schedules: []*Schedule{
+				&Schedule{Name: to.Strp("peak"), Recurrence: to.Strp("0 8 * * *"), MinSize: to.Int64p(6), MaxSize: to.Int64p(20), DesiredCapacity: to.Int64p(12)},
+			},
+		},
+		PreviousDesiredCapacity: to.Int64p(3),
+	}
+
+	service.SetDefaults(MockMinimalRelease(t), "web")
+	assert.EqualValues(t, 3, service.strategy.DesiredCapacity())
+
+	// Deploying during the peak starts at the peaks capacity
+	service.ActiveSchedule = to.Strp("peak")
+	service.SetDefaults(MockMinimalRelease(t), "web")
+	assert.EqualValues(t, 6, service.Capacity().MinSize)
+	assert.EqualValues(t, 12, service.strategy.DesiredCapacity())
+	assert.EqualValues(t, 20, *service.createInput().MaxSize)
+
+	awsc := mocks.MockAWS()
+	assert.NoError(t, service.ResetDesiredCapacity(awsc.ASG))
+	assert.EqualValues(t, 6, *awsc.ASG.UpdateAutoScalingGroupLastInput.MinSize)
+
+	assert.NoError(t, service.CreateResources(awsc.ASG, awsc.EC2, awsc.CW))
+	assert.Equal(t, 1, len(awsc.ASG.PutScheduledUpdateGroupActionInputs))
+	assert.Equal(t, "peak", *awsc.ASG.PutScheduledUpdateGroupActionInputs[0].ScheduledActionName)
+	assert.Equal(t, *service.ServiceID(), *awsc.ASG.PutScheduledUpdateGroupActionInputs[0].AutoScalingGroupName)
+}
+
+func Test_Service_LifeCycleHooks(t *testing.T) {
+	release := MockRelease(t)
+	MockPrepareRelease(release)
+
+	service := release.Services["web"]
+	service.LifeCycle = map[string]*LifeCycleHook{
+		"TermHook": nil,
+		"WorkerHook": &LifeCycleHook{
+			Transistion:          to.Strp("autoscaling:EC2_INSTANCE_TERMINATING"),
+			HeartbeatTimeout:     to.Int64p(1800),
+			DefaultResult:        to.Strp("CONTINUE"),
+			NotificationMetadata: to.Strp(`{"release":"{{RELEASE_ID}}","service":"{{SERVICE_NAME}}"}`),
+		},
+	}
+	release.SetDefaults()
+
+	assert.NoError(t, service.Validate())
+
+	specs := service.LifeCycleHookSpecs()
+	assert.Equal(t, 1, len(specs))
+	assert.Equal(t, "WorkerHook", *specs[0].LifecycleHookName)
+	assert.Equal(t, "CONTINUE", *specs[0].DefaultResult)
+	assert.Equal(t, `{"release":"1","service":"web"}`, *specs[0].NotificationMetadata)
+
+	// The release hook is unchanged
+	assert.NotNil(t, release.LifeCycleHooks["TermHook"])
+
+	service.LifeCycle["Unknown"] = nil
+	assert.Error(t, service.Validate())
+}
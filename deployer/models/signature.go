@@ -0,0 +1,250 @@
+package models
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/utils/to"
+
+	// Register the hashes used by the KMS signing algorithms
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// ReleaseSignature signs the release and its userdata SHA256
+type ReleaseSignature struct {
+	Algorithm *string `json:"algorithm,omitempty"` // "ED25519" or a KMS signing algorithm e.g. "ECDSA_SHA_256"
+	Signature *string `json:"signature,omitempty"` // base64 encoded
+	KeyID     *string `json:"key_id,omitempty"`    // The key the client signed with, for information only
+}
+
+// SigningKeys are the public keys allowed to sign each project configs releases
+type SigningKeys struct {
+	SigningKeys []*ProjectSigningKeys `json:"signing_keys"`
+}
+
+// ProjectSigningKeys are the public keys allowed to sign a project configs releases
+type ProjectSigningKeys struct {
+	ProjectName *string           `json:"project_name,omitempty"`
+	ConfigName  *string           `json:"config_name,omitempty"` // Every config of the project if nil
+	PublicKeys  map[string]string `json:"public_keys"`           // Name to PEM or base64 DER encoded public key
+}
+
+// SigningKeysPath returns the path of the signing keys, at the root of the bucket so deployers cannot write it
+func (release *Release) SigningKeysPath() *string {
+	return to.Strp("signing_keys.json")
+}
+
+// RequireSignature rejects the release if it is unsigned, even if it has no signing keys
+func (release *Release) RequireSignature() {
+	release.signatureRequired = true
+}
+
+// SignedMessage returns the message that is signed, the SHA256 of the release without its signature and the userdata SHA256
+func (release *Release) SignedMessage() []byte {
+	unsigned := *release
+	unsigned.Signature = nil
+
+	return []byte(fmt.Sprintf(
+		"odin release %v\nuser_data_sha256 %v\n",
+		to.SHA256Struct(&unsigned),
+		to.Strs(release.UserDataSHA256),
+	))
+}
+
+// ValidateUploadedSignature validates the signature of the release uploaded to S3
+// It is checked by the Validate handler, not Validate, so "odin plan" does not need a signing key
+func (release *Release) ValidateUploadedSignature(s3c aws.S3API) error {
+	uploaded := &Release{}
+	if err := s3.GetStruct(s3c, release.Bucket, release.ReleasePath(), uploaded); err != nil {
+		return fmt.Errorf("%v Error Getting release with %v", release.ErrorPrefix(), err.Error())
+	}
+
+	if err := release.ValidateSignature(s3c, uploaded); err != nil {
+		return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
+	}
+
+	return nil
+}
+
+// ValidateSignature validates the uploaded release was signed by one of the project configs keys
+// Releases are required to be signed once signing keys are uploaded, or if the deployer requires it
+func (release *Release) ValidateSignature(s3c aws.S3API, uploaded *Release) error {
+	keys, err := release.signingKeys(s3c)
+	if err != nil {
+		return fmt.Errorf("Error Getting signing keys with %v", err.Error())
+	}
+
+	if keys == nil {
+		if release.signatureRequired {
+			return fmt.Errorf("Release must be signed, but %v has no signing keys for it", *release.SigningKeysPath())
+		}
+		return nil
+	}
+
+	if uploaded.Signature == nil {
+		return fmt.Errorf("Release must be signed")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(to.Strs(uploaded.Signature.Signature))
+	if err != nil || len(sig) == 0 {
+		return fmt.Errorf("Release signature must be base64 encoded")
+	}
+
+	// The release has been altered by the deployer, the uploaded release is what was signed
+	message := uploaded.SignedMessage()
+	algorithm := to.Strs(uploaded.Signature.Algorithm)
+
+	names := []string{}
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		pub, err := parsePublicKey(keys[name])
+		if err != nil {
+			return fmt.Errorf("Signing key %v %v", name, err.Error())
+		}
+
+		if verifySignature(pub, algorithm, message, sig) {
+			release.RecordEvent("SignatureVerified", nil, name, nil)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Release signature %v is not valid for any signing key", algorithm)
+}
+
+// signingKeys returns the configs public keys, else the projects, else nil if neither exist
+func (release *Release) signingKeys(s3c aws.S3API) (map[string]string, error) {
+	var keys SigningKeys
+	err := s3.GetStruct(s3c, release.Bucket, release.SigningKeysPath(), &keys)
+
+	switch err.(type) {
+	case nil:
+	case *s3.NotFoundError:
+		return nil, nil
+	default:
+		return nil, err
+	}
+
+	var projectKeys *ProjectSigningKeys
+	for _, pk := range keys.SigningKeys {
+		if pk == nil || to.Strs(pk.ProjectName) != *release.ProjectName {
+			continue
+		}
+
+		if pk.ConfigName == nil {
+			projectKeys = pk
+		} else if *pk.ConfigName == *release.ConfigName {
+			projectKeys = pk
+			break
+		}
+	}
+
+	if projectKeys == nil {
+		return nil, nil
+	}
+
+	// An empty entry would otherwise turn signing off
+	if len(projectKeys.PublicKeys) == 0 {
+		return nil, fmt.Errorf("%v has no public_keys for %v", *release.SigningKeysPath(), *release.ProjectName)
+	}
+
+	return projectKeys.PublicKeys, nil
+}
+
+// parsePublicKey parses a PEM or base64 DER encoded public key, or a base64 raw ed25519 key
+func parsePublicKey(raw string) (crypto.PublicKey, error) {
+	var der []byte
+	if block, _ := pem.Decode([]byte(raw)); block != nil {
+		der = block.Bytes
+	} else {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("must be PEM or base64 encoded")
+		}
+		der = decoded
+	}
+
+	if len(der) == ed25519.PublicKeySize {
+		return ed25519.PublicKey(der), nil
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+
+	switch pub.(type) {
+	case ed25519.PublicKey, *rsa.PublicKey, *ecdsa.PublicKey:
+		return pub, nil
+	}
+
+	return nil, fmt.Errorf("must be an ed25519, RSA or ECDSA key")
+}
+
+// verifySignature returns true if sig is the keys signature of message with the algorithm
+func verifySignature(pub crypto.PublicKey, algorithm string, message []byte, sig []byte) bool {
+	if key, ok := pub.(ed25519.PublicKey); ok {
+		return algorithm == "ED25519" && ed25519.Verify(key, message, sig)
+	}
+
+	// KMS signs the hash of the RAW message
+	hash, ok := signingHash(algorithm)
+	if !ok {
+		return false
+	}
+
+	h := hash.New()
+	h.Write(message)
+	digest := h.Sum(nil)
+
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		switch {
+		case strings.HasPrefix(algorithm, "RSASSA_PSS_"):
+			return rsa.VerifyPSS(key, hash, digest, sig, nil) == nil
+		case strings.HasPrefix(algorithm, "RSASSA_PKCS1_V1_5_"):
+			return rsa.VerifyPKCS1v15(key, hash, digest, sig) == nil
+		}
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(algorithm, "ECDSA_") {
+			return false
+		}
+
+		// KMS ECDSA signatures are DER encoded
+		var rs struct{ R, S *big.Int }
+		if rest, err := asn1.Unmarshal(sig, &rs); err != nil || len(rest) > 0 {
+			return false
+		}
+		return ecdsa.Verify(key, digest, rs.R, rs.S)
+	}
+
+	return false
+}
+
+func signingHash(algorithm string) (crypto.Hash, bool) {
+	switch {
+	case strings.HasSuffix(algorithm, "_SHA_256"):
+		return crypto.SHA256, true
+	case strings.HasSuffix(algorithm, "_SHA_384"):
+		return crypto.SHA384, true
+	case strings.HasSuffix(algorithm, "_SHA_512"):
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
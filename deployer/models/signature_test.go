@@ -0,0 +1,213 @@
+package models
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+// signedMockRelease uploads the release signed with the signature sign returns
+func signedMockRelease(t *testing.T, sign func([]byte) *ReleaseSignature) (*Release, *mocks.MockClients) {
+	r := MockRelease(t)
+	awsc := MockAwsClients(r)
+
+	if sign != nil {
+		r.Signature = sign(r.SignedMessage())
+		AddReleaseS3Objects(awsc, r)
+	}
+
+	r.ReleaseSHA256 = to.SHA256Struct(r)
+	MockPrepareRelease(r)
+	return r, awsc
+}
+
+func ed25519Signer(key ed25519.PrivateKey) func([]byte) *ReleaseSignature {
+	return func(message []byte) *ReleaseSignature {
+		return &ReleaseSignature{
+			Algorithm: to.Strp("ED25519"),
+			Signature: to.Strp(base64.StdEncoding.EncodeToString(ed25519.Sign(key, message))),
+		}
+	}
+}
+
+// validateSigned validates the release and its signature like the Validate handler
+func validateSigned(r *Release, awsc *mocks.MockClients) error {
+	if err := r.Validate(awsc.S3, awsc.KMS); err != nil {
+		return err
+	}
+	return r.ValidateUploadedSignature(awsc.S3)
+}
+
+// addSigningKeys uploads the signing keys of each project config, a nil config is the whole project
+func addSigningKeys(awsc *mocks.MockClients, r *Release, keys ...*ProjectSigningKeys) {
+	raw, _ := json.Marshal(&SigningKeys{SigningKeys: keys})
+	awsc.S3.AddGetObject(*r.SigningKeysPath(), string(raw), nil)
+}
+
+func configKeys(r *Release, keys map[string]string) *ProjectSigningKeys {
+	return &ProjectSigningKeys{ProjectName: r.ProjectName, ConfigName: r.ConfigName, PublicKeys: keys}
+}
+
+func projectKeys(r *Release, keys map[string]string) *ProjectSigningKeys {
+	return &ProjectSigningKeys{ProjectName: r.ProjectName, PublicKeys: keys}
+}
+
+func Test_Release_Validate_Signature_ED25519(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	_, otherPriv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	assert.NoError(t, err)
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	// No signing keys, signatures are optional
+	r, awsc := signedMockRelease(t, nil)
+	assert.NoError(t, validateSigned(r, awsc))
+
+	// Unsigned
+	r, awsc = signedMockRelease(t, nil)
+	addSigningKeys(awsc, r, configKeys(r, map[string]string{"alice": pemKey}))
+	err = validateSigned(r, awsc)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Release must be signed")
+
+	// Signed by the config key
+	r, awsc = signedMockRelease(t, ed25519Signer(priv))
+	addSigningKeys(awsc, r, configKeys(r, map[string]string{"alice": pemKey}))
+	assert.NoError(t, validateSigned(r, awsc))
+	assert.Equal(t, "SignatureVerified", r.events[0].Type)
+	assert.Equal(t, "alice", r.events[0].Message)
+
+	// Signed by the project key, a base64 raw key
+	r, awsc = signedMockRelease(t, ed25519Signer(priv))
+	addSigningKeys(awsc, r, projectKeys(r, map[string]string{"alice": base64.StdEncoding.EncodeToString(pub)}))
+	assert.NoError(t, validateSigned(r, awsc))
+
+	// The config keys take precedence
+	addSigningKeys(awsc, r,
+		projectKeys(r, map[string]string{"alice": base64.StdEncoding.EncodeToString(pub)}),
+		configKeys(r, map[string]string{"bob": base64.StdEncoding.EncodeToString(otherPriv.Public().(ed25519.PublicKey))}),
+	)
+	assert.Error(t, validateSigned(r, awsc))
+
+	// Other projects keys are not used
+	r, awsc = signedMockRelease(t, ed25519Signer(priv))
+	addSigningKeys(awsc, r, &ProjectSigningKeys{ProjectName: to.Strp("other"), PublicKeys: map[string]string{"alice": pemKey}})
+	assert.NoError(t, validateSigned(r, awsc))
+	assert.Equal(t, 0, len(r.events))
+
+	// Signed by another key
+	r, awsc = signedMockRelease(t, ed25519Signer(otherPriv))
+	addSigningKeys(awsc, r, configKeys(r, map[string]string{"alice": pemKey}))
+	err = validateSigned(r, awsc)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "is not valid for any signing key")
+
+	// Changed after it was signed
+	r, awsc = signedMockRelease(t, func(message []byte) *ReleaseSignature {
+		return ed25519Signer(priv)([]byte("other release"))
+	})
+	addSigningKeys(awsc, r, configKeys(r, map[string]string{"alice": pemKey}))
+	assert.Error(t, validateSigned(r, awsc))
+
+	// A bad signing key
+	r, awsc = signedMockRelease(t, ed25519Signer(priv))
+	addSigningKeys(awsc, r, configKeys(r, map[string]string{"alice": "not a key"}))
+	assert.Error(t, validateSigned(r, awsc))
+}
+
+func Test_Release_Validate_Unsigned(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// Validate does not check signatures, so a release can be planned without a signing key
+	r, awsc := signedMockRelease(t, nil)
+	r.RequireSignature()
+	addSigningKeys(awsc, r, configKeys(r, map[string]string{"alice": base64.StdEncoding.EncodeToString(pub)}))
+	assert.NoError(t, r.Validate(awsc.S3, awsc.KMS))
+	assert.Error(t, r.ValidateUploadedSignature(awsc.S3))
+}
+
+func Test_Release_Validate_Signature_Required(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// Without signing keys a required signature fails closed
+	r, awsc := signedMockRelease(t, ed25519Signer(priv))
+	r.RequireSignature()
+	err = validateSigned(r, awsc)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "has no signing keys")
+
+	// A project with an empty entry is not unsigned
+	addSigningKeys(awsc, r, projectKeys(r, map[string]string{}))
+	assert.Error(t, validateSigned(r, awsc))
+
+	addSigningKeys(awsc, r, projectKeys(r, map[string]string{"alice": base64.StdEncoding.EncodeToString(pub)}))
+	assert.NoError(t, validateSigned(r, awsc))
+
+	// Unsigned
+	r, awsc = signedMockRelease(t, nil)
+	r.RequireSignature()
+	addSigningKeys(awsc, r, projectKeys(r, map[string]string{"alice": base64.StdEncoding.EncodeToString(pub)}))
+	assert.Error(t, validateSigned(r, awsc))
+}
+
+func Test_Release_Validate_Signature_Planted_Keys(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	planted, plantedPriv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	r, awsc := signedMockRelease(t, ed25519Signer(plantedPriv))
+	addSigningKeys(awsc, r, configKeys(r, map[string]string{"alice": base64.StdEncoding.EncodeToString(priv.Public().(ed25519.PublicKey))}))
+
+	// Keys uploaded to the deployer writable release paths are ignored
+	for _, dir := range []*string{r.RootDir(), r.ProjectDir()} {
+		awsc.S3.AddGetObject(fmt.Sprintf("%v/signing_keys.json", *dir), fmt.Sprintf(`{"public_keys": {"mallory": "%v"}}`, base64.StdEncoding.EncodeToString(planted)), nil)
+	}
+
+	err = validateSigned(r, awsc)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "is not valid for any signing key")
+
+	// Without root signing keys the deployer still requires a signature it can verify
+	r, awsc = signedMockRelease(t, ed25519Signer(plantedPriv))
+	r.RequireSignature()
+	awsc.S3.AddGetObject(fmt.Sprintf("%v/signing_keys.json", *r.RootDir()), fmt.Sprintf(`{"public_keys": {"mallory": "%v"}}`, base64.StdEncoding.EncodeToString(planted)), nil)
+	assert.Error(t, validateSigned(r, awsc))
+}
+
+func Test_Release_Validate_Signature_KMS(t *testing.T) {
+	kmsc := &mocks.KMSClient{}
+	sign := func(message []byte) *ReleaseSignature {
+		out, err := kmsc.Sign(&kms.SignInput{Message: message, MessageType: to.Strp("RAW"), SigningAlgorithm: to.Strp("ECDSA_SHA_256")})
+		assert.NoError(t, err)
+		return &ReleaseSignature{Algorithm: out.SigningAlgorithm, Signature: to.Strp(base64.StdEncoding.EncodeToString(out.Signature))}
+	}
+
+	r, awsc := signedMockRelease(t, sign)
+	addSigningKeys(awsc, r, configKeys(r, map[string]string{"kms": kmsc.PublicKeyDER()}))
+	assert.NoError(t, validateSigned(r, awsc))
+
+	// The algorithm must match the key
+	r, awsc = signedMockRelease(t, func(message []byte) *ReleaseSignature {
+		s := sign(message)
+		s.Algorithm = to.Strp("RSASSA_PSS_SHA_256")
+		return s
+	})
+	addSigningKeys(awsc, r, configKeys(r, map[string]string{"kms": kmsc.PublicKeyDER()}))
+	assert.Error(t, validateSigned(r, awsc))
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/coinbase/odin/aws"
 	"github.com/coinbase/step/utils/to"
 )
@@ -14,20 +16,11 @@ var STRATEGIES = []string{
 	"20AtATimeNoCanary",
 }
 
-type StrategyType string
-
-const (
-	AllAtOnce StrategyType = "AllAtOnce"
-	Canary                 = "Canary"
-	Percent                = "Percent"
-	Increment              = "Increment"
-)
-
+// NewStrategy returns the strategy for the autoscaling config,
+// the rollout steps are either the configured rollout or the named strategies preset
 func NewStrategy(autoscaling *AutoScalingConfig, previousDesiredCapacity *int64) *Strategy {
 	// Defaults
 	s := &Strategy{
-		name:                    *autoscaling.Strategy,
-		sType:                   AllAtOnce,
 		minSize:                 int64(1),
 		maxSize:                 int64(1),
 		maxTerminations:         int64(0),
@@ -52,24 +45,12 @@ func NewStrategy(autoscaling *AutoScalingConfig, previousDesiredCapacity *int64)
 		s.maxTerminations = *autoscaling.MaxTerminations
 	}
 
-	// Define the Strategy properties
-	switch s.name {
-	case "OneThenAllWithCanary":
-		s.sType = Canary
-	case "25PercentStepRolloutNoCanary":
-		s.sType = Percent
-		// 25% means release is divided into 4 steps
-		s.rollOutSteps = 4
-	case "10PercentStepRolloutNoCanary":
-		s.sType = Percent
-		// 10% means release divided into 10 stages
-		s.rollOutSteps = 10
-	case "10AtATimeNoCanary":
-		s.sType = Increment
-		s.rollOutSteps = float64(s.TargetCapacity()) / float64(10)
-	case "20AtATimeNoCanary":
-		s.sType = Increment
-		s.rollOutSteps = float64(s.TargetCapacity()) / float64(20)
+	// Define the rollout steps
+	switch {
+	case autoscaling.Rollout != nil:
+		s.steps = autoscaling.Rollout.Steps
+	case autoscaling.Strategy != nil:
+		s.steps = rolloutPreset(autoscaling, s.TargetCapacity())
 	}
 
 	return s
@@ -78,17 +59,14 @@ func NewStrategy(autoscaling *AutoScalingConfig, previousDesiredCapacity *int64)
 // Strategy describes the way in which Odin brings up instances in an Autoscaling Group
 // pulling it out into this struct helps isolate code from the rest of the service
 type Strategy struct {
-	name                    string
-	sType                   StrategyType
 	minSize                 int64
 	maxSize                 int64
 	maxTerminations         int64
 	spread                  float64
 	previousDesiredCapacity *int64 // This can be nil
 
-	// For Percent and Increment types
-	// This is the number of steps used to rollout all instances
-	rollOutSteps float64
+	// The rollout steps before scaling to the TargetCapacity
+	steps []*RolloutStep
 }
 
 ////
@@ -133,82 +111,96 @@ func (strategy *Strategy) DesiredCapacity() int64 {
 }
 
 ////
-// Init Methods
+// Rollout Methods
 ////
 
-func (strategy *Strategy) InitialMinSize() *int64 {
-	switch strategy.sType {
-	case Canary:
-		// "OneThenAllWithCanary" starts with 1
-		return to.Int64p(1)
-	case Percent, Increment:
-		// no instances yet
-		return to.Int64p(fastRolloutRate(0, strategy.minSize, strategy.rollOutSteps))
+// Step returns the rollout step, nil once the rollout is complete
+func (strategy *Strategy) Step(step int64) *RolloutStep {
+	if step < 0 || step >= int64(len(strategy.steps)) {
+		return nil
 	}
+	return strategy.steps[step]
+}
 
-	// default case "AllAtOnce" is minSize
-	return &strategy.minSize
+// RolloutComplete is true after the last step
+func (strategy *Strategy) RolloutComplete(step int64) bool {
+	return strategy.Step(step) == nil
 }
 
-func (strategy *Strategy) InitialDesiredCapacity() *int64 {
-	switch strategy.sType {
-	case Canary:
-		// "OneThenAllWithCanary" starts with 1
-		return to.Int64p(1)
-	case Percent, Increment:
-		return to.Int64p(fastRolloutRate(0, strategy.TargetCapacity(), strategy.rollOutSteps))
+// StepCapacity is the capacity to launch during the step
+// it never decreases from the previous steps
+func (strategy *Strategy) StepCapacity(step int64) int64 {
+	tc := strategy.TargetCapacity()
+	if strategy.RolloutComplete(step) {
+		return tc
+	}
+
+	capacity := int64(0)
+	for _, s := range strategy.steps[:step+1] {
+		capacity = max(capacity, s.capacity(tc))
+	}
+
+	return capacity
+}
+
+// WaitRemaining is how much longer the step must stay healthy
+// healthyAt is when the step first became healthy, nil if it has not
+func (strategy *Strategy) WaitRemaining(step int64, healthyAt *time.Time, now time.Time) time.Duration {
+	s := strategy.Step(step)
+	if s == nil || s.wait() == nil {
+		return 0
+	}
+
+	if healthyAt == nil {
+		return *s.wait()
+	}
+
+	remaining := *s.wait() - now.Sub(*healthyAt)
+	if remaining < 0 {
+		return 0
 	}
 
-	// default case "AllAtOnce" is target capacity
-	return to.Int64p(strategy.TargetCapacity())
+	return remaining
+}
+
+////
+// Init Methods
+////
+
+func (strategy *Strategy) InitialMinSize() *int64 {
+	min, _ := strategy.CalculateMinDesired(0)
+	return to.Int64p(min)
+}
+
+func (strategy *Strategy) InitialDesiredCapacity() *int64 {
+	_, dc := strategy.CalculateMinDesired(0)
+	return to.Int64p(dc)
 }
 
 ////
 // Flow Methods
 ////
 
-func (strategy *Strategy) ReachedMaxTerminations(instances aws.Instances) bool {
+// ReachedMaxTerminations is true if more instances are terminating than the step allows
+func (strategy *Strategy) ReachedMaxTerminations(instances aws.Instances, step int64) bool {
 	maxTermingInstances := strategy.maxTerminations
 
-	switch strategy.sType {
-	case Canary:
-		// OneThenAllWithCanary during the canary it will exit if one terminates, otherwise default
-		canarying := len(instances) <= 1
-		if canarying {
-			maxTermingInstances = 0
-		}
+	if s := strategy.Step(step); s != nil && s.MaxTerms != nil {
+		maxTermingInstances = *s.MaxTerms
 	}
 
-	// Non Canaries just use maxTerms by default
 	// If there are more terminating instances than allowed return true
 	return int64(len(instances.TerminatingIDs())) > maxTermingInstances
 }
 
-func (strategy *Strategy) CalculateMinDesired(instances aws.Instances) (int64, int64) {
-	switch strategy.sType {
-	case Canary:
-		// "OneThenAllWithCanary" if there is only one instance and it is healthy proceed
-		canarying := len(instances) <= 1
-		if !canarying {
-			break
-		} // Only continue if canarying
-
-		canaryIsHealthy := len(instances.HealthyIDs()) == 1
-		if canaryIsHealthy {
-			break
-		} // return default amounts if the canary is Healthy
-
-		return 1, 1
-	case Percent, Increment:
-		// Percent will continually add 1/strategy.rollOutSteps additional instances to those that are launching
-		// until InitialMinSize and InitialDesiredCapacity
-		minSize := fastRolloutRate(len(instances), strategy.minSize, strategy.rollOutSteps)
-		dc := fastRolloutRate(len(instances), strategy.TargetCapacity(), strategy.rollOutSteps)
-		return minSize, dc
+// CalculateMinDesired returns the min_size and desired_capacity of the rollout step
+func (strategy *Strategy) CalculateMinDesired(step int64) (int64, int64) {
+	if strategy.RolloutComplete(step) {
+		return strategy.minSize, strategy.TargetCapacity()
 	}
 
-	// default case "AllAtOnce" is init values
-	return strategy.minSize, strategy.TargetCapacity()
+	dc := strategy.StepCapacity(step)
+	return min(strategy.minSize, dc), dc
 }
 
 ////
@@ -232,20 +224,3 @@ func max(x int64, y int64) int64 {
 func percent(x int64, percent float64) int64 {
 	return (x * int64(percent*100)) / 100
 }
-
-// 25PercentStepRolloutNoCanary and 10PercentStepRolloutNoCanary
-
-func fastRolloutRate(instanceCount int, baseAmount int64, denominator float64) int64 {
-	// 1. Always return greater than 1
-	// 2. Always return less than baseAmount
-	// 3. return the instanceCount + 1/4 the baseAmount
-
-	// find the additional amount, always return more than 1
-	additionalInstances := max(1, int64(float64(baseAmount)/denominator))
-
-	// core return value
-	amount := int64(instanceCount) + additionalInstances
-
-	// Always return greater than 1, and less than baseAmount
-	return max(1, min(amount, baseAmount))
-}
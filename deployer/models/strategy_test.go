@@ -1,8 +1,8 @@
 package models
 
 import (
-	"fmt"
 	"testing"
+	"time"
 
 	"github.com/coinbase/odin/aws"
 	"github.com/coinbase/step/utils/to"
@@ -97,6 +97,7 @@ func Test_Strategy_AllAtOnce_InitValues(t *testing.T) {
 
 	assert.EqualValues(t, *strat.InitialMinSize(), strat.minSize)
 	assert.EqualValues(t, *strat.InitialDesiredCapacity(), strat.TargetCapacity())
+	assert.True(t, strat.RolloutComplete(0))
 }
 
 func Test_Strategy_AllAtOnce_Termination(t *testing.T) {
@@ -105,45 +106,18 @@ func Test_Strategy_AllAtOnce_Termination(t *testing.T) {
 	strat := complexSrategy("AllAtOnce")
 
 	// unless there are two terminating then we didnt reach the limit
-	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneGood))
-	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneTerming))
-	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneOfTwoTerming))
-	assert.EqualValues(t, true, strat.ReachedMaxTerminations(twoTerming))
-}
-
-var allAtOnceCalcs = []struct {
-	instances aws.Instances
-	min       int64
-	dc        int64
-}{
-	{
-		instances: oneGood,
-		min:       1,
-		dc:        25,
-	},
-	{
-		instances: oneUnHealthy,
-		min:       1,
-		dc:        25,
-	},
-	{
-		instances: twoLaunching,
-		min:       1,
-		dc:        25,
-	},
+	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneGood, 0))
+	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneTerming, 0))
+	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneOfTwoTerming, 0))
+	assert.EqualValues(t, true, strat.ReachedMaxTerminations(twoTerming, 0))
 }
 
 func Test_Strategy_AllAtOnce_Min_And_Desired(t *testing.T) {
-	for i, test := range allAtOnceCalcs {
-		t.Run(fmt.Sprintf("test: %v", i), func(t *testing.T) {
-			strat := complexSrategy("AllAtOnce")
-
-			min, dc := strat.CalculateMinDesired(test.instances)
+	strat := complexSrategy("AllAtOnce")
 
-			assert.EqualValues(t, test.min, min)
-			assert.EqualValues(t, test.dc, dc)
-		})
-	}
+	min, dc := strat.CalculateMinDesired(0)
+	assert.EqualValues(t, 1, min)
+	assert.EqualValues(t, 25, dc)
 }
 
 ////
@@ -151,8 +125,7 @@ func Test_Strategy_AllAtOnce_Min_And_Desired(t *testing.T) {
 ////
 
 func Test_Strategy_OneThenAllWithCanary_InitValues(t *testing.T) {
-	// OneThenAllWithCanary does not change throughout a deploy
-	// so intial and dc are 1
+	// OneThenAllWithCanary starts with 1
 	strat := complexSrategy("OneThenAllWithCanary")
 
 	assert.EqualValues(t, *strat.InitialMinSize(), 1)
@@ -160,82 +133,62 @@ func Test_Strategy_OneThenAllWithCanary_InitValues(t *testing.T) {
 }
 
 func Test_Strategy_OneThenAllWithCanary_Termination(t *testing.T) {
-	// OneThenAllWithCanary has a max term count of 1 when there is only 1 instance
+	// OneThenAllWithCanary has a max term count of 0 during the canary step
 	// ReachedMaxTerminations
 	strat := complexSrategy("OneThenAllWithCanary")
 
-	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneGood))
-	// If there is only 1 instance and it is terminating then we think that the canary it terming
-	assert.EqualValues(t, true, strat.ReachedMaxTerminations(oneTerming))
-	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneOfTwoTerming))
-	assert.EqualValues(t, true, strat.ReachedMaxTerminations(twoTerming))
-}
+	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneGood, 0))
+	// If the canary is terminating halt
+	assert.EqualValues(t, true, strat.ReachedMaxTerminations(oneTerming, 0))
 
-var oneAtATimeCalcs = []struct {
-	instances aws.Instances
-	min       int64
-	dc        int64
-}{
-	// If there is one unhealthy we are still canarying
-	{
-		instances: oneUnHealthy,
-		min:       1,
-		dc:        1,
-	},
-	// If there is one good then we can stop canarying
-	{
-		instances: oneGood,
-		min:       1,
-		dc:        25,
-	},
-	// If there is two launching continue launching
-	{
-		instances: twoLaunching,
-		min:       1,
-		dc:        25,
-	},
+	// After the canary the max_terms is used
+	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneOfTwoTerming, 1))
+	assert.EqualValues(t, true, strat.ReachedMaxTerminations(twoTerming, 1))
 }
 
 func Test_Strategy_OneThenAllWithCanary_Min_And_Desired(t *testing.T) {
-	for i, test := range oneAtATimeCalcs {
-		t.Run(fmt.Sprintf("test: %v", i), func(t *testing.T) {
-			strat := complexSrategy("OneThenAllWithCanary")
+	strat := complexSrategy("OneThenAllWithCanary")
 
-			min, dc := strat.CalculateMinDesired(test.instances)
+	// During the canary
+	min, dc := strat.CalculateMinDesired(0)
+	assert.EqualValues(t, 1, min)
+	assert.EqualValues(t, 1, dc)
 
-			assert.EqualValues(t, test.min, min)
-			assert.EqualValues(t, test.dc, dc)
-		})
-	}
+	// After the canary
+	min, dc = strat.CalculateMinDesired(1)
+	assert.EqualValues(t, 1, min)
+	assert.EqualValues(t, 25, dc)
 }
 
-////
-// 25PercentStepRolloutNoCanary, i.e. launching in quarters
-////
+func Test_Strategy_OneThenAllWithCanary_Bake(t *testing.T) {
+	strat := complexSrategy("OneThenAllWithCanary")
+
+	now := time.Now()
+
+	// Without a bake the canary only has to be healthy
+	assert.Equal(t, time.Duration(0), strat.WaitRemaining(0, nil, now))
+
+	asg := &AutoScalingConfig{Strategy: to.Strp("OneThenAllWithCanary"), CanaryBakeSeconds: to.Int64p(600)}
+	asg.SetDefaults(to.Strp("service_id"), to.Intp(30))
+	strat = NewStrategy(asg, to.Int64p(25))
 
-func Test_Strategy_25StepRolloutNoCanary_Rate(t *testing.T) {
-	// Always return at least 1
-	assert.EqualValues(t, 1, fastRolloutRate(0, 1, 4))
-	assert.EqualValues(t, 1, fastRolloutRate(0, 2, 4))
-	assert.EqualValues(t, 1, fastRolloutRate(0, 4, 4))
+	assert.Equal(t, 10*time.Minute, strat.WaitRemaining(0, nil, now))
 
-	// Dont get stuck on low numbers
-	assert.EqualValues(t, 1, fastRolloutRate(1, 1, 4))
-	assert.EqualValues(t, 2, fastRolloutRate(1, 2, 4))
+	healthyAt := now.Add(-4 * time.Minute)
+	assert.Equal(t, 6*time.Minute, strat.WaitRemaining(0, &healthyAt, now))
 
-	// Never return greater than the baseAmount
-	assert.EqualValues(t, 5, fastRolloutRate(100, 5, 4))
-	assert.EqualValues(t, 10, fastRolloutRate(10, 10, 4))
+	healthyAt = now.Add(-11 * time.Minute)
+	assert.Equal(t, time.Duration(0), strat.WaitRemaining(0, &healthyAt, now))
 
-	// return a quarter + the instance amount
-	assert.EqualValues(t, 2, fastRolloutRate(1, 4, 4))
-	assert.EqualValues(t, 3, fastRolloutRate(2, 4, 4))
+	// No wait after the canary
+	assert.Equal(t, time.Duration(0), strat.WaitRemaining(1, nil, now))
 }
 
-func Test_Strategy_25StepRolloutNoCanary_InitValues(t *testing.T) {
-	// 25PercentStepRolloutNoCanary does not change throughout a deploy
-	// So initial values are the same as target values
+////
+// 25PercentStepRolloutNoCanary, i.e. launching in quarters
+////
 
+func Test_Strategy_25StepRolloutNoCanary_InitValues(t *testing.T) {
 	strat := complexSrategy("25PercentStepRolloutNoCanary")
 
 	assert.EqualValues(t, *strat.InitialMinSize(), 1)
@@ -248,78 +201,33 @@ func Test_Strategy_25StepRolloutNoCanary_Termination(t *testing.T) {
 	strat := complexSrategy("25PercentStepRolloutNoCanary")
 
 	// unless there are two terminating then we didnt reach the limit
-	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneGood))
-	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneTerming))
-	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneOfTwoTerming))
-	assert.EqualValues(t, true, strat.ReachedMaxTerminations(twoTerming))
-}
-
-var fastRolloutCalcs25 = []struct {
-	instances aws.Instances
-	min       int64
-	dc        int64
-}{
-	{
-		instances: oneGood,
-		min:       1,
-		dc:        7, // 25/4 + 1
-	},
-	{
-		instances: oneUnHealthy,
-		min:       1,
-		dc:        7, // 25/4 + 1
-	},
-	{
-		instances: twoLaunching,
-		min:       1,
-		dc:        8, // 25/4 + 2
-	},
+	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneGood, 0))
+	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneTerming, 0))
+	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneOfTwoTerming, 0))
+	assert.EqualValues(t, true, strat.ReachedMaxTerminations(twoTerming, 0))
 }
 
 func Test_Strategy_25StepRolloutNoCanary_Min_And_Desired(t *testing.T) {
-	for i, test := range fastRolloutCalcs25 {
-		t.Run(fmt.Sprintf("test: %v", i), func(t *testing.T) {
-			strat := complexSrategy("25PercentStepRolloutNoCanary")
-
-			min, dc := strat.CalculateMinDesired(test.instances)
+	strat := complexSrategy("25PercentStepRolloutNoCanary")
 
-			assert.EqualValues(t, test.min, min)
-			assert.EqualValues(t, test.dc, dc)
-		})
+	for step, expected := range []int64{6, 12, 18, 25} {
+		min, dc := strat.CalculateMinDesired(int64(step))
+		assert.EqualValues(t, 1, min)
+		assert.EqualValues(t, expected, dc)
 	}
+
+	assert.True(t, strat.RolloutComplete(3))
 }
 
 ////
-// 10PercentStepRolloutNoCanary, i.e. launching in quarters
+// 10PercentStepRolloutNoCanary, i.e. launching in tenths
 ////
 
-func Test_Strategy_10StepRolloutNoCanary_Rate(t *testing.T) {
-	// Always return at least 1
-	assert.EqualValues(t, 1, fastRolloutRate(0, 1, 10))
-	assert.EqualValues(t, 1, fastRolloutRate(0, 2, 10))
-	assert.EqualValues(t, 1, fastRolloutRate(0, 4, 10))
-
-	// Dont get stuck on low numbers
-	assert.EqualValues(t, 1, fastRolloutRate(1, 1, 10))
-	assert.EqualValues(t, 2, fastRolloutRate(1, 2, 10))
-
-	// Never return greater than the baseAmount
-	assert.EqualValues(t, 5, fastRolloutRate(100, 5, 10))
-	assert.EqualValues(t, 10, fastRolloutRate(10, 10, 10))
-
-	// return a quarter + the instance amount
-	assert.EqualValues(t, 2, fastRolloutRate(1, 4, 10))
-	assert.EqualValues(t, 3, fastRolloutRate(2, 4, 10))
-}
-
 func Test_Strategy_10StepRolloutNoCanary_InitValues(t *testing.T) {
-	// 10PercentStepRolloutNoCanary does not change throughout a deploy
-	// So initial values are the same as target values
-
 	strat := complexSrategy("10PercentStepRolloutNoCanary")
 
 	assert.EqualValues(t, *strat.InitialMinSize(), 1)
-	assert.EqualValues(t, *strat.InitialDesiredCapacity(), 2) // 25/4
+	assert.EqualValues(t, *strat.InitialDesiredCapacity(), 2) // 25/10
 }
 
 func Test_Strategy_10StepRolloutNoCanary_Termination(t *testing.T) {
@@ -328,44 +236,19 @@ func Test_Strategy_10StepRolloutNoCanary_Termination(t *testing.T) {
 	strat := complexSrategy("10PercentStepRolloutNoCanary")
 
 	// unless there are two terminating then we didnt reach the limit
-	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneGood))
-	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneTerming))
-	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneOfTwoTerming))
-	assert.EqualValues(t, true, strat.ReachedMaxTerminations(twoTerming))
-}
-
-var fastRolloutCalcs10 = []struct {
-	instances aws.Instances
-	min       int64
-	dc        int64
-}{
-	{
-		instances: oneGood,
-		min:       1,
-		dc:        3, // 25/10 + 1
-	},
-	{
-		instances: oneUnHealthy,
-		min:       1,
-		dc:        3, // 25/10 + 1
-	},
-	{
-		instances: twoLaunching,
-		min:       1,
-		dc:        4, // 25/10 + 2
-	},
+	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneGood, 0))
+	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneTerming, 0))
+	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneOfTwoTerming, 0))
+	assert.EqualValues(t, true, strat.ReachedMaxTerminations(twoTerming, 0))
 }
 
 func Test_Strategy_10StepRolloutNoCanary_Min_And_Desired(t *testing.T) {
-	for i, test := range fastRolloutCalcs10 {
-		t.Run(fmt.Sprintf("test: %v", i), func(t *testing.T) {
-			strat := complexSrategy("10PercentStepRolloutNoCanary")
-
-			min, dc := strat.CalculateMinDesired(test.instances)
+	strat := complexSrategy("10PercentStepRolloutNoCanary")
 
-			assert.EqualValues(t, test.min, min)
-			assert.EqualValues(t, test.dc, dc)
-		})
+	for step, expected := range []int64{2, 5, 7, 10, 12, 15, 17, 20, 22, 25} {
+		min, dc := strat.CalculateMinDesired(int64(step))
+		assert.EqualValues(t, 1, min)
+		assert.EqualValues(t, expected, dc)
 	}
 }
 
@@ -374,9 +257,6 @@ func Test_Strategy_10StepRolloutNoCanary_Min_And_Desired(t *testing.T) {
 ////
 
 func Test_Strategy_XAtATimeNoCanary_InitValues(t *testing.T) {
-	// XAtATimeNoCanary does not change throughout a deploy
-	// So initial values are the same as target values
-
 	strat := complexSrategy("10AtATimeNoCanary")
 
 	assert.EqualValues(t, *strat.InitialMinSize(), 1)
@@ -385,7 +265,7 @@ func Test_Strategy_XAtATimeNoCanary_InitValues(t *testing.T) {
 	strat = complexSrategy("20AtATimeNoCanary")
 
 	assert.EqualValues(t, *strat.InitialMinSize(), 1)
-	assert.EqualValues(t, *strat.InitialDesiredCapacity(), 20) // should start with 10
+	assert.EqualValues(t, *strat.InitialDesiredCapacity(), 20) // should start with 20
 }
 
 func Test_Strategy_XAtATimeNoCanary_Termination(t *testing.T) {
@@ -394,43 +274,74 @@ func Test_Strategy_XAtATimeNoCanary_Termination(t *testing.T) {
 	strat := complexSrategy("10AtATimeNoCanary")
 
 	// unless there are two terminating then we didnt reach the limit
-	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneGood))
-	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneTerming))
-	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneOfTwoTerming))
-	assert.EqualValues(t, true, strat.ReachedMaxTerminations(twoTerming))
+	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneGood, 0))
+	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneTerming, 0))
+	assert.EqualValues(t, false, strat.ReachedMaxTerminations(oneOfTwoTerming, 0))
+	assert.EqualValues(t, true, strat.ReachedMaxTerminations(twoTerming, 0))
 }
 
-var fastRolloutCalcs10AtATime = []struct {
-	instances aws.Instances
-	min       int64
-	dc        int64
-}{
-	{
-		instances: oneGood,
-		min:       1,
-		dc:        11, // 10 + 1
-	},
-	{
-		instances: oneUnHealthy,
-		min:       1,
-		dc:        11, // 10 + 1
-	},
-	{
-		instances: twoLaunching,
-		min:       1,
-		dc:        12, // 10 + 2
-	},
+func Test_Strategy_XAtATimeNoCanary_Min_And_Desired(t *testing.T) {
+	strat := complexSrategy("10AtATimeNoCanary")
+
+	for step, expected := range []int64{10, 20, 25} {
+		min, dc := strat.CalculateMinDesired(int64(step))
+		assert.EqualValues(t, 1, min)
+		assert.EqualValues(t, expected, dc)
+	}
 }
 
-func Test_Strategy_XAtATimeNoCanary_Min_And_Desired(t *testing.T) {
-	for i, test := range fastRolloutCalcs10AtATime {
-		t.Run(fmt.Sprintf("test: %v", i), func(t *testing.T) {
-			strat := complexSrategy("10AtATimeNoCanary")
+////
+// Rollout, i.e. user defined steps
+////
 
-			min, dc := strat.CalculateMinDesired(test.instances)
+func Test_Strategy_Rollout(t *testing.T) {
+	asg := &AutoScalingConfig{
+		MinSize:         to.Int64p(5),
+		MaxSize:         to.Int64p(200),
+		MaxTerminations: to.Int64p(1),
+		Rollout: &RolloutConfig{
+			Steps: []*RolloutStep{
+				&RolloutStep{Count: to.Int64p(1), WaitSeconds: to.Int64p(300), MaxTerms: to.Int64p(0)},
+				&RolloutStep{Percent: to.Float64p(5)},
+				&RolloutStep{Percent: to.Float64p(25), WaitSeconds: to.Int64p(60)},
+			},
+		},
+	}
+	asg.SetDefaults(to.Strp("service_id"), to.Intp(30))
+	assert.NoError(t, asg.ValidateAttributes())
+
+	strat := NewStrategy(asg, to.Int64p(100))
 
-			assert.EqualValues(t, test.min, min)
-			assert.EqualValues(t, test.dc, dc)
-		})
+	// min_size is never above the steps capacity
+	for step, expected := range [][]int64{{1, 1}, {5, 5}, {5, 25}, {5, 100}} {
+		min, dc := strat.CalculateMinDesired(int64(step))
+		assert.EqualValues(t, expected[0], min)
+		assert.EqualValues(t, expected[1], dc)
 	}
+
+	assert.True(t, strat.ReachedMaxTerminations(oneTerming, 0))
+	assert.False(t, strat.ReachedMaxTerminations(oneTerming, 1))
+	assert.Equal(t, 5*time.Minute, strat.WaitRemaining(0, nil, time.Now()))
+	assert.Equal(t, time.Duration(0), strat.WaitRemaining(1, nil, time.Now()))
+}
+
+func Test_Strategy_Rollout_Steps_Never_Decrease(t *testing.T) {
+	asg := &AutoScalingConfig{
+		MinSize: to.Int64p(1),
+		MaxSize: to.Int64p(10),
+		Rollout: &RolloutConfig{
+			Steps: []*RolloutStep{
+				&RolloutStep{Count: to.Int64p(5)},
+				&RolloutStep{Percent: to.Float64p(10)},
+				&RolloutStep{Count: to.Int64p(50)},
+			},
+		},
+	}
+	asg.SetDefaults(to.Strp("service_id"), to.Intp(30))
+
+	strat := NewStrategy(asg, to.Int64p(10))
+
+	assert.EqualValues(t, 5, strat.StepCapacity(0))
+	assert.EqualValues(t, 5, strat.StepCapacity(1))
+	assert.EqualValues(t, 10, strat.StepCapacity(2)) // Never above the target capacity
 }
@@ -0,0 +1,192 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/aws/alb"
+	"github.com/coinbase/step/utils/is"
+	"github.com/coinbase/step/utils/to"
+)
+
+// TRAFFIC_DEFAULT_WEIGHTS are the percent of traffic sent to the new target group in each step
+var TRAFFIC_DEFAULT_WEIGHTS = []int64{5, 25, 50, 100}
+
+// TrafficShiftConfig moves the traffic of a listener rule from the previous releases target group
+// to the new releases target group, one weight each health check once the service is healthy
+type TrafficShiftConfig struct {
+	ListenerRule *string   `json:"listener_rule,omitempty"` // ARN of the rule forwarding to both target groups
+	TargetGroups []*string `json:"target_groups,omitempty"` // The two target groups, the new release uses the one without traffic
+	Weights      []*int64  `json:"weights,omitempty"`       // Percent of traffic for the new target group, ending at 100
+}
+
+// SetDefaults assigns default values
+func (t *TrafficShiftConfig) SetDefaults() {
+	if len(t.Weights) == 0 {
+		for _, w := range TRAFFIC_DEFAULT_WEIGHTS {
+			t.Weights = append(t.Weights, to.Int64p(w))
+		}
+	}
+}
+
+// ValidateAttributes validates attributes
+func (t *TrafficShiftConfig) ValidateAttributes() error {
+	if is.EmptyStr(t.ListenerRule) {
+		return fmt.Errorf("Traffic shift listener_rule must be defined")
+	}
+
+	if len(t.TargetGroups) != 2 || !is.UniqueStrp(t.TargetGroups) {
+		return fmt.Errorf("Traffic shift requires two unique target_groups")
+	}
+
+	prev := int64(0)
+	for _, w := range t.Weights {
+		if w == nil || *w <= prev || *w > 100 {
+			return fmt.Errorf("Traffic shift weights must be increasing between 1 and 100")
+		}
+		prev = *w
+	}
+
+	if prev != 100 {
+		return fmt.Errorf("Traffic shift weights must end at 100")
+	}
+
+	return nil
+}
+
+// fetchTrafficShift returns the traffic shift target groups and listener rule
+func (service *Service) fetchTrafficShift(albc aws.ALBAPI) ([]*alb.TargetGroup, *alb.Rule, error) {
+	if service.TrafficShift == nil {
+		return nil, nil, nil
+	}
+
+	tgs, err := alb.FindAll(albc, service.TrafficShift.TargetGroups)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rule, err := alb.FindRule(albc, service.TrafficShift.ListenerRule)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tgs, rule, nil
+}
+
+// trafficTargetGroups returns the live and new target group ARNs
+// the live target group is the one receiving most of the rules traffic
+func (sr *ServiceResources) trafficTargetGroups() (*string, *string) {
+	if sr.TrafficRule == nil || len(sr.TrafficTargetGroups) != 2 {
+		return nil, nil
+	}
+
+	a, b := sr.TrafficTargetGroups[0].TargetGroupArn, sr.TrafficTargetGroups[1].TargetGroupArn
+	if a == nil || b == nil {
+		return nil, nil
+	}
+
+	wa, wb := sr.TrafficRule.Weights[*a], sr.TrafficRule.Weights[*b]
+	switch {
+	case wa > wb:
+		return a, b
+	case wb > wa:
+		return b, a
+	}
+
+	return nil, nil
+}
+
+// validateTrafficShift checks the rule forwards to only the two target groups, and one is live
+func (sr *ServiceResources) validateTrafficShift(service *Service) error {
+	if service.TrafficShift == nil {
+		return nil
+	}
+
+	if len(sr.TrafficTargetGroups) != 2 {
+		return fmt.Errorf("Traffic shift TargetGroup Not Found expected %v", to.StrSlice(service.TrafficShift.TargetGroups))
+	}
+
+	for _, r := range sr.TrafficTargetGroups {
+		if err := ValidateTargetGroup(service, r); err != nil {
+			return err
+		}
+	}
+
+	if sr.TrafficRule == nil {
+		return fmt.Errorf("Traffic shift Rule Not Found %v", *service.TrafficShift.ListenerRule)
+	}
+
+	for _, tg := range sr.TrafficTargetGroups {
+		if _, ok := sr.TrafficRule.Weights[to.Strs(tg.TargetGroupArn)]; !ok {
+			return fmt.Errorf("Traffic shift Rule %v does not forward to %v", *service.TrafficShift.ListenerRule, to.Strs(tg.TargetGroupName))
+		}
+	}
+
+	if len(sr.TrafficRule.Weights) != 2 {
+		return fmt.Errorf("Traffic shift Rule %v must only forward to the traffic shift target groups", *service.TrafficShift.ListenerRule)
+	}
+
+	if live, _ := sr.trafficTargetGroups(); live == nil {
+		return fmt.Errorf("Traffic shift Rule %v must send most traffic to one target group", *service.TrafficShift.ListenerRule)
+	}
+
+	return nil
+}
+
+// shiftTraffic sends the next weight of traffic to the new target group if the service is healthy
+// The service is not healthy until all the traffic is shifted
+func (service *Service) shiftTraffic(albc aws.ALBAPI) error {
+	if service.TrafficShift == nil {
+		return nil
+	}
+
+	weights := service.TrafficShift.Weights
+
+	if service.Healthy && service.TrafficStep < int64(len(weights)) {
+		if err := service.setTrafficWeight(albc, *weights[service.TrafficStep]); err != nil {
+			return err // This might retry
+		}
+
+		service.TrafficStep++
+		service.Healthy = false // Check health again before the next weight
+	}
+
+	if service.HealthReport != nil && service.TrafficStep > 0 {
+		service.HealthReport.TrafficPercent = weights[service.TrafficStep-1]
+	}
+
+	service.Healthy = service.Healthy && service.TrafficStep == int64(len(weights))
+
+	return nil
+}
+
+// setTrafficWeight sends percent of the rules traffic to the new target group and the rest to the live one
+func (service *Service) setTrafficWeight(albc aws.ALBAPI, percent int64) error {
+	names := service.Resources
+	if names.TrafficRule == nil || names.TrafficLiveTargetGroup == nil || names.TrafficNewTargetGroup == nil {
+		return fmt.Errorf("Traffic shift resources not found for %v", *service.ServiceName)
+	}
+
+	return alb.SetWeights(albc, names.TrafficRule, map[string]int64{
+		*names.TrafficLiveTargetGroup: 100 - percent,
+		*names.TrafficNewTargetGroup:  percent,
+	})
+}
+
+// ResetTraffic sends all traffic back to the previous releases target groups
+// The weights are always set, as a failed round may have shifted traffic without recording its step
+func (release *Release) ResetTraffic(albc aws.ALBAPI) error {
+	for _, service := range release.Services {
+		if service.TrafficShift == nil || service.Resources == nil || service.Resources.TrafficRule == nil {
+			continue
+		}
+
+		if err := service.setTrafficWeight(albc, 0); err != nil {
+			return err
+		}
+
+		service.TrafficStep = 0
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/coinbase/odin/aws/alb"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockTrafficShift(t *testing.T, weights map[string]int64) (*Release, *mocks.MockClients) {
+	r := MockRelease(t)
+	r.Services["web"].TrafficShift = &TrafficShiftConfig{
+		ListenerRule: to.Strp("web-rule"),
+		TargetGroups: []*string{to.Strp("web-blue"), to.Strp("web-green")},
+	}
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+	for _, name := range []string{"web-blue", "web-green"} {
+		awsc.ALB.AddTargetGroup(mocks.MockTargetGroup{
+			Name:        name,
+			ProjectName: *r.ProjectName,
+			ConfigName:  *r.ConfigName,
+			ServiceName: "web",
+		})
+	}
+	awsc.ALB.AddRule("web-rule", weights)
+
+	return r, awsc
+}
+
+func Test_TrafficShift_ValidateAttributes(t *testing.T) {
+	tgs := []*string{to.Strp("blue"), to.Strp("green")}
+
+	ts := &TrafficShiftConfig{ListenerRule: to.Strp("rule"), TargetGroups: tgs}
+	ts.SetDefaults()
+	assert.NoError(t, ts.ValidateAttributes())
+	assert.Equal(t, 4, len(ts.Weights))
+
+	assert.Error(t, (&TrafficShiftConfig{TargetGroups: tgs, Weights: ts.Weights}).ValidateAttributes())
+	assert.Error(t, (&TrafficShiftConfig{ListenerRule: to.Strp("rule"), TargetGroups: tgs[:1], Weights: ts.Weights}).ValidateAttributes())
+	assert.Error(t, (&TrafficShiftConfig{ListenerRule: to.Strp("rule"), TargetGroups: []*string{tgs[0], tgs[0]}, Weights: ts.Weights}).ValidateAttributes())
+
+	for _, weights := range [][]int64{{50, 25, 100}, {0, 100}, {50}, {50, 150}} {
+		ws := []*int64{}
+		for _, w := range weights {
+			ws = append(ws, to.Int64p(w))
+		}
+		assert.Error(t, (&TrafficShiftConfig{ListenerRule: to.Strp("rule"), TargetGroups: tgs, Weights: ws}).ValidateAttributes(), "%v", weights)
+	}
+}
+
+func Test_TrafficShift_Service_Validate(t *testing.T) {
+	r := MockRelease(t)
+	r.Services["web"].TrafficShift = &TrafficShiftConfig{
+		ListenerRule: to.Strp("web-rule"),
+		TargetGroups: []*string{to.Strp("web-elb-target"), to.Strp("web-green")},
+	}
+	MockPrepareRelease(r)
+
+	assert.Error(t, r.Services["web"].ValidateAttributes())
+}
+
+func Test_TrafficShift_Resources(t *testing.T) {
+	r, awsc := mockTrafficShift(t, map[string]int64{"web-blue": 100, "web-green": 0})
+
+	resources, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS)
+	assert.NoError(t, err)
+	assert.NoError(t, r.ValidateResources(resources))
+
+	r.UpdateWithResources(resources)
+
+	names := r.Services["web"].Resources
+	assert.Equal(t, "web-rule", *names.TrafficRule)
+	assert.Equal(t, "web-blue", *names.TrafficLiveTargetGroup)
+	assert.Equal(t, "web-green", *names.TrafficNewTargetGroup)
+
+	// The new ASG is attached to the target group without traffic
+	assert.Equal(t, []string{"web-elb-target", "web-green"}, to.StrSlice(names.TargetGroups))
+}
+
+func Test_TrafficShift_Resources_Alternates(t *testing.T) {
+	r, awsc := mockTrafficShift(t, map[string]int64{"web-blue": 0, "web-green": 100})
+
+	resources, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS)
+	assert.NoError(t, err)
+	assert.NoError(t, r.ValidateResources(resources))
+
+	r.UpdateWithResources(resources)
+	assert.Equal(t, "web-blue", *r.Services["web"].Resources.TrafficNewTargetGroup)
+}
+
+func Test_TrafficShift_Resources_Invalid(t *testing.T) {
+	for _, weights := range []map[string]int64{
+		{"web-blue": 50, "web-green": 50},             // No live target group
+		{"web-blue": 100},                             // Missing target group
+		{"web-blue": 100, "web-green": 0, "other": 0}, // Extra target group
+	} {
+		r, awsc := mockTrafficShift(t, weights)
+
+		resources, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS)
+		assert.NoError(t, err)
+		assert.Error(t, r.ValidateResources(resources), "%v", weights)
+	}
+}
+
+func Test_TrafficShift_Resources_RuleNotFound(t *testing.T) {
+	r, awsc := mockTrafficShift(t, map[string]int64{})
+	delete(awsc.ALB.Rules, "web-rule")
+
+	_, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS)
+	assert.Error(t, err)
+}
+
+func Test_TrafficShift_ShiftsWhenHealthy(t *testing.T) {
+	r, awsc := mockTrafficShift(t, map[string]int64{"web-blue": 100, "web-green": 0})
+
+	resources, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS)
+	assert.NoError(t, err)
+	r.UpdateWithResources(resources)
+
+	service := r.Services["web"]
+	weight := func() int64 {
+		rule, err := alb.FindRule(awsc.ALB, to.Strp("web-rule"))
+		assert.NoError(t, err)
+		return rule.Weights["web-green"]
+	}
+
+	// Nothing is shifted while unhealthy
+	service.Healthy = false
+	service.HealthReport = &HealthReport{}
+	assert.NoError(t, service.shiftTraffic(awsc.ALB))
+	assert.EqualValues(t, 0, service.TrafficStep)
+	assert.Equal(t, 0, len(awsc.ALB.ModifyRuleInputs))
+	assert.Nil(t, service.HealthReport.TrafficPercent)
+
+	// Each healthy check shifts one weight, and is not Healthy until all is shifted
+	for i, expected := range []int64{5, 25, 50, 100} {
+		service.Healthy = true
+		service.HealthReport = &HealthReport{}
+		assert.NoError(t, service.shiftTraffic(awsc.ALB))
+		assert.False(t, service.Healthy)
+		assert.EqualValues(t, i+1, service.TrafficStep)
+		assert.Equal(t, expected, weight())
+		assert.Equal(t, expected, *service.HealthReport.TrafficPercent)
+	}
+
+	service.Healthy = true
+	assert.NoError(t, service.shiftTraffic(awsc.ALB))
+	assert.True(t, service.Healthy)
+	assert.Equal(t, 4, len(awsc.ALB.ModifyRuleInputs))
+}
+
+func Test_TrafficShift_ResetTraffic(t *testing.T) {
+	r, awsc := mockTrafficShift(t, map[string]int64{"web-blue": 100, "web-green": 0})
+
+	resources, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS)
+	assert.NoError(t, err)
+	r.UpdateWithResources(resources)
+
+	// Reset even before a step is recorded, a failed round may have set the first weight
+	service := r.Services["web"]
+	assert.NoError(t, service.setTrafficWeight(awsc.ALB, 5))
+	assert.NoError(t, r.ResetTraffic(awsc.ALB))
+	assert.EqualValues(t, 0, service.TrafficStep)
+	assert.Equal(t, 2, len(awsc.ALB.ModifyRuleInputs))
+
+	rule, err := alb.FindRule(awsc.ALB, to.Strp("web-rule"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"web-blue": 100, "web-green": 0}, rule.Weights)
+
+	service.Healthy = true
+	assert.NoError(t, service.shiftTraffic(awsc.ALB))
+
+	assert.NoError(t, r.ResetTraffic(awsc.ALB))
+	assert.EqualValues(t, 0, service.TrafficStep)
+
+	rule, err = alb.FindRule(awsc.ALB, to.Strp("web-rule"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"web-blue": 100, "web-green": 0}, rule.Weights)
+}
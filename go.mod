@@ -2,12 +2,13 @@ module github.com/coinbase/odin
 
 require (
 	github.com/aws/aws-lambda-go v1.17.0
-	github.com/aws/aws-sdk-go v1.31.9
+	github.com/aws/aws-sdk-go v1.42.30
 	github.com/coinbase/step v1.0.2
 	github.com/davecgh/go-spew v1.1.1
 	github.com/google/gofuzz v0.0.0-20170612174753-24818f796faf
-	github.com/jmespath/go-jmespath v0.3.0
+	github.com/jmespath/go-jmespath v0.4.0
 	github.com/pmezard/go-difflib v1.0.0
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/stretchr/testify v1.5.1
 )
 
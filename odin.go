@@ -1,8 +1,10 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/coinbase/odin/client"
 	"github.com/coinbase/odin/deployer"
@@ -13,6 +15,7 @@ import (
 
 func main() {
 	var arg, command string
+	var args []string
 	switch len(os.Args) {
 	case 1:
 		fmt.Println("Starting Lambda")
@@ -24,7 +27,12 @@ func main() {
 		command = os.Args[1]
 		arg = os.Args[2]
 	default:
-		printUsage() // Print how to use and exit
+		// Only deploy, rollback, status, fails and events take more than one argument
+		command = os.Args[1]
+		args = os.Args[2:]
+		if command != "deploy" && command != "rollback" && command != "status" && command != "fails" && command != "events" {
+			printUsage() // Print how to use and exit
+		}
 	}
 
 	stepFn := to.Strp(os.Getenv("ODIN_STEP"))
@@ -38,15 +46,24 @@ func main() {
 		run.JSON(deployer.StateMachine())
 	case "deploy":
 		// Send Configuration to the deployer
-		// arg is a filename
-		err := client.Deploy(stepFn, &arg)
+		// arg is a filename, or args are [--sign-key <key>] <release_file>
+		signKey, releaseFile := parseDeployFlags(os.Args[2:])
+		err := client.Deploy(stepFn, &releaseFile, signKey)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	case "plan":
+		// Print what deploying the release would do without changing anything
+		err := client.Plan(&arg)
 		if err != nil {
 			fmt.Println(err.Error())
 			os.Exit(1)
 		}
 	case "fails":
 		// List the recent failures and their causes
-		err := client.Failures(stepFn)
+		filter, jsonOutput := parseFailsFlags(os.Args[2:])
+		err := client.Failures(stepFn, filter, jsonOutput)
 		if err != nil {
 			fmt.Println(err.Error())
 			os.Exit(1)
@@ -57,12 +74,126 @@ func main() {
 			fmt.Println(err.Error())
 			os.Exit(1)
 		}
+	case "continue":
+		// Approve a release paused for approval
+		err := client.Continue(stepFn, &arg)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	case "events":
+		// args are <release_file> [release_id]
+		var releaseID *string
+		switch len(args) {
+		case 0:
+			args = []string{arg}
+		case 2:
+			releaseID = &args[1]
+		default:
+			printUsage()
+		}
+
+		err := client.Events(stepFn, &args[0], releaseID)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	case "rollback":
+		// args are [--sign-key <key>] <project> <config> [release_id]
+		signKey, args := parseSignKeyFlags("rollback", os.Args[2:])
+		var releaseID *string
+		switch len(args) {
+		case 2:
+		case 3:
+			releaseID = &args[2]
+		default:
+			printUsage()
+		}
+
+		err := client.Rollback(stepFn, &args[0], &args[1], releaseID, signKey)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	case "status":
+		// args are <project> <config> [--json]
+		jsonOutput := false
+		switch len(args) {
+		case 2:
+		case 3:
+			if args[2] != "--json" {
+				printUsage()
+			}
+			jsonOutput = true
+		default:
+			printUsage()
+		}
+
+		err := client.Status(stepFn, &args[0], &args[1], jsonOutput)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
 	default:
 		printUsage() // Print how to use and exit
 	}
 }
 
+func parseDeployFlags(args []string) (*string, string) {
+	signKey, args := parseSignKeyFlags("deploy", args)
+	if len(args) != 1 {
+		printUsage()
+	}
+
+	return signKey, args[0]
+}
+
+// parseSignKeyFlags returns the --sign-key flag and the remaining args
+func parseSignKeyFlags(command string, args []string) (*string, []string) {
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	signKey := flags.String("sign-key", "", "sign the release with a KMS key ARN or alias, or an ed25519 private key file")
+	flags.Parse(args)
+
+	if *signKey == "" {
+		return nil, flags.Args()
+	}
+
+	return signKey, flags.Args()
+}
+
+func parseFailsFlags(args []string) (*client.FailuresFilter, bool) {
+	flags := flag.NewFlagSet("fails", flag.ExitOnError)
+	since := flags.Duration("since", 72*time.Hour, "list failures started within this duration")
+	project := flags.String("project", "", "only list failures for this project")
+	config := flags.String("config", "", "only list failures for this config")
+	state := flags.String("state", "", "only list failures ending in FailureDirty or FailureClean")
+	jsonOutput := flags.Bool("json", false, "print failures as JSON")
+	flags.Parse(args)
+
+	if flags.NArg() > 0 {
+		printUsage()
+	}
+
+	filter := &client.FailuresFilter{Since: time.Now().Add(-*since)}
+	if *project != "" {
+		filter.ProjectName = project
+	}
+	if *config != "" {
+		filter.ConfigName = config
+	}
+	if *state != "" {
+		filter.State = state
+	}
+
+	return filter, *jsonOutput
+}
+
 func printUsage() {
-	fmt.Println("Usage: odin <json|deploy|halt|fails> <release_file> (No args starts Lambda)")
+	fmt.Println("Usage: odin <json|deploy|plan|halt|continue> <release_file> (No args starts Lambda)")
+	fmt.Println("       odin deploy [--sign-key <kms_key_arn|alias/name|ed25519_key_file>] <release_file>")
+	fmt.Println("       odin rollback [--sign-key <kms_key_arn|alias/name|ed25519_key_file>] <project> <config> [release_id]")
+	fmt.Println("       odin events <release_file> [release_id]")
+	fmt.Println("       odin status <project> <config> [--json]")
+	fmt.Println("       odin fails [--since 72h] [--project <project>] [--config <config>] [--state FailureDirty|FailureClean] [--json]")
 	os.Exit(0)
 }